@@ -9,6 +9,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
 func New(config *Config) *Client {
@@ -19,6 +21,13 @@ func New(config *Config) *Client {
 	}
 }
 
+func (c *Client) dataSource() pokemon.DataSource {
+	if c.Config.DataSource == nil {
+		c.Config.DataSource = pokemon.NewHTTPSource()
+	}
+	return c.Config.DataSource
+}
+
 func (c *Client) Connect() error {
 	serverAddr := fmt.Sprintf("[%s]:%s", c.Config.ServerHost, c.Config.ServerPort)
 	if strings.Contains(c.Config.ServerHost, ":") && !strings.HasPrefix(c.Config.ServerHost, "[") {
@@ -151,6 +160,7 @@ func (c *Client) ProcessMessage(msg Message) {
 }
 
 func (c *Client) Disconnect() {
+	c.finishSetup()
 	if c.Conn != nil {
 		log.Println("Disconnecting client...")
 		c.Conn.Close()
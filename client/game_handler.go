@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,13 +34,17 @@ func CallClear() {
 
 func (c *Client) startGameMode() {
 	c.GameActive = true
+	c.CanSwitch = true
 	c.AwaitingForcedSwitch = false
+	c.WaitingForOpponent = false
 	log.Println("Entered game mode.")
 }
 
 func (c *Client) endGameMode() {
+	c.finishSetup()
 	c.GameActive = false
 	c.AwaitingForcedSwitch = false
+	c.WaitingForOpponent = false
 	c.InMatch = false
 	c.Opponent = ""
 	c.PlayerSquad = nil
@@ -47,7 +52,15 @@ func (c *Client) endGameMode() {
 	c.PlayerMaxHPs = nil
 	c.EnemyMaxHPs = nil
 	c.LastTurnDescription = nil
+	c.LastTurnEvents = nil
 	c.LastAvailableMovesInfo = nil
+	c.Weather = ""
+	c.WeatherTurns = 0
+	c.YourSideConditions = nil
+	c.OpponentSideConditions = nil
+	c.FieldConditions = nil
+	c.YourHazards = nil
+	c.OpponentHazards = nil
 	fmt.Println("\n=== Exited Battle Mode ===")
 	log.Println("Exited game mode.")
 }
@@ -61,6 +74,17 @@ func (c *Client) handleTurnRequest(msg Message) {
 	turnNumberF, _ := msg.Message["turn"].(float64)
 	turnNumber := int(turnNumberF)
 	forceSwitch, _ := msg.Message["force_switch"].(bool)
+	c.CanSwitch = true
+	if canSwitch, ok := msg.Message["can_switch"].(bool); ok {
+		c.CanSwitch = canSwitch
+	}
+	c.applyWeather(msg)
+	c.WaitingForOpponent, _ = msg.Message["waiting"].(bool)
+	if c.WaitingForOpponent {
+		fmt.Printf("\n=== TURN %d === vs %s\n", turnNumber, c.Opponent)
+		fmt.Printf("Waiting for %s to decide...\n", c.Opponent)
+		return
+	}
 	c.LastAvailableMovesInfo = nil
 	if movesInfoInterface, ok := msg.Message["available_moves_info"]; ok {
 		jsonBytes, err := json.Marshal(movesInfoInterface)
@@ -80,6 +104,7 @@ func (c *Client) handleTurnRequest(msg Message) {
 	}
 
 	fmt.Printf("\n=== TURN %d === vs %s\n", turnNumber, c.Opponent)
+	c.printWeather()
 	fmt.Println("\nYour Squad:")
 	if c.PlayerSquad == nil || len(c.PlayerSquad) == 0 || c.PlayerMaxHPs == nil {
 		fmt.Println("(Squad information not available)")
@@ -104,7 +129,11 @@ func (c *Client) handleTurnRequest(msg Message) {
 			if i == c.PlayerActiveIdx {
 				activeIndicator = "->"
 			}
-			fmt.Printf("%s %d. %-12s HP: %3.0f%% %s\n", activeIndicator, i+1, poke.Base.Name, hpPercent, status)
+			held := ""
+			if poke.Item != "" {
+				held = "@ " + poke.Item
+			}
+			fmt.Printf("%s %d. %-12s HP: %3.0f%% %s %s\n", activeIndicator, i+1, poke.Base.Name, hpPercent, status, held)
 		}
 	}
 	fmt.Println("-------------------------")
@@ -135,8 +164,12 @@ func (c *Client) handleTurnRequest(msg Message) {
 		if c.LastAvailableMovesInfo != nil && len(c.LastAvailableMovesInfo) > 0 {
 			for i, moveInfo := range c.LastAvailableMovesInfo {
 				ppIndicator := ""
-				if moveInfo.CurrentPP <= 0 {
+				if moveInfo.Forced {
+					ppIndicator = " (MUST USE)"
+				} else if moveInfo.CurrentPP <= 0 {
 					ppIndicator = " (NO PP)"
+				} else if moveInfo.Disabled != "" {
+					ppIndicator = fmt.Sprintf(" (%s)", strings.ToUpper(moveInfo.Disabled))
 				}
 				fmt.Printf("%d. %-15s (%d/%d PP)%s\n", i+1, moveInfo.Name, moveInfo.CurrentPP, moveInfo.MaxPP, ppIndicator)
 			}
@@ -197,9 +230,64 @@ func (c *Client) handleTurnResult(msg Message) {
 		strings.Title(opponentPokemonName),
 		oppHpPercent,
 	)
+	if c.PlayerSquad != nil && c.PlayerActiveIdx >= 0 && c.PlayerActiveIdx < len(c.PlayerSquad) && c.PlayerSquad[c.PlayerActiveIdx] != nil {
+		if stages := formatStages(c.PlayerSquad[c.PlayerActiveIdx].StatStages); stages != "" {
+			fmt.Printf("Your stat changes: %s\n", stages)
+		}
+	}
+	if c.EnemySquad != nil && c.EnemyActiveIdx >= 0 && c.EnemyActiveIdx < len(c.EnemySquad) && c.EnemySquad[c.EnemyActiveIdx] != nil {
+		if stages := formatStages(c.EnemySquad[c.EnemyActiveIdx].StatStages); stages != "" {
+			fmt.Printf("Enemy stat changes: %s\n", stages)
+		}
+	}
+	c.printWeather()
+	printConditions("Your side", c.YourSideConditions, "turns left")
+	printConditions("Opponent's side", c.OpponentSideConditions, "turns left")
+	printConditions("Field", c.FieldConditions, "turns left")
+	printConditions("Hazards on your side", c.YourHazards, "layers")
+	printConditions("Hazards on the opponent's side", c.OpponentHazards, "layers")
 	fmt.Println("===================")
 }
 
+func printConditions(label string, conditions map[string]int, unit string) {
+	if len(conditions) == 0 {
+		return
+	}
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%d %s)", name, conditions[name], unit)
+	}
+	fmt.Printf("%s: %s\n", label, strings.Join(parts, ", "))
+}
+
+func (c *Client) printWeather() {
+	if c.Weather == "" {
+		return
+	}
+	fmt.Printf("Weather: %s (%d turns left)\n", strings.Title(c.Weather), c.WeatherTurns)
+}
+
+var stageLabels = []struct{ stat, label string }{
+	{"attack", "Atk"}, {"defense", "Def"}, {"special-attack", "SpA"}, {"special-defense", "SpD"},
+	{"speed", "Spe"}, {"accuracy", "Acc"}, {"evasion", "Eva"},
+}
+
+// formatStages renders non-zero stat stages as e.g. "Atk +2 Spe -1".
+func formatStages(stages map[string]int) string {
+	var parts []string
+	for _, s := range stageLabels {
+		if v := stages[s.stat]; v != 0 {
+			parts = append(parts, fmt.Sprintf("%s %+d", s.label, v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 func (c *Client) handleOpponentDisconnected(msg Message) {
 	opponentName, _ := msg.Message["opponent"].(string)
 	if opponentName == "" {
@@ -217,6 +305,10 @@ func (c *Client) handleGameInput(input string) {
 		return
 	}
 	command := strings.ToLower(parts[0])
+	if c.WaitingForOpponent && !c.AwaitingForcedSwitch {
+		fmt.Printf("Waiting for %s to decide...\n", c.Opponent)
+		return
+	}
 	if c.AwaitingForcedSwitch {
 		var targetIndex int = -1
 		if switchNum, err := strconv.Atoi(command); err == nil && len(parts) == 1 {
@@ -283,11 +375,16 @@ func (c *Client) handleGameInput(input string) {
 					return
 				}
 				selectedMove := c.LastAvailableMovesInfo[moveIndex]
-				if selectedMove.CurrentPP <= 0 {
+				if selectedMove.CurrentPP <= 0 && !selectedMove.Forced {
 					fmt.Printf("Move '%s' has no PP left!\n", selectedMove.Name)
 					fmt.Print("Enter your action: ")
 					return
 				}
+				if selectedMove.Disabled != "" {
+					fmt.Printf("Move '%s' can't be used: %s!\n", selectedMove.Name, selectedMove.Disabled)
+					fmt.Print("Enter your action: ")
+					return
+				}
 				c.sendGameAction("move", moveNum, 0)
 				return
 			}
@@ -322,11 +419,16 @@ func (c *Client) handleGameInput(input string) {
 				return
 			}
 			selectedMove := c.LastAvailableMovesInfo[moveIndex0Based]
-			if selectedMove.CurrentPP <= 0 {
+			if selectedMove.CurrentPP <= 0 && !selectedMove.Forced {
 				fmt.Printf("Move '%s' has no PP left!\n", selectedMove.Name)
 				fmt.Print("Enter your action: ")
 				return
 			}
+			if selectedMove.Disabled != "" {
+				fmt.Printf("Move '%s' can't be used: %s!\n", selectedMove.Name, selectedMove.Disabled)
+				fmt.Print("Enter your action: ")
+				return
+			}
 			c.sendGameAction("move", actionIndex, 0)
 		case "switch", "s":
 			if actionIndex < 1 || actionIndex > 6 {
@@ -334,6 +436,11 @@ func (c *Client) handleGameInput(input string) {
 				fmt.Print("Enter your action: ")
 				return
 			}
+			if !c.CanSwitch {
+				fmt.Println("Your active Pokemon is locked into its move and can't switch out!")
+				fmt.Print("Enter your action: ")
+				return
+			}
 			targetIndex := actionIndex - 1
 			if targetIndex == c.PlayerActiveIdx {
 				fmt.Println("Cannot switch to the Pokemon that is already active.")
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,7 +10,6 @@ import (
 	"time"
 
 	"github.com/ross1116/pokebattlecli/internal/battle"
-	"github.com/ross1116/pokebattlecli/internal/pokemon"
 	"github.com/ross1116/pokebattlecli/internal/stats"
 )
 
@@ -93,21 +93,43 @@ func (c *Client) processGameStart(msg Message) {
 		opponentSquad[i], _ = v.(string)
 	}
 
+	yourLevels := levelsFromMessage(msg.Message["your_levels"], len(yourSquad))
+	opponentLevels := levelsFromMessage(msg.Message["opponent_levels"], len(opponentSquad))
+
 	fmt.Printf("\n=== Battle Start vs %s ===\n", c.Opponent)
+	if format, ok := msg.Message["format"].(string); ok && format != "" {
+		fmt.Printf("Format: %s\n", format)
+	}
 	fmt.Println("\nYour squad:")
-	for _, pokemon := range yourSquad {
-		fmt.Printf("- %s\n", pokemon)
+	for i, pokemon := range yourSquad {
+		fmt.Printf("- %s (Lv. %d)\n", pokemon, yourLevels[i])
 	}
 	fmt.Println("\nOpponent's squad:")
-	for _, pokemon := range opponentSquad {
-		fmt.Printf("- %s\n", pokemon)
+	for i, pokemon := range opponentSquad {
+		fmt.Printf("- %s (Lv. %d)\n", pokemon, opponentLevels[i])
 	}
 
-	c.setupBattleState(yourSquad, opponentSquad)
+	c.setupBattleState(msg, yourSquad, opponentSquad, yourLevels, opponentLevels)
 
 	c.startGameMode()
 }
 
+// levelsFromMessage reads a squad's levels from game_start, falling back to
+// level 100 for servers that do not send them.
+func levelsFromMessage(raw interface{}, n int) []int {
+	levels := make([]int, n)
+	values, _ := raw.([]interface{})
+	for i := range levels {
+		levels[i] = stats.MaxLevel
+		if i < len(values) {
+			if v, ok := values[i].(float64); ok && v >= 1 {
+				levels[i] = int(v)
+			}
+		}
+	}
+	return levels
+}
+
 func (c *Client) handleSwitchRequest(msg Message) {
 	log.Println("Received switch request from server.")
 	reason, _ := msg.Message["reason"].(string)
@@ -186,76 +208,8 @@ func (c *Client) processGameEnd(msg Message) {
 
 func (c *Client) applyBattleStateUpdate(msg Message) {
 	log.Println("Applying battle state update...")
-	var yourSquadUpdate []PokemonStateInfo
-	var opponentSquadUpdate []PokemonStateInfo
-
-	if data, ok := msg.Message["your_squad_state"]; ok {
-		jsonBytes, err := json.Marshal(data)
-		if err == nil {
-			err = json.Unmarshal(jsonBytes, &yourSquadUpdate)
-		}
-		if err != nil {
-			log.Printf("Error un/marshaling your_squad_state: %v", err)
-		}
-	} else {
-		log.Println("Warning: 'your_squad_state' missing from update message")
-	}
-
-	if data, ok := msg.Message["opponent_squad_state"]; ok {
-		jsonBytes, err := json.Marshal(data)
-		if err == nil {
-			err = json.Unmarshal(jsonBytes, &opponentSquadUpdate)
-		}
-		if err != nil {
-			log.Printf("Error un/marshaling opponent_squad_state: %v", err)
-		}
-	} else {
-		log.Println("Warning: 'opponent_squad_state' missing from update message")
-	}
-
-	if c.PlayerSquad != nil && len(yourSquadUpdate) > 0 {
-		if len(yourSquadUpdate) != len(c.PlayerSquad) {
-			log.Printf("Warning: Player squad update length mismatch. Local=%d, Update=%d", len(c.PlayerSquad), len(yourSquadUpdate))
-		} else {
-			for i, updateInfo := range yourSquadUpdate {
-				if i < len(c.PlayerSquad) && c.PlayerSquad[i] != nil && c.PlayerSquad[i].Base != nil {
-					if c.PlayerSquad[i].Base.Name == updateInfo.Name {
-						c.PlayerSquad[i].CurrentHP = updateInfo.CurrentHP
-						c.PlayerSquad[i].Fainted = updateInfo.Fainted
-						c.PlayerSquad[i].Status = updateInfo.Status
-					} else {
-						log.Printf("Warning: Name mismatch at index %d during player state update. Expected %s, got %s", i, c.PlayerSquad[i].Base.Name, updateInfo.Name)
-					}
-				} else {
-					log.Printf("Warning: Nil BattlePokemon/Base or index out of bounds (%d) in PlayerSquad during update.", i)
-				}
-			}
-		}
-	} else {
-		log.Printf("Warning: Player squad update skipped. Local squad nil or update empty.")
-	}
-
-	if c.EnemySquad != nil && len(opponentSquadUpdate) > 0 {
-		if len(opponentSquadUpdate) != len(c.EnemySquad) {
-			log.Printf("Warning: Opponent squad update length mismatch. Local=%d, Update=%d", len(c.EnemySquad), len(opponentSquadUpdate))
-		} else {
-			for i, updateInfo := range opponentSquadUpdate {
-				if i < len(c.EnemySquad) && c.EnemySquad[i] != nil && c.EnemySquad[i].Base != nil {
-					if c.EnemySquad[i].Base.Name == updateInfo.Name {
-						c.EnemySquad[i].CurrentHP = updateInfo.CurrentHP
-						c.EnemySquad[i].Fainted = updateInfo.Fainted
-						c.EnemySquad[i].Status = updateInfo.Status
-					} else {
-						log.Printf("Warning: Name mismatch at index %d during opponent state update. Expected %s, got %s", i, c.EnemySquad[i].Base.Name, updateInfo.Name)
-					}
-				} else {
-					log.Printf("Warning: Nil BattlePokemon/Base or index out of bounds (%d) in EnemySquad during update.", i)
-				}
-			}
-		}
-	} else {
-		log.Printf("Warning: Opponent squad update skipped. Local squad nil or update empty.")
-	}
+	c.applySquadState("player", c.PlayerSquad, c.PlayerMaxHPs, squadStateFromMessage(msg, "your_squad_state"))
+	c.applySquadState("opponent", c.EnemySquad, c.EnemyMaxHPs, squadStateFromMessage(msg, "opponent_squad_state"))
 
 	if idxFloat, ok := msg.Message["your_active_index"].(float64); ok {
 		c.PlayerActiveIdx = int(idxFloat)
@@ -268,7 +222,19 @@ func (c *Client) applyBattleStateUpdate(msg Message) {
 		log.Println("Warning: 'opponent_active_index' missing or invalid in update message")
 	}
 
-	if descInterface, ok := msg.Message["description"].([]interface{}); ok {
+	c.applyWeather(msg)
+	c.YourSideConditions = conditionsFromMessage(msg, "your_side_conditions")
+	c.OpponentSideConditions = conditionsFromMessage(msg, "opponent_side_conditions")
+	c.FieldConditions = conditionsFromMessage(msg, "field_conditions")
+	c.YourHazards = conditionsFromMessage(msg, "your_hazards")
+	c.OpponentHazards = conditionsFromMessage(msg, "opponent_hazards")
+
+	if events, err := eventsFromMessage(msg); err == nil {
+		c.LastTurnEvents = events
+		c.LastTurnDescription = battle.Render(events)
+	} else if descInterface, ok := msg.Message["description"].([]interface{}); ok {
+		log.Printf("Warning: could not decode turn events, using the server's description: %v", err)
+		c.LastTurnEvents = nil
 		c.LastTurnDescription = make([]string, len(descInterface))
 		for i, desc := range descInterface {
 			if descStr, ok := desc.(string); ok {
@@ -282,34 +248,119 @@ func (c *Client) applyBattleStateUpdate(msg Message) {
 	log.Println("Battle state update applied.")
 }
 
-func (c *Client) setupBattleState(yourSquadNames, opponentSquadNames []string) {
-	log.Println("Setting up client battle state by fetching data...")
-	startTime := time.Now()
-	moveCache := make(map[string]*pokemon.MoveInfo)
-	var cacheMutex sync.Mutex
-	fetchMoveWithCache := func(url string) (*pokemon.MoveInfo, error) {
-		cacheMutex.Lock()
-		if cachedMove, found := moveCache[url]; found {
-			cacheMutex.Unlock()
-			return cachedMove, nil
+// squadStateFromMessage decodes the squad state the server sends under key.
+func squadStateFromMessage(msg Message, key string) []PokemonStateInfo {
+	data, ok := msg.Message[key]
+	if !ok {
+		log.Printf("Warning: '%s' missing from %s message", key, msg.Type)
+		return nil
+	}
+	var state []PokemonStateInfo
+	jsonBytes, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(jsonBytes, &state)
+	}
+	if err != nil {
+		log.Printf("Error un/marshaling %s: %v", key, err)
+	}
+	return state
+}
+
+// applySquadState copies the server's view of owner's squad onto the local
+// one.
+func (c *Client) applySquadState(owner string, squad []*battle.BattlePokemon, maxHPs []float64, update []PokemonStateInfo) {
+	if squad == nil || len(update) == 0 {
+		log.Printf("Warning: %s squad update skipped. Local squad nil or update empty.", owner)
+		return
+	}
+	if len(update) != len(squad) {
+		log.Printf("Warning: %s squad update length mismatch. Local=%d, Update=%d", owner, len(squad), len(update))
+		return
+	}
+	for i, updateInfo := range update {
+		if squad[i] == nil || squad[i].Base == nil {
+			log.Printf("Warning: Nil BattlePokemon/Base at index %d in %s squad during update.", i, owner)
+			continue
 		}
-		cacheMutex.Unlock()
-		var moveData *pokemon.MoveInfo
-		var err error
-		maxRetries := 3
-		for attempts := 0; attempts < maxRetries; attempts++ {
-			moveData, err = pokemon.FetchMoveData(url)
-			if err == nil {
-				cacheMutex.Lock()
-				moveCache[url] = moveData
-				cacheMutex.Unlock()
-				return moveData, nil
-			}
-			log.Printf("Attempt %d: Error fetching move %s: %v. Retrying...", attempts+1, url, err)
-			time.Sleep(time.Duration(100*(attempts+1)) * time.Millisecond)
+		if squad[i].Base.Name != updateInfo.Name {
+			log.Printf("Warning: Name mismatch at index %d during %s state update. Expected %s, got %s", i, owner, squad[i].Base.Name, updateInfo.Name)
+			continue
+		}
+		squad[i].CurrentHP = updateInfo.CurrentHP
+		squad[i].Fainted = updateInfo.Fainted
+		squad[i].Status = updateInfo.Status
+		squad[i].Item = updateInfo.Item
+		squad[i].StatStages = updateInfo.StatStages
+		if updateInfo.MaxHP > 0 && i < len(maxHPs) {
+			maxHPs[i] = updateInfo.MaxHP
+		}
+	}
+}
+
+// eventsFromMessage decodes the structured events the server sends with
+// each turn.
+func eventsFromMessage(msg Message) ([]battle.Event, error) {
+	raw, ok := msg.Message["events"]
+	if !ok {
+		return nil, fmt.Errorf("no events in %s message", msg.Type)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var records []battle.Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return battle.Events(records), nil
+}
+
+// applyWeather records the weather the server reports with each turn.
+func (c *Client) applyWeather(msg Message) {
+	c.Weather, _ = msg.Message["weather"].(string)
+	turns, _ := msg.Message["weather_turns"].(float64)
+	c.WeatherTurns = int(turns)
+}
+
+// conditionsFromMessage reads a map of condition names to turns left, or of
+// hazards to layers.
+func conditionsFromMessage(msg Message, key string) map[string]int {
+	raw, _ := msg.Message[key].(map[string]interface{})
+	conditions := make(map[string]int, len(raw))
+	for name, turns := range raw {
+		if n, ok := turns.(float64); ok {
+			conditions[name] = int(n)
 		}
-		return nil, fmt.Errorf("failed to fetch move %s after %d retries: %w", url, maxRetries, err)
 	}
+	return conditions
+}
+
+// beginSetup returns a context for fetching battle data that is cancelled if
+// the client disconnects or leaves the game before setup finishes.
+func (c *Client) beginSetup() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.setupMu.Lock()
+	c.cancelSetup = cancel
+	c.setupMu.Unlock()
+	return ctx
+}
+
+func (c *Client) finishSetup() {
+	c.setupMu.Lock()
+	defer c.setupMu.Unlock()
+	if c.cancelSetup != nil {
+		c.cancelSetup()
+		c.cancelSetup = nil
+	}
+}
+
+// setupBattleState builds the squads shown during the battle from the
+// species and state in the server's game_start message.
+func (c *Client) setupBattleState(msg Message, yourSquadNames, opponentSquadNames []string, yourLevels, opponentLevels []int) {
+	log.Println("Setting up client battle state by fetching data...")
+	startTime := time.Now()
+	ctx := c.beginSetup()
+	defer c.finishSetup()
 
 	var wg sync.WaitGroup
 	var setupMutex sync.Mutex
@@ -320,10 +371,10 @@ func (c *Client) setupBattleState(yourSquadNames, opponentSquadNames []string) {
 	c.PlayerMaxHPs = make([]float64, playerSquadSize)
 	c.EnemyMaxHPs = make([]float64, enemySquadSize)
 
-	processPokemon := func(idx int, pokeName string, isPlayer bool) {
+	processPokemon := func(idx int, pokeName string, level int, isPlayer bool) {
 		defer wg.Done()
 		log.Printf("Initializing %s (%s)...", pokeName, map[bool]string{true: "Player", false: "Opponent"}[isPlayer])
-		basePoke, err := pokemon.FetchPokemonData(pokeName)
+		basePoke, err := c.dataSource().Pokemon(ctx, pokeName)
 		if err != nil || basePoke == nil {
 			log.Printf("Error fetching base data for %s: %v", pokeName, err)
 			setupMutex.Lock()
@@ -336,42 +387,20 @@ func (c *Client) setupBattleState(yourSquadNames, opponentSquadNames []string) {
 			setupMutex.Unlock()
 			return
 		}
-		moveEntries := pokemon.PickRandMoves(basePoke)
-		moveset := []*pokemon.MoveInfo{}
-		var moveWg sync.WaitGroup
-		var movesetMutex sync.Mutex
-		for _, entry := range moveEntries {
-			moveWg.Add(1)
-			go func(mEntry pokemon.ApiResource) {
-				defer moveWg.Done()
-				moveData, err := fetchMoveWithCache(mEntry.URL)
-				if err != nil {
-					log.Printf("Error fetching move details %s for %s: %v", mEntry.Name, pokeName, err)
-					return
-				}
-				if moveData != nil {
-					movesetMutex.Lock()
-					moveset = append(moveset, moveData)
-					movesetMutex.Unlock()
-				}
-			}(entry)
-		}
-		moveWg.Wait()
-		battlePoke := battle.NewBattlePokemon(basePoke, moveset)
+		// Moves come with each turn request; the server owns the sets.
+		battlePoke := battle.NewBattlePokemon(basePoke, nil)
 		if battlePoke == nil {
 			log.Printf("Error creating BattlePokemon for %s", pokeName)
 			setupMutex.Lock()
 			setupMutex.Unlock()
 			return
 		}
-		maxHP := 0.0
-		hpBaseStat := stats.GetStat(battlePoke.Base, "hp")
-		if hpBaseStat > 0 {
-			maxHP = stats.HpCalc(hpBaseStat)
-		} else {
-			log.Printf("Warning: Could not get HP base stat for %s", battlePoke.Base.Name)
+		spread := stats.DefaultSpread()
+		spread.Level = level
+		if err := battlePoke.SetSpread(spread); err != nil {
+			log.Printf("Error setting level for %s: %v", pokeName, err)
 		}
-		battlePoke.CurrentHP = maxHP
+		maxHP := battlePoke.MaxHP()
 		setupMutex.Lock()
 		if isPlayer {
 			if idx < len(c.PlayerSquad) {
@@ -391,12 +420,12 @@ func (c *Client) setupBattleState(yourSquadNames, opponentSquadNames []string) {
 	log.Println("Initializing Player Squad...")
 	for i, name := range yourSquadNames {
 		wg.Add(1)
-		go processPokemon(i, name, true)
+		go processPokemon(i, name, yourLevels[i], true)
 	}
 	log.Println("Initializing Opponent Squad...")
 	for i, name := range opponentSquadNames {
 		wg.Add(1)
-		go processPokemon(i, name, false)
+		go processPokemon(i, name, opponentLevels[i], false)
 	}
 	wg.Wait()
 	c.PlayerActiveIdx = 0
@@ -416,9 +445,15 @@ func (c *Client) setupBattleState(yourSquadNames, opponentSquadNames []string) {
 		}
 	}
 	setupMutex.Unlock()
+	if ctx.Err() != nil {
+		log.Println("Battle setup cancelled.")
+		return
+	}
 	if !squadPopulated {
 		log.Println("Error: Failed to initialize one or more Pokemon.")
 	}
+	c.applySquadState("player", c.PlayerSquad, c.PlayerMaxHPs, squadStateFromMessage(msg, "your_squad_state"))
+	c.applySquadState("opponent", c.EnemySquad, c.EnemyMaxHPs, squadStateFromMessage(msg, "opponent_squad_state"))
 	log.Printf("Client battle state setup complete. Time: %s", time.Since(startTime))
 	fmt.Println("\nBattle state ready!")
 }
@@ -1,22 +1,30 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"net"
+	"sync"
 
 	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
 type Config struct {
 	ServerHost string
 	ServerPort string
 	Username   string
+	DataSource pokemon.DataSource
 }
 
 type MoveStateInfo struct {
 	Name      string `json:"name"`
 	CurrentPP int    `json:"current_pp"`
 	MaxPP     int    `json:"max_pp"`
+	Disabled  string `json:"disabled,omitempty"`
+	// Forced marks the only move the Pokémon may use, because it is locked
+	// into a multi-turn move or must Struggle. It needs no PP.
+	Forced bool `json:"forced,omitempty"`
 }
 
 type Client struct {
@@ -27,16 +35,34 @@ type Client struct {
 	InMatch     bool
 	MessageChan chan Message
 
-	GameActive             bool
-	AwaitingForcedSwitch   bool
-	PlayerSquad            []*battle.BattlePokemon
-	EnemySquad             []*battle.BattlePokemon
-	PlayerActiveIdx        int
-	EnemyActiveIdx         int
-	PlayerMaxHPs           []float64
-	EnemyMaxHPs            []float64
-	LastTurnDescription    []string
+	GameActive           bool
+	AwaitingForcedSwitch bool
+	// WaitingForOpponent is set while the opponent decides a turn this
+	// client has no say in.
+	WaitingForOpponent  bool
+	PlayerSquad         []*battle.BattlePokemon
+	EnemySquad          []*battle.BattlePokemon
+	PlayerActiveIdx     int
+	EnemyActiveIdx      int
+	PlayerMaxHPs        []float64
+	EnemyMaxHPs         []float64
+	LastTurnDescription []string
+	// LastTurnEvents is what happened last turn, as the server reported it.
+	LastTurnEvents         []battle.Event
 	LastAvailableMovesInfo []MoveStateInfo
+	// CanSwitch is false while the active Pokémon is locked into a
+	// multi-turn move.
+	CanSwitch              bool
+	Weather                string
+	WeatherTurns           int
+	YourSideConditions     map[string]int
+	OpponentSideConditions map[string]int
+	FieldConditions        map[string]int
+	YourHazards            map[string]int
+	OpponentHazards        map[string]int
+
+	setupMu     sync.Mutex
+	cancelSetup context.CancelFunc
 }
 
 type PlayerAction struct {
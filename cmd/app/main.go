@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"math/rand/v2"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ross1116/pokebattlecli/internal/battle"
@@ -10,8 +14,40 @@ import (
 )
 
 func main() {
+	dataSpec := flag.String("data", "auto", "Data source: auto, http, bundled, or a local store directory")
+	formatName := flag.String("format", battle.DefaultFormat, "Battle format: random, singles, vgc, little-cup or level-N, optionally prefixed with genN-")
+	seed := flag.Uint64("seed", 0, "Battle seed; replaying a seed with the same choices repeats the battle (0 picks a new one)")
+	difficulty := flag.String("difficulty", battle.DefaultDifficulty, "Opponent difficulty: "+strings.Join(battle.DifficultyNames(), ", "))
+	flag.Parse()
+
+	format, err := battle.LookupFormat(*formatName)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	src, err := pokemon.OpenSource(*dataSpec)
+	if err != nil {
+		log.Fatalf("Failed to open data source: %v", err)
+	}
+	if err := format.LoadChart(context.Background(), src); err != nil {
+		log.Fatalf("Failed to load type chart: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = battle.NewSeed()
+	}
+	fmt.Printf("Battle seed: %d\n", *seed)
+	field := battle.NewField(format, *seed)
+	needsPool, err := battle.NeedsSetPool(*difficulty)
+	if err != nil {
+		log.Fatalf("Invalid difficulty: %v", err)
+	}
+
 	start := time.Now()
-	playerSquad, enemySquad, playerMovesets, enemyMovesets, playerActiveIndex, enemyActiveIndex := battle.SetupFullSquads()
+	playerSquad, enemySquad, _, _, playerActiveIndex, enemyActiveIndex, err := battle.SetupFullSquads(context.Background(), src, format, field.Rand())
+	if err != nil {
+		log.Fatalf("Failed to set up battle: %v", err)
+	}
 	playerMaxHPs := make([]float64, len(playerSquad))
 	enemyMaxHPs := make([]float64, len(enemySquad))
 	for i, p := range playerSquad {
@@ -20,113 +56,158 @@ func main() {
 	for i, p := range enemySquad {
 		enemyMaxHPs[i] = p.CurrentHP
 	}
-	var playerPokemonSquad []pokemon.Pokemon
-	var enemyPokemonSquad []pokemon.Pokemon
-	for _, bp := range playerSquad {
-		playerPokemonSquad = append(playerPokemonSquad, *bp.Base)
+	var pool *battle.SetPool
+	if needsPool {
+		// The opponent knows your species but has to guess their sets.
+		if pool, err = battle.LoadSetPool(context.Background(), src, playerSquad); err != nil {
+			log.Fatalf("Failed to load the opponent's move pool: %v", err)
+		}
 	}
-	for _, bp := range enemySquad {
-		enemyPokemonSquad = append(enemyPokemonSquad, *bp.Base)
+	enemy, err := battle.LookupAgent(*difficulty, field.Rand(), pool)
+	if err != nil {
+		log.Fatalf("Invalid difficulty: %v", err)
 	}
-	for {
-		battle.DisplayBattleState(playerSquad, enemySquad, playerActiveIndex, enemyActiveIndex, playerMaxHPs, enemyMaxHPs)
-		battle.DisplayMoveOptions(playerMovesets[playerActiveIndex], playerSquad[playerActiveIndex].MovePP)
-		fmt.Println("0. Switch Pokémon")
-		fmt.Print("Select your action (0-4): ")
-		var choice int
-		fmt.Scan(&choice)
+	b := battle.NewBattle(field, [2]string{"your team", "the opposing team"}, [2][]*battle.BattlePokemon{playerSquad, enemySquad}, [2]int{playerActiveIndex, enemyActiveIndex})
+	printEvents(b.Start())
 
-		// Handle switching
-		if choice == 0 {
-			fmt.Println("Select Pokémon to switch to:")
-			for i, p := range playerSquad {
-				fmt.Printf("%d. %s (HP: %d%%)\n", i+1, p.Base.Name, int((p.CurrentHP/playerMaxHPs[i])*100))
+	for !b.Over() {
+		turn := b.Turn
+		if req := b.RequestsFor(0); req != nil {
+			if !req.ForceSwitch {
+				battle.DisplayBattleState(playerSquad, enemySquad, b.Active[0], b.Active[1], playerMaxHPs, enemyMaxHPs)
+				if field.Weather != "" {
+					fmt.Printf("Weather: %s (%d turns left)\n", field.Weather, field.WeatherTurns)
+				}
+				printConditions("Your side", field.Sides[0].Conditions)
+				printConditions("Opposing side", field.Sides[1].Conditions)
+				printConditions("Field", field.ActiveConditions())
+				printConditions("Hazards on your side", field.Sides[0].Hazards)
+				printConditions("Hazards on the opposing side", field.Sides[1].Hazards)
 			}
-			fmt.Println("0. Go back to move selection (Cancel switch)")
-			fmt.Print("Enter your choice (0-6): ")
-			var switchChoice int
-			fmt.Scan(&switchChoice)
-			if switchChoice == 0 {
-				continue
+			if err := b.Submit(0, chooseAction(b, req)); err != nil {
+				log.Fatalf("Battle rejected your action: %v", err)
 			}
-			if switchChoice >= 1 && switchChoice <= len(playerSquad) {
-				if switchChoice-1 == playerActiveIndex {
-					fmt.Println("This Pokémon is already active.")
-					continue
-				}
-				playerActiveIndex = switchChoice - 1
-				fmt.Printf("You switched to %s!\n", playerSquad[playerActiveIndex].Base.Name)
-
-				enemyMoveData := enemyMovesets[enemyActiveIndex][rand.IntN(len(enemyMovesets[enemyActiveIndex]))]
-				if enemySquad[enemyActiveIndex].MovePP[enemyMoveData.Name] == 0 {
-					fmt.Println("Enemy tried to use", enemyMoveData.Name, "but it has no PP!")
-				} else {
-					fmt.Printf("Enemy %s uses %s!\n", enemySquad[enemyActiveIndex].Base.Name, enemyMoveData.Name)
-					enemySquad[enemyActiveIndex].UseMove(enemyMoveData.Name)
+		}
+		if req := b.RequestsFor(1); req != nil {
+			if err := b.Submit(1, enemy.Choose(battle.NewView(b, 1, req))); err != nil {
+				log.Fatalf("Battle rejected the enemy's action: %v", err)
+			}
+		}
+		events, _, err := b.Step()
+		if err != nil {
+			log.Fatalf("Battle step failed: %v", err)
+		}
+		printEvents(events)
+		if b.Turn != turn {
+			time.Sleep(1 * time.Second)
+		}
+	}
 
-					battle.ProcessEnemyTurn(playerSquad[playerActiveIndex], enemySquad[enemyActiveIndex], enemyMoveData)
+	switch {
+	case b.Lost(0) && b.Lost(1):
+		fmt.Println("\nEvery Pokémon has fainted! It's a draw!")
+	case b.Lost(1):
+		fmt.Println("\nAll enemy Pokémon have fainted! You win!")
+	default:
+		fmt.Println("\nAll your Pokémon have fainted! You lose!")
+	}
+	fmt.Println("\nExecution Time:", time.Since(start))
+}
 
-					if playerSquad[playerActiveIndex].Fainted {
-						fmt.Printf("\nYour %s has fainted. Choose a replacement.\n", playerSquad[playerActiveIndex].Base.Name)
-						newIndex := battle.SelectPokemon(playerPokemonSquad)
-						playerActiveIndex = newIndex
-						fmt.Printf("You sent out %s!\n", playerSquad[playerActiveIndex].Base.Name)
-					}
-				}
-				continue
-			} else {
-				fmt.Println("Invalid choice. Please select a valid Pokémon.")
-				continue
+// chooseAction asks the player how to answer req until they pick something
+// the battle allows.
+func chooseAction(b *battle.Battle, req *battle.Request) battle.Action {
+	squad := b.Team(0)
+	if req.ForceSwitch {
+		fmt.Printf("\nYour %s has fainted. Choose a replacement.\n", b.ActivePokemon(0).Base.Name)
+		for {
+			for _, i := range req.Switches {
+				fmt.Printf("%d. %s\n", i+1, squad[i].Base.Name)
+			}
+			fmt.Print("Choose Pokémon by number: ")
+			var idx int
+			fmt.Scan(&idx)
+			if action := battle.SwitchAction(idx - 1); req.Allows(action) == nil {
+				return action
 			}
+			fmt.Println("Invalid selection.")
 		}
+	}
 
-		if choice < 1 || choice > 4 {
-			fmt.Println("Invalid choice. Please select a valid action.")
-			continue
+	for {
+		active := b.ActivePokemon(0)
+		fmt.Println("\nMoveset:")
+		for i, move := range req.Moves {
+			fmt.Printf("%d. %s (PP: %d)", i+1, move.Name, move.PP)
+			if move.Forced {
+				fmt.Print(" (MUST USE)")
+			} else if move.Disabled != "" {
+				fmt.Printf(" (%s)", move.Disabled)
+			}
+			fmt.Println()
 		}
+		if active.MustStruggle() {
+			fmt.Printf("%s has no moves left! Any move choice uses Struggle.\n", active.Base.Name)
+		}
+		fmt.Println("0. Switch Pokémon")
+		fmt.Print("Select your action (0-4): ")
+		var choice int
+		fmt.Scan(&choice)
 
-		playerMoveData := playerMovesets[playerActiveIndex][choice-1]
-		enemyMoveData := enemyMovesets[enemyActiveIndex][rand.IntN(len(enemyMovesets[enemyActiveIndex]))]
+		if choice != 0 {
+			action := battle.MoveAction(choice - 1)
+			if active.MustStruggle() {
+				action = battle.MoveAction(0)
+			}
+			if err := req.Allows(action); err != nil {
+				fmt.Printf("You can't do that: %v\n", err)
+				continue
+			}
+			return action
+		}
 
-		if playerSquad[playerActiveIndex].MovePP[playerMoveData.Name] == 0 {
-			fmt.Println("This move has no PP left.")
+		if !req.CanSwitch {
+			fmt.Printf("%s can't be switched out right now!\n", active.Base.Name)
 			continue
 		}
-		if !playerSquad[playerActiveIndex].UseMove(playerMoveData.Name) {
-			fmt.Println("Move failed.")
-			continue
+		fmt.Println("Select Pokémon to switch to:")
+		for i, p := range squad {
+			fmt.Printf("%d. %s (HP: %d%%)\n", i+1, p.Base.Name, int(p.CurrentHP/p.MaxHP()*100))
 		}
-		if enemySquad[enemyActiveIndex].MovePP[enemyMoveData.Name] == 0 {
-			fmt.Println("Enemy tried to use", enemyMoveData.Name, "but it has no PP!")
+		fmt.Println("0. Go back to move selection (Cancel switch)")
+		fmt.Print("Enter your choice (0-6): ")
+		var switchChoice int
+		fmt.Scan(&switchChoice)
+		if switchChoice == 0 {
 			continue
 		}
-
-		battle.ExecuteBattleTurn(
-			playerSquad[playerActiveIndex],
-			enemySquad[enemyActiveIndex],
-			playerMoveData,
-			enemyMoveData,
-		)
-
-		if enemySquad[enemyActiveIndex].Fainted {
-			newEnemyIndex := battle.NextAvailablePokemon(enemyPokemonSquad, enemyActiveIndex)
-			if newEnemyIndex == -1 {
-				fmt.Println("\nAll enemy Pokémon have fainted! You win!")
-				break
-			}
-			enemyActiveIndex = newEnemyIndex
-			fmt.Printf("Enemy sent out %s!\n", enemySquad[enemyActiveIndex].Base.Name)
+		action := battle.SwitchAction(switchChoice - 1)
+		if err := req.Allows(action); err != nil {
+			fmt.Println("Invalid choice. Please select a valid Pokémon.")
 			continue
 		}
+		return action
+	}
+}
 
-		if playerSquad[playerActiveIndex].Fainted {
-			fmt.Printf("\nYour %s has fainted. Choose a replacement.\n", playerSquad[playerActiveIndex].Base.Name)
-			newIndex := battle.SelectPokemon(playerPokemonSquad)
-			playerActiveIndex = newIndex
-			fmt.Printf("You sent out %s!\n", playerSquad[playerActiveIndex].Base.Name)
-		}
+func printConditions(label string, conditions map[string]int) {
+	if len(conditions) == 0 {
+		return
+	}
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%d)", name, conditions[name])
+	}
+	fmt.Printf("%s: %s\n", label, strings.Join(parts, ", "))
+}
 
-		time.Sleep(1 * time.Second)
+func printEvents(events []battle.Event) {
+	for _, line := range battle.Render(events) {
+		fmt.Println(line)
 	}
-	fmt.Println("\nExecution Time:", time.Since(start))
 }
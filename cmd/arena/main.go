@@ -0,0 +1,142 @@
+// Command arena pits two opponent difficulties against each other in
+// headless battles and reports how each fared.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// timedAgent records how long its agent takes to decide.
+type timedAgent struct {
+	battle.Agent
+	decisions int
+	thinking  time.Duration
+}
+
+func (t *timedAgent) Choose(v battle.View) battle.Action {
+	start := time.Now()
+	defer func() {
+		t.decisions++
+		t.thinking += time.Since(start)
+	}()
+	return t.Agent.Choose(v)
+}
+
+// record is how one difficulty fared over the games.
+type record struct {
+	name                string
+	wins, losses, draws int
+	decisions           int
+	thinking            time.Duration
+}
+
+func main() {
+	dataSpec := flag.String("data", "auto", "Data source: auto, http, bundled, or a local store directory")
+	formatName := flag.String("format", battle.DefaultFormat, "Battle format: random, singles, vgc, little-cup or level-N, optionally prefixed with genN-")
+	seed := flag.Uint64("seed", 0, "Seed of the first game; game i plays seed+i (0 picks a new one)")
+	games := flag.Int("games", 10, "Number of games to play")
+	maxTurns := flag.Int("turns", 300, "Turns after which an unfinished game counts as a draw")
+	names := strings.Join(battle.DifficultyNames(), ", ")
+	first := flag.String("a", "master", "Difficulty of the first contender: "+names)
+	second := flag.String("b", "hard", "Difficulty of the second contender: "+names)
+	flag.Parse()
+
+	format, err := battle.LookupFormat(*formatName)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+	src, err := pokemon.OpenSource(*dataSpec)
+	if err != nil {
+		log.Fatalf("Failed to open data source: %v", err)
+	}
+	ctx := context.Background()
+	if err := format.LoadChart(ctx, src); err != nil {
+		log.Fatalf("Failed to load type chart: %v", err)
+	}
+	if *seed == 0 {
+		*seed = battle.NewSeed()
+	}
+	fmt.Printf("Seeds %d to %d\n", *seed, *seed+uint64(*games)-1)
+
+	needsPool := map[string]bool{}
+	for _, name := range []string{*first, *second} {
+		if needsPool[name], err = battle.NeedsSetPool(name); err != nil {
+			log.Fatalf("Invalid difficulty: %v", err)
+		}
+	}
+
+	records := [2]*record{{name: *first}, {name: *second}}
+	for game := range *games {
+		// The contenders swap sides every game so neither keeps an edge.
+		sides := [2]*record{records[game%2], records[1-game%2]}
+		gameSeed := *seed + uint64(game)
+		field := battle.NewField(format, gameSeed)
+		playerSquad, enemySquad, err := battle.SetupHeadlessSquads(ctx, src, format, field.Rand())
+		if err != nil {
+			log.Fatalf("Game %d: failed to set up squads: %v", game+1, err)
+		}
+		teams := [2][]*battle.BattlePokemon{playerSquad, enemySquad}
+
+		var agents [2]battle.Agent
+		var timers [2]*timedAgent
+		for side, rec := range sides {
+			var pool *battle.SetPool
+			if needsPool[rec.name] {
+				if pool, err = battle.LoadSetPool(ctx, src, teams[1-side]); err != nil {
+					log.Fatalf("Game %d: failed to load move pool: %v", game+1, err)
+				}
+			}
+			agent, err := battle.LookupAgent(rec.name, field.Rand(), pool)
+			if err != nil {
+				log.Fatalf("Invalid difficulty: %v", err)
+			}
+			timers[side] = &timedAgent{Agent: agent}
+			agents[side] = timers[side]
+		}
+
+		b := battle.NewBattle(field, [2]string{sides[0].name, sides[1].name}, teams, [2]int{0, 0})
+		b.Start()
+		if err := battle.Play(b, agents, *maxTurns); err != nil {
+			log.Fatalf("Game %d: %v", game+1, err)
+		}
+
+		result := "draw"
+		switch {
+		case b.Over() && b.Lost(0) && b.Lost(1):
+		case b.Lost(1):
+			result = sides[0].name + " won"
+			sides[0].wins++
+			sides[1].losses++
+		case b.Lost(0):
+			result = sides[1].name + " won"
+			sides[1].wins++
+			sides[0].losses++
+		}
+		if result == "draw" {
+			sides[0].draws++
+			sides[1].draws++
+		}
+		for side, rec := range sides {
+			rec.decisions += timers[side].decisions
+			rec.thinking += timers[side].thinking
+		}
+		fmt.Printf("Game %d (seed %d): %s after %d turns\n", game+1, gameSeed, result, b.Turn-1)
+	}
+
+	fmt.Println()
+	for _, rec := range records {
+		average := time.Duration(0)
+		if rec.decisions > 0 {
+			average = rec.thinking / time.Duration(rec.decisions)
+		}
+		fmt.Printf("%-8s %3d won, %3d lost, %3d drawn, %v per decision\n", rec.name, rec.wins, rec.losses, rec.draws, average)
+	}
+}
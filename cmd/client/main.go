@@ -9,12 +9,14 @@ import (
 	"syscall"
 
 	"github.com/ross1116/pokebattlecli/client"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
 func main() {
 	serverHost := flag.String("host", "localhost", "Server host address")
 	serverPort := flag.String("port", "9090", "Server port")
 	username := flag.String("user", "", "Your username")
+	dataSpec := flag.String("data", "auto", "Data source: auto, http, bundled, or a local store directory")
 
 	flag.Parse()
 
@@ -24,10 +26,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	src, err := pokemon.OpenSource(*dataSpec)
+	if err != nil {
+		log.Fatalf("Failed to open data source: %v", err)
+	}
+
 	config := &client.Config{
 		ServerHost: *serverHost,
 		ServerPort: *serverPort,
 		Username:   *username,
+		DataSource: src,
 	}
 
 	c := client.New(config)
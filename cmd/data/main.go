@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "sync":
+		runSync(os.Args[2:])
+	case "verify":
+		runVerify(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  data sync   [-dir DIR] [-from N] [-to N] [-concurrency N] [-quiet]")
+	fmt.Fprintln(os.Stderr, "  data verify [-dir DIR]")
+}
+
+func runSync(args []string) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", pokemon.DefaultStoreDir(), "Local data store directory")
+	from := fs.Int("from", 1, "First dex number to sync")
+	to := fs.Int("to", pokemon.MaxDexNumber, "Last dex number to sync")
+	concurrency := fs.Int("concurrency", 10, "Maximum parallel downloads")
+	quiet := fs.Bool("quiet", false, "Only print the summary")
+	fs.Parse(args)
+
+	opts := pokemon.SyncOptions{
+		Dir:         *dir,
+		DexFrom:     *from,
+		DexTo:       *to,
+		Concurrency: *concurrency,
+		Items:       battle.ItemNames(),
+	}
+	if !*quiet {
+		opts.Logf = log.Printf
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := pokemon.SyncStore(ctx, opts)
+	fmt.Printf("Downloaded %d, already up to date %d, failed %d.\n", report.Downloaded, report.Skipped, report.Failed)
+	if err != nil {
+		log.Fatalf("Sync incomplete: %v", err)
+	}
+
+	problems, err := pokemon.VerifyStore(*dir)
+	if err != nil {
+		log.Fatalf("Failed to verify data store: %v", err)
+	}
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		log.Fatalf("Data store has %d problems; run sync again to repair it.", len(problems))
+	}
+	fmt.Printf("Data store ready at %s\n", *dir)
+}
+
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	dir := fs.String("dir", pokemon.DefaultStoreDir(), "Local data store directory")
+	fs.Parse(args)
+
+	problems, err := pokemon.VerifyStore(*dir)
+	if err != nil {
+		log.Fatalf("Failed to verify data store: %v", err)
+	}
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("Data store at %s is intact.\n", *dir)
+}
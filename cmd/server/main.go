@@ -1,13 +1,38 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 	"github.com/ross1116/pokebattlecli/server"
 )
 
 func main() {
+	dataSpec := flag.String("data", "auto", "Data source: auto, http, bundled, or a local store directory")
+	formatName := flag.String("format", battle.DefaultFormat, "Battle format: random, singles, vgc, little-cup or level-N, optionally prefixed with genN-")
+	flag.Parse()
+
+	format, err := battle.LookupFormat(*formatName)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	src, err := pokemon.OpenSource(*dataSpec)
+	if err != nil {
+		log.Fatalf("Failed to open data source: %v", err)
+	}
+	if err := format.LoadChart(context.Background(), src); err != nil {
+		log.Fatalf("Failed to load type chart: %v", err)
+	}
+
 	config := server.Config{
-		Host: "localhost",
-		Port: "9090",
+		Host:       "localhost",
+		Port:       "9090",
+		DataSource: src,
+		Format:     format,
 	}
 	srv := server.New(&config)
 	srv.Run()
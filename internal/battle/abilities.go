@@ -0,0 +1,313 @@
+package battle
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// Ability describes how an ability hooks into the battle. Every hook is
+// optional and receives the holder as self.
+type Ability struct {
+	Name string
+
+	// OnSwitchIn runs when the holder enters the field.
+	OnSwitchIn func(self, foe *BattlePokemon, field *Field) []Event
+	// Immunity reports whether the holder is unaffected by move.
+	Immunity func(self *BattlePokemon, move *pokemon.MoveInfo) bool
+	// Absorb reacts to a move Immunity blocked, as Flash Fire and Water
+	// Absorb do. When it is missing or does nothing, the move is reported
+	// as not affecting the holder because of its ability.
+	Absorb func(self *BattlePokemon, move *pokemon.MoveInfo) []Event
+	// ModifyHit adjusts a damaging move while DamageCalc works it out. It is
+	// called for both the attacker and the defender.
+	ModifyHit func(self *BattlePokemon, hit *Hit)
+	// BeforeDamage runs on the defender just before it loses HP and returns
+	// the damage actually taken.
+	BeforeDamage func(self, attacker *BattlePokemon, move *pokemon.MoveInfo, dmg int) (int, []Event)
+	// AfterDamage runs on the defender once a move has hit it.
+	AfterDamage func(self, attacker *BattlePokemon, move *pokemon.MoveInfo, dmg int) []Event
+	// EndOfTurn runs with the holder's residual effects.
+	EndOfTurn func(self *BattlePokemon) []Event
+	// ModifySpeed scales the holder's speed when ordering the turn.
+	ModifySpeed func(self *BattlePokemon, field *Field) float64
+}
+
+// Hit holds the multipliers DamageCalc applies on top of the base formula.
+type Hit struct {
+	Attacker   *BattlePokemon
+	Defender   *BattlePokemon
+	Move       *pokemon.MoveInfo
+	Power      float64
+	Attack     float64
+	Defense    float64
+	Damage     float64
+	IgnoreBurn bool
+}
+
+var noAbility = &Ability{}
+
+var abilities = map[string]*Ability{}
+
+func registerAbility(a *Ability) {
+	abilities[a.Name] = a
+}
+
+// LookupAbility returns an ability's battle effect, or nil if it has none.
+func LookupAbility(name string) *Ability {
+	return abilities[name]
+}
+
+func (bp *BattlePokemon) ability() *Ability {
+	if a, ok := abilities[bp.Ability]; ok {
+		return a
+	}
+	return noAbility
+}
+
+// displayName turns a PokeAPI identifier such as "water-absorb" into
+// "Water Absorb" for battle messages. Items use it too.
+func displayName(name string) string {
+	words := strings.Split(name, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func init() {
+	registerAbility(&Ability{
+		Name: "levitate",
+		Immunity: func(self *BattlePokemon, move *pokemon.MoveInfo) bool {
+			return move.Type.Name == "ground"
+		},
+	})
+
+	registerAbility(&Ability{
+		Name: "intimidate",
+		OnSwitchIn: func(self, foe *BattlePokemon, field *Field) []Event {
+			if foe == nil || foe.Fainted {
+				return nil
+			}
+			events := []Event{Activate{Pokemon: self.Base.Name, Side: self.side, Effect: "intimidate", Target: foe.Base.Name}}
+			return append(events, foe.changeStage("attack", -1)...)
+		},
+	})
+
+	registerAbility(&Ability{
+		Name: "static",
+		AfterDamage: func(self, attacker *BattlePokemon, move *pokemon.MoveInfo, dmg int) []Event {
+			if !makesContact(move) || attacker.Fainted || attacker.Status != "" || hasType(attacker, "electric") {
+				return nil
+			}
+			if self.rng().Float64() >= 0.3 {
+				return nil
+			}
+			return attacker.inflictStatus("par", "static", self.Base.Name)
+		},
+	})
+
+	registerAbility(&Ability{
+		Name: "flash-fire",
+		Immunity: func(self *BattlePokemon, move *pokemon.MoveInfo) bool {
+			return move.Type.Name == "fire"
+		},
+		Absorb: func(self *BattlePokemon, move *pokemon.MoveInfo) []Event {
+			self.setVolatile("flash-fire", "flash-fire", 0)
+			return []Event{Activate{Pokemon: self.Base.Name, Side: self.side, Effect: "flash-fire"}}
+		},
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && self.Volatile.Has("flash-fire") && hit.Move.Type.Name == "fire" {
+				hit.Power *= 1.5
+			}
+		},
+	})
+
+	registerAbility(weatherSpeedAbility("swift-swim", "rain"))
+	registerAbility(weatherSpeedAbility("chlorophyll", "sun"))
+	registerAbility(weatherSpeedAbility("sand-rush", "sandstorm"))
+	registerAbility(weatherAbility("drizzle", "rain"))
+	registerAbility(weatherAbility("drought", "sun"))
+	registerAbility(weatherAbility("sand-stream", "sandstorm"))
+	registerAbility(weatherAbility("snow-warning", "hail"))
+
+	registerAbility(&Ability{
+		Name: "sturdy",
+		Immunity: func(self *BattlePokemon, move *pokemon.MoveInfo) bool {
+			return ohko(move)
+		},
+		BeforeDamage: func(self, attacker *BattlePokemon, move *pokemon.MoveInfo, dmg int) (int, []Event) {
+			maxHP := self.MaxHP()
+			if maxHP <= 0 || self.CurrentHP < maxHP || float64(dmg) < self.CurrentHP {
+				return dmg, nil
+			}
+			return int(self.CurrentHP) - 1, []Event{Activate{Pokemon: self.Base.Name, Side: self.side, Effect: "sturdy"}}
+		},
+	})
+
+	registerAbility(pinchAbility("blaze", "fire"))
+	registerAbility(pinchAbility("torrent", "water"))
+	registerAbility(pinchAbility("overgrow", "grass"))
+	registerAbility(pinchAbility("swarm", "bug"))
+
+	registerAbility(absorbAbility("water-absorb", "water"))
+	registerAbility(absorbAbility("volt-absorb", "electric"))
+
+	registerAbility(&Ability{
+		Name: "thick-fat",
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Defender == self && (hit.Move.Type.Name == "fire" || hit.Move.Type.Name == "ice") {
+				hit.Attack *= 0.5
+			}
+		},
+	})
+
+	registerAbility(&Ability{
+		Name: "guts",
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && self.Status != "" && hit.Move.DamageClass.Name == "physical" {
+				hit.Attack *= 1.5
+				hit.IgnoreBurn = true
+			}
+		},
+	})
+
+	registerAbility(&Ability{
+		Name: "speed-boost",
+		EndOfTurn: func(self *BattlePokemon) []Event {
+			if self.StatStages["speed"] >= 6 {
+				return nil
+			}
+			events := []Event{Activate{Pokemon: self.Base.Name, Side: self.side, Effect: "speed-boost"}}
+			return append(events, self.changeStage("speed", 1)...)
+		},
+	})
+}
+
+// pinchAbility boosts moves of one type by half once the holder is at or
+// below a third of its HP (Blaze, Torrent, Overgrow, Swarm).
+func pinchAbility(name, moveType string) *Ability {
+	return &Ability{
+		Name: name,
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && hit.Move.Type.Name == moveType && self.CurrentHP <= self.MaxHP()/3 {
+				hit.Power *= 1.5
+			}
+		},
+	}
+}
+
+// absorbAbility makes the holder immune to one type and heals it by a
+// quarter of its HP instead.
+func absorbAbility(name, moveType string) *Ability {
+	return &Ability{
+		Name: name,
+		Immunity: func(self *BattlePokemon, move *pokemon.MoveInfo) bool {
+			return move.Type.Name == moveType
+		},
+		Absorb: func(self *BattlePokemon, move *pokemon.MoveInfo) []Event {
+			return self.restore(self.MaxHP()/4, name)
+		},
+	}
+}
+
+// weatherSpeedAbility doubles the holder's speed in the given weather.
+func weatherSpeedAbility(name, weather string) *Ability {
+	return &Ability{
+		Name: name,
+		ModifySpeed: func(self *BattlePokemon, field *Field) float64 {
+			if field.weather() == weather {
+				return 2
+			}
+			return 1
+		},
+	}
+}
+
+// SwitchIn runs the entry effects for a Pokémon that has just been sent out.
+// foe is the opposing active Pokémon, if any.
+func SwitchIn(incoming, foe *BattlePokemon, field *Field) []Event {
+	if incoming == nil || incoming.Fainted {
+		return nil
+	}
+	incoming.Volatile = make(Volatiles)
+	incoming.ChoiceLock = ""
+	if hook := incoming.ability().OnSwitchIn; hook != nil {
+		return hook(incoming, foe, field)
+	}
+	return nil
+}
+
+// SwitchOut clears what a Pokémon loses when it leaves the field: stat
+// stages, volatile conditions, any choice lock and the toxic counter.
+func SwitchOut(outgoing *BattlePokemon) {
+	if outgoing == nil {
+		return
+	}
+	outgoing.StatStages = make(map[string]int)
+	outgoing.Volatile = make(Volatiles)
+	outgoing.ChoiceLock = ""
+	outgoing.ForcedMove = ""
+	outgoing.LastDamage, outgoing.LastDamageClass = 0, ""
+	if outgoing.Status == "tox" {
+		outgoing.StatusTurns = 0
+	}
+}
+
+func abilityImmunity(defender *BattlePokemon, move *pokemon.MoveInfo) (bool, []Event) {
+	if !abilityBlocks(defender, move) {
+		return false, nil
+	}
+	a := defender.ability()
+	if a.Absorb != nil {
+		if events := a.Absorb(defender, move); events != nil {
+			return true, events
+		}
+	}
+	return true, []Event{Immune{Pokemon: defender.Base.Name, Side: defender.side, Ability: a.Name}}
+}
+
+// abilityBlocks reports whether defender's ability makes it immune to move,
+// without reacting to it.
+func abilityBlocks(defender *BattlePokemon, move *pokemon.MoveInfo) bool {
+	hook := defender.ability().Immunity
+	return hook != nil && hook(defender, move)
+}
+
+// nonContactPhysical lists the physical moves that do not touch the target.
+// PokeAPI does not expose move flags, so every other physical move counts as
+// contact.
+var nonContactPhysical = map[string]bool{
+	"bullet-seed": true, "earthquake": true, "fissure": true, "magnitude": true,
+	"razor-leaf": true, "rock-slide": true, "rock-throw": true, "poison-sting": true,
+	"stone-edge": true, "bonemerang": true, "bone-club": true, "explosion": true,
+	"self-destruct": true, "egg-bomb": true, "barrage": true, "spike-cannon": true,
+	"pin-missile": true, "twineedle": true,
+}
+
+func makesContact(move *pokemon.MoveInfo) bool {
+	return move.DamageClass.Name == "physical" && !nonContactPhysical[move.Name]
+}
+
+func hasType(bp *BattlePokemon, typeName string) bool {
+	return slices.Contains(bp.types(), typeName)
+}
+
+// types lists bp's current types. A Pokémon that used Roost this turn loses
+// its Flying type, and a pure Flying type becomes Normal.
+func (bp *BattlePokemon) types() []string {
+	types := make([]string, 0, len(bp.Base.Types))
+	for _, t := range bp.Base.Types {
+		if t.Type.Name == "flying" && bp.Volatile.Has("roost") {
+			continue
+		}
+		types = append(types, t.Type.Name)
+	}
+	if len(types) == 0 && len(bp.Base.Types) > 0 {
+		types = append(types, "normal")
+	}
+	return types
+}
@@ -0,0 +1,99 @@
+package battle_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func newBattler(t *testing.T, name, ability string, moves ...string) *battle.BattlePokemon {
+	t.Helper()
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+	base, err := src.Pokemon(ctx, name)
+	if err != nil {
+		t.Fatalf("Failed to fetch %s: %v", name, err)
+	}
+	var moveset []*pokemon.MoveInfo
+	for _, m := range moves {
+		move, err := src.MoveByName(ctx, m)
+		if err != nil {
+			t.Fatalf("Failed to fetch move %s: %v", m, err)
+		}
+		moveset = append(moveset, move)
+	}
+	bp := battle.NewBattlePokemon(base, moveset)
+	if ability != "" {
+		if err := bp.SetAbility(ability); err != nil {
+			t.Fatalf("SetAbility: %v", err)
+		}
+	}
+	// Battlers roll on a field of their own until a test seats them on one.
+	battle.NewField(battle.Format{}, 1).Join(0, "", []*battle.BattlePokemon{bp})
+	return bp
+}
+
+func TestLevitateBlocksGroundMoves(t *testing.T) {
+	gengar := newBattler(t, "gengar", "levitate")
+	golem := newBattler(t, "golem", "sturdy", "earthquake")
+
+	dmg, _, events := battle.DamageCalc(golem, gengar, golem.Moves[0], nil)
+	if dmg != 0 {
+		t.Fatalf("Expected Levitate to block Earthquake, took %d damage", dmg)
+	}
+	if len(events) == 0 || events[0] != (battle.Immune{Pokemon: "gengar", Ability: "levitate"}) {
+		t.Errorf("Expected a Levitate message, got %v", events)
+	}
+}
+
+func TestIntimidateLowersAttackOnSwitchIn(t *testing.T) {
+	gyarados := newBattler(t, "gyarados", "intimidate")
+	golem := newBattler(t, "golem", "sturdy")
+
+	events := battle.SwitchIn(gyarados, golem, nil)
+	if golem.StatStages["attack"] != -1 {
+		t.Errorf("Expected attack stage -1 after Intimidate, got %d", golem.StatStages["attack"])
+	}
+	if len(events) == 0 {
+		t.Error("Expected Intimidate to announce itself")
+	}
+}
+
+func TestSturdySurvivesOneHitFromFullHP(t *testing.T) {
+	blastoise := newBattler(t, "blastoise", "torrent", "surf")
+	golem := newBattler(t, "golem", "sturdy")
+
+	battle.ExecuteTurn(blastoise, golem, blastoise.Moves[0], nil, nil)
+	if golem.Fainted || golem.CurrentHP != 1 {
+		t.Errorf("Expected Golem to hang on at 1 HP, got %.1f (fainted=%v)", golem.CurrentHP, golem.Fainted)
+	}
+}
+
+func TestFlashFireAbsorbsFireMoves(t *testing.T) {
+	charmander := newBattler(t, "charmander", "blaze", "ember")
+	arcanine := newBattler(t, "arcanine", "flash-fire")
+
+	dmg, _, _ := battle.DamageCalc(charmander, arcanine, charmander.Moves[0], nil)
+	if dmg != 0 {
+		t.Fatalf("Expected Flash Fire to absorb Ember, took %d damage", dmg)
+	}
+	if !arcanine.Volatile.Has("flash-fire") {
+		t.Error("Expected Flash Fire to be activated")
+	}
+}
+
+func TestSwiftSwimDoublesSpeedInRain(t *testing.T) {
+	kingdra := newBattler(t, "kingdra", "swift-swim", "tackle")
+	sneasel := newBattler(t, "sneasel", "inner-focus", "tackle")
+
+	first, _, _, _ := battle.ResolveTurn(kingdra, sneasel, kingdra.Moves[0], sneasel.Moves[0], nil)
+	if first != sneasel {
+		t.Fatalf("Expected Sneasel to outspeed Kingdra on a clear field")
+	}
+	first, _, _, _ = battle.ResolveTurn(kingdra, sneasel, kingdra.Moves[0], sneasel.Moves[0], &battle.Field{Weather: "rain"})
+	if first != kingdra {
+		t.Errorf("Expected Kingdra to outspeed Sneasel in rain")
+	}
+}
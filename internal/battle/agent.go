@@ -0,0 +1,361 @@
+package battle
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+	"github.com/ross1116/pokebattlecli/internal/stats"
+)
+
+// Agent decides for one side of a battle. Choose is called with the side's
+// view whenever the battle requests a decision from it and returns an
+// action the request allows.
+type Agent interface {
+	Choose(v View) Action
+}
+
+// View is what a side has in front of it when it decides: the battle as the
+// side has seen it, which side it plays and the request it owes. Battle is
+// a copy the agent may read or play on without touching the real battle.
+type View struct {
+	Battle  *Battle
+	Side    int
+	Request *Request
+}
+
+// NewView builds side's view of b for answering req. The copy holds side's
+// own squad in full, but of the foe's squad only the moves, abilities and
+// items that have been revealed, and a neutral nature for every member.
+func NewView(b *Battle, side int, req *Request) View {
+	c := b.Clone(NewRand(b.Field.Seed ^ uint64(b.Turn)<<1 ^ uint64(side)).Uint64())
+	c.conceal(1 - side)
+	return View{Battle: c, Side: side, Request: req}
+}
+
+// conceal strips side's squad down to what the other side has seen of it.
+func (b *Battle) conceal(side int) {
+	for i, bp := range b.Team(side) {
+		if bp == nil || bp.Base == nil {
+			continue
+		}
+		set := b.revealedSet(side, i)
+		moves := bp.Moves[:0]
+		for _, move := range bp.Moves {
+			if slices.Contains(set.moves, move.Name) {
+				moves = append(moves, move)
+			} else {
+				delete(bp.MovePP, move.Name)
+			}
+		}
+		bp.Moves = moves
+		if !set.ability {
+			bp.Ability = ""
+		}
+		if !set.item {
+			bp.Item, bp.ChoiceLock = "", ""
+		}
+		bp.Spread.Nature = stats.DefaultSpread().Nature
+		bp.Stats = stats.Compute(bp.Base, bp.Spread)
+	}
+}
+
+// Self returns the side's active Pokémon.
+func (v View) Self() *BattlePokemon {
+	return v.Battle.ActivePokemon(v.Side)
+}
+
+// Foe returns the other side's active Pokémon.
+func (v View) Foe() *BattlePokemon {
+	return v.Battle.ActivePokemon(1 - v.Side)
+}
+
+// move returns the move option i of the request stands for.
+func (v View) move(i int) *pokemon.MoveInfo {
+	return v.Battle.chosenMove(v.Side, i)
+}
+
+// usableMoves lists the indexes of the move options that can be picked.
+func (r *Request) usableMoves() []int {
+	var usable []int
+	for i, move := range r.Moves {
+		if move.Usable() {
+			usable = append(usable, i)
+		}
+	}
+	return usable
+}
+
+// Play runs b with agents deciding for each side until it is over or
+// maxTurns more turns have been played, whichever comes first; a maxTurns
+// of 0 plays to the end. It needs no terminal, so agents can be pitted
+// against each other headless. The events are discarded.
+func Play(b *Battle, agents [2]Agent, maxTurns int) error {
+	return play(b, agents, maxTurns, NewView)
+}
+
+// play is Play with the agents deciding from the views view builds.
+func play(b *Battle, agents [2]Agent, maxTurns int, view func(b *Battle, side int, req *Request) View) error {
+	last := b.Turn + maxTurns
+	for !b.Over() && (maxTurns <= 0 || b.Turn < last) {
+		for side, agent := range agents {
+			req := b.RequestsFor(side)
+			if req == nil {
+				continue
+			}
+			if err := b.Submit(side, agent.Choose(view(b, side, req))); err != nil {
+				return fmt.Errorf("side %d: %w", side, err)
+			}
+		}
+		if _, _, err := b.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultDifficulty is the difficulty single-player opponents play at
+// unless told otherwise.
+const DefaultDifficulty = "medium"
+
+// difficulties pairs each difficulty with the agent that plays it, from the
+// easiest up. Agents that guess the foe's hidden sets need a pool to deal
+// them from.
+var difficulties = []struct {
+	name      string
+	needsPool bool
+	agent     func(r *rand.Rand, pool *SetPool) Agent
+}{
+	{"easy", false, func(r *rand.Rand, _ *SetPool) Agent { return RandomAgent{Rand: r} }},
+	{"medium", false, func(*rand.Rand, *SetPool) Agent { return GreedyAgent{} }},
+	{"hard", false, func(*rand.Rand, *SetPool) Agent { return HeuristicAgent{} }},
+	{"expert", false, func(*rand.Rand, *SetPool) Agent { return ExpectiminimaxAgent{Depth: 2} }},
+	{"master", true, func(r *rand.Rand, pool *SetPool) Agent { return MCTSAgent{Pool: pool, Iterations: 1000, Rand: r} }},
+}
+
+func lookupDifficulty(difficulty string) (int, error) {
+	if difficulty == "" {
+		difficulty = DefaultDifficulty
+	}
+	for i, d := range difficulties {
+		if d.name == difficulty {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown difficulty %q (want one of %s)", difficulty, strings.Join(DifficultyNames(), ", "))
+}
+
+// NeedsSetPool reports whether the agent at difficulty must be given a
+// SetPool of the foe's squad.
+func NeedsSetPool(difficulty string) (bool, error) {
+	i, err := lookupDifficulty(difficulty)
+	if err != nil {
+		return false, err
+	}
+	return difficulties[i].needsPool, nil
+}
+
+// LookupAgent resolves a -difficulty flag value to the agent that plays at
+// it. Agents that roll dice draw from r, and those that guess the foe's
+// hidden sets deal them from pool, without which they cannot play.
+func LookupAgent(difficulty string, r *rand.Rand, pool *SetPool) (Agent, error) {
+	i, err := lookupDifficulty(difficulty)
+	if err != nil {
+		return nil, err
+	}
+	d := difficulties[i]
+	if d.needsPool && pool == nil {
+		return nil, fmt.Errorf("difficulty %s needs a set pool of the foe's squad", d.name)
+	}
+	return d.agent(r, pool), nil
+}
+
+// DifficultyNames lists the difficulties from the easiest up.
+func DifficultyNames() []string {
+	names := make([]string, len(difficulties))
+	for i, d := range difficulties {
+		names[i] = d.name
+	}
+	return names
+}
+
+// RandomAgent picks uniformly among the usable moves and, when its active
+// Pokémon has fainted, among the replacements. It never switches by choice.
+type RandomAgent struct {
+	Rand *rand.Rand
+}
+
+func (a RandomAgent) Choose(v View) Action {
+	req := v.Request
+	if req.ForceSwitch && len(req.Switches) > 0 {
+		return SwitchAction(req.Switches[a.Rand.IntN(len(req.Switches))])
+	}
+	usable := req.usableMoves()
+	if len(usable) == 0 {
+		return req.DefaultAction()
+	}
+	return MoveAction(usable[a.Rand.IntN(len(usable))])
+}
+
+// GreedyAgent picks the move expected to do the most damage to the foe, and
+// replaces a fainted Pokémon with the member that can hit the foe hardest.
+// It never switches by choice.
+type GreedyAgent struct{}
+
+func (GreedyAgent) Choose(v View) Action {
+	req := v.Request
+	if req.ForceSwitch {
+		return bestSwitch(v, func(member *BattlePokemon) float64 {
+			return bestDamage(member, v.Foe(), v.Battle.Field)
+		})
+	}
+	best, bestScore := req.DefaultAction(), -1.0
+	for _, i := range req.usableMoves() {
+		if score := ExpectedDamage(v.Self(), v.Foe(), v.move(i), v.Battle.Field); score > bestScore {
+			best, bestScore = MoveAction(i), score
+		}
+	}
+	return best
+}
+
+// switchMargin is how much better a benched member's matchup must be before
+// HeuristicAgent gives up the turn to switch to it.
+const switchMargin = 0.6
+
+// HeuristicAgent weighs the matchup as well as damage. It switches out of a
+// matchup the foe is winning when a benched member fares clearly better,
+// passes over moves the foe is immune to, and uses status, boosting and
+// healing moves when they are likely to pay off.
+type HeuristicAgent struct{}
+
+func (HeuristicAgent) Choose(v View) Action {
+	req, self, foe, field := v.Request, v.Self(), v.Foe(), v.Battle.Field
+	score := func(member *BattlePokemon) float64 { return matchup(member, foe, field) }
+	if req.ForceSwitch {
+		return bestSwitch(v, score)
+	}
+
+	current := score(self)
+	outpaces := effectiveSpeed(self, field) > effectiveSpeed(foe, field)
+	if req.CanSwitch && current < 0 && !(outpaces && pressure(self, foe, field) >= 1) {
+		if action := bestSwitch(v, score); score(v.Battle.Team(v.Side)[action.Switch]) >= current+switchMargin {
+			return action
+		}
+	}
+
+	best, bestScore := req.DefaultAction(), -1.0
+	for _, i := range req.usableMoves() {
+		if s := moveScore(self, foe, v.move(i), field); s > bestScore {
+			best, bestScore = MoveAction(i), s
+		}
+	}
+	return best
+}
+
+// bestSwitch returns the switch to the member score rates highest, or the
+// request's default action if there is nobody to send in.
+func bestSwitch(v View, score func(member *BattlePokemon) float64) Action {
+	req := v.Request
+	if len(req.Switches) == 0 {
+		return req.DefaultAction()
+	}
+	team := v.Battle.Team(v.Side)
+	best, bestScore := req.Switches[0], score(team[req.Switches[0]])
+	for _, i := range req.Switches[1:] {
+		if s := score(team[i]); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return SwitchAction(best)
+}
+
+// bestDamage is the most damage any of user's moves can be expected to do
+// to target.
+func bestDamage(user, target *BattlePokemon, field *Field) float64 {
+	best := 0.0
+	for _, move := range user.Moves {
+		if user.MovePP[move.Name] > 0 {
+			best = max(best, ExpectedDamage(user, target, move, field))
+		}
+	}
+	return best
+}
+
+// pressure is the share of target's remaining HP user's best move can be
+// expected to take, from 0 to 1.
+func pressure(user, target *BattlePokemon, field *Field) float64 {
+	if target.CurrentHP <= 0 {
+		return 0
+	}
+	return bestDamage(user, target, field) / target.CurrentHP
+}
+
+// matchup rates how own fares against foe, from -1 when foe can knock it
+// out while barely scratching back to 1 the other way round.
+func matchup(own, foe *BattlePokemon, field *Field) float64 {
+	return pressure(own, foe, field) - pressure(foe, own, field)
+}
+
+// statusWorth is what HeuristicAgent reckons inflicting each status is worth,
+// measured like damage as a share of the foe's HP.
+var statusWorth = map[string]float64{
+	"slp":       0.5,
+	"tox":       0.4,
+	"par":       0.35,
+	"brn":       0.35,
+	"psn":       0.25,
+	"frz":       0.5,
+	"confusion": 0.2,
+}
+
+// moveScore rates move for HeuristicAgent as a share of the foe's HP: the
+// damage it is expected to do, or what its effect is worth if it can take
+// hold.
+func moveScore(user, foe *BattlePokemon, move *pokemon.MoveInfo, field *Field) float64 {
+	if move.Power > 0 || fixedDamageMove(move) {
+		if foe.CurrentHP <= 0 {
+			return 0
+		}
+		return ExpectedDamage(user, foe, move, field) / foe.CurrentHP
+	}
+	if targetsFoe(move) && behindSubstitute(user, foe) {
+		return 0
+	}
+
+	switch move.Meta.Category.Name {
+	case "ailment":
+		ailment := move.Meta.Ailment.Name
+		if ailment == "confusion" {
+			if foe.Volatile.Has("confusion") {
+				return 0
+			}
+			return statusWorth["confusion"] * hitChance(user, foe, move, field)
+		}
+		status, ok := ailmentStatus[ailment]
+		if status == "psn" && move.Name == "toxic" {
+			status = "tox"
+		}
+		if !ok || foe.Status != "" || statusImmune(foe, status) || terrainBlocksStatus(field, foe, status) {
+			return 0
+		}
+		return statusWorth[status] * hitChance(user, foe, move, field)
+	case "net-good-stats":
+		// Boosting pays off while the user is healthy and not under threat.
+		if targetsFoe(move) || user.CurrentHP < user.MaxHP()*2/3 || pressure(foe, user, field) >= 0.5 {
+			return 0
+		}
+		for _, change := range move.StatChanges {
+			if change.Change > 0 && user.StatStages[change.Stat.Name] < 2 {
+				return 0.3
+			}
+		}
+	case "heal":
+		if user.CurrentHP > user.MaxHP()/2 {
+			return 0
+		}
+		return min(healingPercent(move, field)/100*user.MaxHP(), user.MaxHP()-user.CurrentHP) / user.MaxHP()
+	}
+	return 0
+}
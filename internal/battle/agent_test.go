@@ -0,0 +1,192 @@
+package battle_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+// playTurn has both sides pick actions for one turn of b, so the moves they
+// use are revealed.
+func playTurn(t *testing.T, b *battle.Battle, actions [2]battle.Action) {
+	t.Helper()
+	for side, action := range actions {
+		if err := b.Submit(side, action); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := b.Step(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// choose asks agent for side's answer to b's current request.
+func choose(t *testing.T, agent battle.Agent, b *battle.Battle, side int) battle.Action {
+	t.Helper()
+	req := b.RequestsFor(side)
+	if req == nil {
+		t.Fatalf("Expected side %d to owe a decision", side)
+	}
+	action := agent.Choose(battle.NewView(b, side, req))
+	if err := req.Allows(action); err != nil {
+		t.Fatalf("Agent %T chose an illegal action: %v", agent, err)
+	}
+	return action
+}
+
+func TestExpectedDamageMatchesAverageRoll(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "tackle")
+	snorlax := newBattler(t, "snorlax", "")
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{machamp})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{snorlax})
+
+	expected := battle.ExpectedDamage(machamp, snorlax, machamp.Moves[0], field)
+	const rolls = 4000
+	total := 0
+	for range rolls {
+		dmg, _, _ := battle.DamageCalc(machamp, snorlax, machamp.Moves[0], field)
+		total += dmg
+	}
+	if mean := float64(total) / rolls; math.Abs(mean-expected) > expected*0.03 {
+		t.Errorf("Expected an average close to %.1f, got %.1f", expected, mean)
+	}
+
+	gengar := newBattler(t, "gengar", "levitate")
+	golem := newBattler(t, "golem", "", "earthquake")
+	if dmg := battle.ExpectedDamage(golem, gengar, golem.Moves[0], nil); dmg != 0 {
+		t.Errorf("Expected Levitate to leave nothing to expect, got %.1f", dmg)
+	}
+}
+
+func TestGreedyPicksTheMostDamagingMove(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "tackle", "close-combat")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{newBattler(t, "snorlax", "", "tackle")})
+	b.Start()
+
+	if action := choose(t, battle.GreedyAgent{}, b, 0); action != battle.MoveAction(1) {
+		t.Errorf("Expected Close Combat, got %+v", action)
+	}
+}
+
+func TestHeuristicAvoidsImmuneTargets(t *testing.T) {
+	pikachu := newBattler(t, "pikachu", "", "tackle", "thunder-wave")
+	b := newBattle([]*battle.BattlePokemon{pikachu}, []*battle.BattlePokemon{newBattler(t, "gengar", "", "shadow-ball")})
+	b.Start()
+
+	if action := choose(t, battle.HeuristicAgent{}, b, 0); action != battle.MoveAction(1) {
+		t.Errorf("Expected Thunder Wave on a Ghost type, got %+v", action)
+	}
+}
+
+func TestHeuristicSwitchesOutOfBadMatchups(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	snorlax := newBattler(t, "snorlax", "", "crunch")
+	b := newBattle([]*battle.BattlePokemon{machamp, snorlax}, []*battle.BattlePokemon{newBattler(t, "gengar", "", "shadow-ball")})
+	b.Start()
+	playTurn(t, b, [2]battle.Action{battle.MoveAction(0), battle.MoveAction(0)})
+
+	if action := choose(t, battle.HeuristicAgent{}, b, 0); action != battle.SwitchAction(1) {
+		t.Errorf("Expected a switch to Snorlax, got %+v", action)
+	}
+	if action := choose(t, battle.GreedyAgent{}, b, 0); action != battle.MoveAction(0) {
+		t.Errorf("Expected Greedy to stay in, got %+v", action)
+	}
+}
+
+func TestExpectiminimaxUsesPriorityToFinishFasterFoes(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "thunder", "quick-attack")
+	alakazam := newBattler(t, "alakazam", "", "psychic")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{alakazam})
+	b.Start()
+	playTurn(t, b, [2]battle.Action{battle.MoveAction(1), battle.MoveAction(0)})
+	machamp.CurrentHP, alakazam.CurrentHP = 1, 1
+
+	if action := choose(t, battle.ExpectiminimaxAgent{}, b, 0); action != battle.MoveAction(1) {
+		t.Errorf("Expected Quick Attack to strike before Alakazam, got %+v", action)
+	}
+}
+
+func TestExpectiminimaxModelsStruggle(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "quick-attack")
+	alakazam := newBattler(t, "alakazam", "", "psychic")
+	b := newBattle([]*battle.BattlePokemon{machamp, newBattler(t, "snorlax", "", "body-slam")}, []*battle.BattlePokemon{alakazam})
+	b.Start()
+	playTurn(t, b, [2]battle.Action{battle.MoveAction(0), battle.MoveAction(0)})
+	machamp.MovePP["quick-attack"] = 0
+	machamp.CurrentHP, alakazam.CurrentHP = 1, 1
+
+	// Struggle has none of Quick Attack's priority, so Alakazam would strike
+	// first.
+	if action := choose(t, battle.ExpectiminimaxAgent{}, b, 0); action != battle.SwitchAction(1) {
+		t.Errorf("Expected a switch to Snorlax rather than a Struggle that comes too late, got %+v", action)
+	}
+}
+
+func TestAgentsPlayBattlesToTheEnd(t *testing.T) {
+	agents := map[string]battle.Agent{
+		"random":         battle.RandomAgent{Rand: battle.NewRand(7)},
+		"greedy":         battle.GreedyAgent{},
+		"heuristic":      battle.HeuristicAgent{},
+		"expectiminimax": battle.ExpectiminimaxAgent{},
+	}
+	team := func() []*battle.BattlePokemon {
+		return []*battle.BattlePokemon{
+			newBattler(t, "machamp", "", "close-combat", "rock-slide", "bulk-up"),
+			newBattler(t, "gengar", "", "shadow-ball", "sludge-bomb", "will-o-wisp"),
+			newBattler(t, "snorlax", "", "body-slam", "crunch", "rest"),
+		}
+	}
+	for name, agent := range agents {
+		t.Run(name, func(t *testing.T) {
+			b := newBattle(team(), team())
+			b.Start()
+			for step := 0; !b.Over(); step++ {
+				if step == 500 {
+					t.Fatal("Expected the battle to end within 500 steps")
+				}
+				for side := range 2 {
+					if b.RequestsFor(side) != nil {
+						if err := b.Submit(side, choose(t, agent, b, side)); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+				if _, _, err := b.Step(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestViewsHideTheFoesUnrevealedSet(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "rock-slide")
+	gengar := holding(t, newBattler(t, "gengar", "levitate", "shadow-ball", "sludge-bomb"), "leftovers")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{gengar})
+	b.Start()
+
+	v := battle.NewView(b, 0, b.RequestsFor(0))
+	if foe := v.Foe(); len(foe.Moves) != 0 || foe.Item != "" || foe.Ability != "" {
+		t.Errorf("Expected nothing of Gengar's set to show yet, got %d moves, %q and %q", len(foe.Moves), foe.Item, foe.Ability)
+	}
+	if v.Self().Moves[0] != machamp.Moves[0] {
+		t.Error("Expected the side to see its own moves")
+	}
+
+	playTurn(t, b, [2]battle.Action{battle.MoveAction(0), battle.MoveAction(0)})
+	v = battle.NewView(b, 0, b.RequestsFor(0))
+	foe := v.Foe()
+	if len(foe.Moves) != 1 || foe.Moves[0].Name != "shadow-ball" {
+		t.Errorf("Expected only Shadow Ball to be revealed, got %v", foe.Moves)
+	}
+	if foe.Item != "leftovers" || foe.Ability != "" {
+		t.Errorf("Expected Leftovers to have shown itself and Levitate not, got %q and %q", foe.Item, foe.Ability)
+	}
+
+	foe.CurrentHP = 0
+	if gengar.CurrentHP == 0 || len(gengar.Moves) != 2 {
+		t.Error("Expected changing the view to leave the battle alone")
+	}
+}
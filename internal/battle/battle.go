@@ -0,0 +1,416 @@
+package battle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// ErrInvalidAction is returned by Submit for an action the side's request
+// does not allow.
+var ErrInvalidAction = errors.New("invalid action")
+
+// ErrAwaitingAction is returned by Step while a side still owes a decision.
+var ErrAwaitingAction = errors.New("awaiting action")
+
+// ActionKind says what a side does with its decision.
+type ActionKind int
+
+const (
+	ActionMove ActionKind = iota
+	ActionSwitch
+)
+
+// Action is one side's decision. Move indexes the active Pokémon's move
+// options and Switch the squad member to send in; only the one matching
+// Kind is read.
+type Action struct {
+	Kind   ActionKind
+	Move   int
+	Switch int
+}
+
+// MoveAction picks the move option at index i.
+func MoveAction(i int) Action {
+	return Action{Kind: ActionMove, Move: i}
+}
+
+// SwitchAction sends in squad member i.
+func SwitchAction(i int) Action {
+	return Action{Kind: ActionSwitch, Switch: i}
+}
+
+// MoveOption is one of the moves a side may pick this turn.
+type MoveOption struct {
+	Name  string
+	PP    int
+	MaxPP int
+	// Disabled explains why the move cannot be picked, such as a Choice
+	// lock, or is "" if nothing stops it.
+	Disabled string
+	// Forced marks the only move the Pokémon may use, because it is locked
+	// into a multi-turn move or must Struggle. It needs no PP.
+	Forced bool
+}
+
+// Usable reports whether the move can be picked.
+func (m MoveOption) Usable() bool {
+	return m.Disabled == "" && (m.PP > 0 || m.Forced)
+}
+
+// Request is a decision the battle is waiting on from one side.
+type Request struct {
+	Side int
+	Turn int
+	// ForceSwitch is set when the side's active Pokémon has fainted: the
+	// side must send in a replacement and can do nothing else.
+	ForceSwitch bool
+	// CanSwitch is false while the active Pokémon is locked into its move.
+	CanSwitch bool
+	// Moves lists the active Pokémon's moves in moveset order, or Struggle
+	// alone once it has nothing else. It is empty on a forced switch.
+	Moves []MoveOption
+	// Switches lists the squad members that can be sent in.
+	Switches []int
+}
+
+// Allows reports why action is not a legal answer to r, or nil if it is.
+func (r *Request) Allows(action Action) error {
+	switch action.Kind {
+	case ActionSwitch:
+		if !r.ForceSwitch && !r.CanSwitch {
+			return fmt.Errorf("%w: the active Pokémon can't be switched out", ErrInvalidAction)
+		}
+		if !slices.Contains(r.Switches, action.Switch) {
+			return fmt.Errorf("%w: squad member %d can't be sent in", ErrInvalidAction, action.Switch)
+		}
+	case ActionMove:
+		if r.ForceSwitch {
+			return fmt.Errorf("%w: a replacement must be sent in", ErrInvalidAction)
+		}
+		if action.Move < 0 || action.Move >= len(r.Moves) {
+			return fmt.Errorf("%w: no move option %d", ErrInvalidAction, action.Move)
+		}
+		if move := r.Moves[action.Move]; !move.Usable() {
+			reason := move.Disabled
+			if reason == "" {
+				reason = "no PP left"
+			}
+			return fmt.Errorf("%w: %s can't be used: %s", ErrInvalidAction, move.Name, reason)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action kind %d", ErrInvalidAction, action.Kind)
+	}
+	return nil
+}
+
+// DefaultAction is the first legal answer to r: the first usable move, or
+// the first Pokémon that can be sent in.
+func (r *Request) DefaultAction() Action {
+	for i, move := range r.Moves {
+		if move.Usable() {
+			return MoveAction(i)
+		}
+	}
+	if len(r.Switches) > 0 {
+		return SwitchAction(r.Switches[0])
+	}
+	return MoveAction(0)
+}
+
+// Battle is the authoritative state of a two-sided battle: both squads on
+// the field, which member of each is active and the turn counter. Sides
+// answer its requests with Submit and Step plays out the result.
+type Battle struct {
+	Field *Field
+	// Turn is the number of the turn being decided, from 1.
+	Turn int
+	// Active holds the squad index of each side's active Pokémon.
+	Active  [2]int
+	actions [2]*Action
+	// revealed maps each side's squad members to what the other side has
+	// seen of their sets.
+	revealed [2]map[int]*revealedSet
+}
+
+// revealedSet is what has been seen of a squad member's set: the moves it
+// chose, in the order they were first used, and whether its ability and
+// item have shown themselves.
+type revealedSet struct {
+	moves   []string
+	ability bool
+	item    bool
+}
+
+// NewBattle seats teams on field under the given side names, such as
+// "your team", with leads as their first active members.
+func NewBattle(field *Field, names [2]string, teams [2][]*BattlePokemon, leads [2]int) *Battle {
+	for i := range teams {
+		field.Join(i, names[i], teams[i])
+	}
+	return &Battle{Field: field, Turn: 1, Active: leads, revealed: [2]map[int]*revealedSet{{}, {}}}
+}
+
+// Clone returns an independent copy of the battle whose random decisions
+// follow from seed instead, so agents can play out what might happen
+// without touching the real battle.
+func (b *Battle) Clone(seed uint64) *Battle {
+	c := *b
+	c.Field = b.Field.clone(seed)
+	for side, sets := range b.revealed {
+		c.revealed[side] = make(map[int]*revealedSet, len(sets))
+		for member, set := range sets {
+			clone := *set
+			clone.moves = slices.Clone(set.moves)
+			c.revealed[side][member] = &clone
+		}
+	}
+	return &c
+}
+
+// Revealed lists the moves squad member of side has been seen to choose,
+// in the order they were first used. It is all the other side knows of the
+// member's moveset.
+func (b *Battle) Revealed(side, member int) []string {
+	if set := b.revealed[side][member]; set != nil {
+		return set.moves
+	}
+	return nil
+}
+
+// revealedSet returns what has been seen of member of side.
+func (b *Battle) revealedSet(side, member int) *revealedSet {
+	if b.revealed[side] == nil {
+		b.revealed[side] = map[int]*revealedSet{}
+	}
+	set := b.revealed[side][member]
+	if set == nil {
+		set = &revealedSet{}
+		b.revealed[side][member] = set
+	}
+	return set
+}
+
+// Start sends out both leads and returns what happens as they enter.
+func (b *Battle) Start() []Event {
+	events := b.Field.SendOut(nil, b.ActivePokemon(0), b.ActivePokemon(1))
+	return append(events, b.Field.SendOut(nil, b.ActivePokemon(1), b.ActivePokemon(0))...)
+}
+
+// Team returns side's squad.
+func (b *Battle) Team(side int) []*BattlePokemon {
+	return b.Field.Sides[side].Members
+}
+
+// ActivePokemon returns side's Pokémon on the field.
+func (b *Battle) ActivePokemon(side int) *BattlePokemon {
+	return b.Team(side)[b.Active[side]]
+}
+
+// Lost reports whether every member of side's squad has fainted.
+func (b *Battle) Lost(side int) bool {
+	return IsAllFainted(b.Team(side))
+}
+
+// Over reports whether either side has lost.
+func (b *Battle) Over() bool {
+	return b.Lost(0) || b.Lost(1)
+}
+
+// replacing reports whether side has to replace a fainted active Pokémon.
+func (b *Battle) replacing(side int) bool {
+	return b.ActivePokemon(side).Fainted && !b.Lost(side)
+}
+
+// RequestsFor returns the decision side owes, or nil if it has none: the
+// battle is over or only the other side is replacing a fainted Pokémon.
+func (b *Battle) RequestsFor(side int) *Request {
+	if b.Over() {
+		return nil
+	}
+	forced := b.replacing(side)
+	if !forced && (b.replacing(0) || b.replacing(1)) {
+		return nil
+	}
+	active := b.ActivePokemon(side)
+	req := &Request{Side: side, Turn: b.Turn, ForceSwitch: forced, CanSwitch: forced || !active.LockedIn()}
+	for i, member := range b.Team(side) {
+		if member != nil && !member.Fainted && i != b.Active[side] {
+			req.Switches = append(req.Switches, i)
+		}
+	}
+	if len(req.Switches) == 0 {
+		req.CanSwitch = false
+	}
+	if !forced {
+		req.Moves = moveOptions(active)
+	}
+	return req
+}
+
+func moveOptions(bp *BattlePokemon) []MoveOption {
+	if bp.MustStruggle() {
+		return []MoveOption{{Name: Struggle.Name, Forced: true}}
+	}
+	options := make([]MoveOption, 0, len(bp.Moves))
+	for _, move := range bp.Moves {
+		option := MoveOption{
+			Name:   move.Name,
+			PP:     bp.MovePP[move.Name],
+			MaxPP:  move.Pp,
+			Forced: bp.LockedIn() && bp.ForcedMove == move.Name,
+		}
+		if locked := bp.LockedMove(); locked != "" && locked != move.Name {
+			option.Disabled = "locked into " + locked
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// Submit records side's answer to its current request, replacing any
+// earlier answer this turn.
+func (b *Battle) Submit(side int, action Action) error {
+	req := b.RequestsFor(side)
+	if req == nil {
+		return fmt.Errorf("%w: side %d has nothing to decide", ErrInvalidAction, side)
+	}
+	if err := req.Allows(action); err != nil {
+		return err
+	}
+	b.actions[side] = &action
+	return nil
+}
+
+// Step plays out the submitted actions once every side that owes a
+// decision has made one: the replacement of fainted Pokémon, or else a full
+// turn. It returns what happened and the decisions the battle waits on
+// next, which are all nil once it is over.
+func (b *Battle) Step() ([]Event, [2]*Request, error) {
+	reqs := [2]*Request{b.RequestsFor(0), b.RequestsFor(1)}
+	for side, req := range reqs {
+		if req != nil && b.actions[side] == nil {
+			return nil, reqs, fmt.Errorf("%w from side %d", ErrAwaitingAction, side)
+		}
+	}
+	if reqs[0] == nil && reqs[1] == nil {
+		return nil, reqs, fmt.Errorf("%w: the battle is over", ErrInvalidAction)
+	}
+	actions := b.actions
+	b.actions = [2]*Action{}
+
+	var events []Event
+	if b.replacing(0) || b.replacing(1) {
+		events = b.replace(actions)
+	} else {
+		events = b.playTurn(actions)
+	}
+	b.observe(events)
+	return events, [2]*Request{b.RequestsFor(0), b.RequestsFor(1)}, nil
+}
+
+// replace sends in the chosen replacements for fainted Pokémon. Both come
+// in before either switch-in resolves, so each meets the other's new lead.
+func (b *Battle) replace(actions [2]*Action) []Event {
+	var outgoing [2]*BattlePokemon
+	for side, action := range actions {
+		if action != nil {
+			outgoing[side] = b.ActivePokemon(side)
+			b.Active[side] = action.Switch
+		}
+	}
+	var events []Event
+	for side, action := range actions {
+		if action != nil {
+			events = append(events, b.sendOut(side, outgoing[side])...)
+		}
+	}
+	return events
+}
+
+// playTurn runs a full turn: switches first, then both moves in order, then
+// the end of turn effects.
+func (b *Battle) playTurn(actions [2]*Action) []Event {
+	var events []Event
+	for side, action := range actions {
+		if action.Kind == ActionSwitch {
+			outgoing := b.ActivePokemon(side)
+			b.Active[side] = action.Switch
+			events = append(events, b.sendOut(side, outgoing)...)
+		}
+	}
+	var moves [2]*pokemon.MoveInfo
+	for side, action := range actions {
+		if action.Kind == ActionMove {
+			moves[side] = b.chosenMove(side, action.Move)
+			b.reveal(side, moves[side])
+		}
+	}
+	events = append(events, executeTurn(b.ActivePokemon(0), b.ActivePokemon(1), moves[0], moves[1], b.Field)...)
+	b.Turn++
+	return events
+}
+
+// sendOut announces side's new active Pokémon and runs it through the
+// switch pipeline.
+func (b *Battle) sendOut(side int, outgoing *BattlePokemon) []Event {
+	incoming := b.ActivePokemon(side)
+	events := []Event{Switch{Side: b.Field.Sides[side].Name, Pokemon: incoming.Base.Name}}
+	return append(events, b.Field.SendOut(outgoing, incoming, b.ActivePokemon(1-side))...)
+}
+
+// reveal records that side's active Pokémon chose move.
+func (b *Battle) reveal(side int, move *pokemon.MoveInfo) {
+	set := b.revealedSet(side, b.Active[side])
+	if move != Struggle && !slices.Contains(set.moves, move.Name) {
+		set.moves = append(set.moves, move.Name)
+	}
+}
+
+// observe records the abilities and items events gave away: any effect,
+// cause or source naming an active Pokémon's ability or item, and items
+// knocked off or used up.
+func (b *Battle) observe(events []Event) {
+	for _, e := range events {
+		var name string
+		switch e := e.(type) {
+		case Damage:
+			name = e.Cause
+		case Heal:
+			name = e.Cause
+		case Immune:
+			name = e.Ability
+		case StatusApplied:
+			name = e.Cause
+		case StatusCured:
+			name = e.Cause
+		case WeatherChanged:
+			name = e.Ability
+		case Activate:
+			name = e.Effect
+		case ItemRemoved:
+			name = e.Item
+		}
+		if name == "" {
+			continue
+		}
+		for side := range b.revealed {
+			switch bp := b.ActivePokemon(side); name {
+			case bp.Ability:
+				b.revealedSet(side, b.Active[side]).ability = true
+			case bp.Item, bp.LostItem:
+				b.revealedSet(side, b.Active[side]).item = true
+			}
+		}
+	}
+}
+
+func (b *Battle) chosenMove(side, i int) *pokemon.MoveInfo {
+	active := b.ActivePokemon(side)
+	if active.MustStruggle() {
+		return Struggle
+	}
+	return active.Moves[i]
+}
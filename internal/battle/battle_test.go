@@ -0,0 +1,132 @@
+package battle_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func newBattle(player, enemy []*battle.BattlePokemon) *battle.Battle {
+	field := battle.NewField(battle.Format{}, 1)
+	teams := [2][]*battle.BattlePokemon{player, enemy}
+	return battle.NewBattle(field, [2]string{"your team", "the opposing team"}, teams, [2]int{0, 0})
+}
+
+func TestSubmitRejectsIllegalActions(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat", "tackle")
+	gengar := newBattler(t, "gengar", "")
+	fainted := newBattler(t, "snorlax", "")
+	fainted.Fainted = true
+	b := newBattle([]*battle.BattlePokemon{machamp, gengar, fainted}, []*battle.BattlePokemon{newBattler(t, "snorlax", "", "tackle")})
+	b.Start()
+
+	machamp.MovePP["tackle"] = 0
+	cases := map[string]battle.Action{
+		"move without PP":     battle.MoveAction(1),
+		"missing move":        battle.MoveAction(4),
+		"switch to active":    battle.SwitchAction(0),
+		"switch to a faint":   battle.SwitchAction(2),
+		"switch out of squad": battle.SwitchAction(6),
+	}
+	for name, action := range cases {
+		if err := b.Submit(0, action); !errors.Is(err, battle.ErrInvalidAction) {
+			t.Errorf("Expected %s to be rejected, got %v", name, err)
+		}
+	}
+	if err := b.Submit(0, battle.SwitchAction(1)); err != nil {
+		t.Errorf("Expected switching to Gengar to be allowed, got %v", err)
+	}
+}
+
+func TestStepWaitsForBothSides(t *testing.T) {
+	b := newBattle([]*battle.BattlePokemon{newBattler(t, "machamp", "", "tackle")}, []*battle.BattlePokemon{newBattler(t, "snorlax", "", "tackle")})
+	b.Start()
+
+	if err := b.Submit(0, battle.MoveAction(0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := b.Step(); !errors.Is(err, battle.ErrAwaitingAction) {
+		t.Fatalf("Expected Step to wait for the enemy, got %v", err)
+	}
+	if err := b.Submit(1, battle.MoveAction(0)); err != nil {
+		t.Fatal(err)
+	}
+	events, reqs, err := b.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Turn != 2 || !hasEvent(events, "used tackle") {
+		t.Errorf("Expected a full turn to be played, now on turn %d: %v", b.Turn, events)
+	}
+	if reqs[0] == nil || reqs[1] == nil || reqs[0].Turn != 2 {
+		t.Errorf("Expected both sides to be asked for turn 2, got %+v", reqs)
+	}
+}
+
+func TestFaintedPokemonMustBeReplaced(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	snorlax := newBattler(t, "snorlax", "", "tackle")
+	gengar := newBattler(t, "gengar", "", "shadow-ball")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{snorlax, gengar})
+	b.Start()
+
+	snorlax.CurrentHP = 1
+	b.Submit(0, battle.MoveAction(0))
+	b.Submit(1, battle.MoveAction(0))
+	_, reqs, err := b.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snorlax.Fainted {
+		t.Fatal("Expected Close Combat to knock out Snorlax")
+	}
+	if reqs[0] != nil || reqs[1] == nil || !reqs[1].ForceSwitch {
+		t.Fatalf("Expected only the opposing side to owe a replacement, got %+v", reqs)
+	}
+	if err := b.Submit(1, battle.MoveAction(0)); !errors.Is(err, battle.ErrInvalidAction) {
+		t.Errorf("Expected a move to be rejected during a forced switch, got %v", err)
+	}
+
+	b.Submit(1, battle.SwitchAction(1))
+	events, reqs, err := b.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ActivePokemon(1) != gengar || !hasEvent(events, "The opposing team sent out") {
+		t.Errorf("Expected Gengar to be sent out, got %v", events)
+	}
+	if b.Turn != 2 || reqs[0] == nil || reqs[1] == nil {
+		t.Errorf("Expected turn 2 to be decided next, got turn %d and %+v", b.Turn, reqs)
+	}
+}
+
+func TestMovesAtASwitchInThatFaintedToHazardsAreSkipped(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "tackle")
+	charizard := newBattler(t, "charizard", "", "ember")
+	charizard.CurrentHP = 1
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	b := newBattle([]*battle.BattlePokemon{snorlax, charizard}, []*battle.BattlePokemon{machamp})
+	b.Start()
+	b.Field.Sides[0].Hazards["stealth-rock"] = 1
+
+	b.Submit(0, battle.SwitchAction(1))
+	b.Submit(1, battle.MoveAction(0))
+	events, _, err := b.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !charizard.Fainted {
+		t.Fatalf("Expected Charizard to faint to Stealth Rock, got %v", events)
+	}
+	for _, e := range events {
+		switch e := e.(type) {
+		case battle.MoveUsed:
+			t.Errorf("Expected Machamp to hold its move for a fainted target, got %+v", e)
+		case battle.Damage:
+			if e.Cause != "stealth-rock" {
+				t.Errorf("Expected only Stealth Rock to hurt Charizard, got %+v", e)
+			}
+		}
+	}
+}
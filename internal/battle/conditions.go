@@ -0,0 +1,244 @@
+package battle
+
+import (
+	"sort"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// Side is one player's half of the field and the conditions, such as
+// Reflect, that protect it.
+type Side struct {
+	Name    string
+	Members []*BattlePokemon
+	// Conditions maps each active side condition to the turns it has left.
+	Conditions map[string]int
+	// Hazards maps each entry hazard on this side to its layers.
+	Hazards map[string]int
+}
+
+// condition describes a move that sets up a side or field condition.
+type condition struct {
+	name    string
+	turns   int
+	side    bool
+	terrain bool
+	// toggles ends the condition when the move is used while it is active.
+	toggles bool
+	// moveType is the type of move a terrain boosts.
+	moveType string
+}
+
+var conditionMoves = map[string]condition{
+	"reflect":          {name: "reflect", turns: 5, side: true},
+	"light-screen":     {name: "light-screen", turns: 5, side: true},
+	"tailwind":         {name: "tailwind", turns: 4, side: true},
+	"trick-room":       {name: "trick-room", turns: 5, toggles: true},
+	"electric-terrain": {name: "electric-terrain", turns: 5, terrain: true, moveType: "electric"},
+	"grassy-terrain":   {name: "grassy-terrain", turns: 5, terrain: true, moveType: "grass"},
+	"misty-terrain":    {name: "misty-terrain", turns: 5, terrain: true},
+	"psychic-terrain":  {name: "psychic-terrain", turns: 5, terrain: true, moveType: "psychic"},
+}
+
+// Join seats squad on side i of the field, where its members draw from the
+// field's generator. name describes the side in messages, such as "your
+// team".
+func (f *Field) Join(i int, name string, squad []*BattlePokemon) {
+	f.Sides[i] = &Side{Name: name, Members: squad, Conditions: make(map[string]int), Hazards: make(map[string]int)}
+	for _, bp := range squad {
+		if bp != nil {
+			bp.rand, bp.side = f.rand, name
+		}
+	}
+}
+
+// SideOf returns the side bp battles on, or nil if it has not joined one.
+func (f *Field) SideOf(bp *BattlePokemon) *Side {
+	if f == nil {
+		return nil
+	}
+	for _, side := range f.Sides {
+		if side == nil {
+			continue
+		}
+		for _, member := range side.Members {
+			if member == bp {
+				return side
+			}
+		}
+	}
+	return nil
+}
+
+// sideHas reports whether bp's side is protected by the named condition.
+func (f *Field) sideHas(bp *BattlePokemon, name string) bool {
+	side := f.SideOf(bp)
+	return side != nil && side.Conditions[name] > 0
+}
+
+// ActiveConditions lists the field-wide conditions, including terrain, with
+// the turns each has left.
+func (f *Field) ActiveConditions() map[string]int {
+	active := make(map[string]int)
+	if f == nil {
+		return active
+	}
+	for name, turns := range f.Conditions {
+		active[name] = turns
+	}
+	if f.Terrain != "" {
+		active[f.Terrain] = f.TerrainTurns
+	}
+	return active
+}
+
+func (f *Field) trickRoom() bool {
+	return f != nil && f.Conditions["trick-room"] > 0
+}
+
+func (f *Field) terrain() string {
+	if f == nil {
+		return ""
+	}
+	return f.Terrain
+}
+
+// useConditionMove sets up the condition move creates for user's side or
+// the whole field.
+func (f *Field) useConditionMove(user *BattlePokemon, move *pokemon.MoveInfo) []Event {
+	c := conditionMoves[move.Name]
+	if f == nil {
+		return nil
+	}
+	switch {
+	case c.side:
+		side := f.SideOf(user)
+		if side == nil || side.Conditions[c.name] > 0 {
+			return nil
+		}
+		side.Conditions[c.name] = c.turns
+		return []Event{ConditionChanged{Side: side.Name, Condition: c.name}}
+	case c.terrain:
+		if f.Terrain == c.name {
+			return nil
+		}
+		f.Terrain, f.TerrainTurns = c.name, c.turns
+		return []Event{ConditionChanged{Condition: c.name}}
+	default:
+		if f.Conditions == nil {
+			f.Conditions = make(map[string]int)
+		}
+		if f.Conditions[c.name] > 0 && c.toggles {
+			delete(f.Conditions, c.name)
+			return []Event{ConditionChanged{Condition: c.name, Ended: true}}
+		}
+		f.Conditions[c.name] = c.turns
+		return []Event{ConditionChanged{Condition: c.name}}
+	}
+}
+
+// grounded reports whether terrain affects bp.
+func grounded(bp *BattlePokemon) bool {
+	return !hasType(bp, "flying") && bp.Ability != "levitate"
+}
+
+// conditionModifyHit applies screens and terrain to a damaging move. Critical
+// hits ignore screens.
+func conditionModifyHit(field *Field, hit *Hit, crit bool) {
+	if !crit {
+		if hit.Move.DamageClass.Name == "physical" && field.sideHas(hit.Defender, "reflect") ||
+			hit.Move.DamageClass.Name == "special" && field.sideHas(hit.Defender, "light-screen") {
+			hit.Damage *= 0.5
+		}
+	}
+
+	terrain := field.terrain()
+	if terrain == "" {
+		return
+	}
+	if t := conditionMoves[terrain].moveType; t != "" && hit.Move.Type.Name == t && grounded(hit.Attacker) {
+		hit.Power *= 1.3
+	}
+	switch {
+	case terrain == "misty-terrain" && hit.Move.Type.Name == "dragon" && grounded(hit.Defender):
+		hit.Power *= 0.5
+	case terrain == "grassy-terrain" && groundShakers[hit.Move.Name] && grounded(hit.Defender):
+		hit.Power *= 0.5
+	}
+}
+
+var groundShakers = map[string]bool{"earthquake": true, "bulldoze": true, "magnitude": true}
+
+// terrainBlocksStatus reports whether terrain keeps target from gaining
+// status: Misty Terrain prevents every major status and confusion, Electric
+// Terrain prevents sleep.
+func terrainBlocksStatus(field *Field, target *BattlePokemon, status string) bool {
+	if !grounded(target) {
+		return false
+	}
+	switch field.terrain() {
+	case "misty-terrain":
+		return true
+	case "electric-terrain":
+		return status == "slp"
+	}
+	return false
+}
+
+// terrainBlocksPriority reports whether Psychic Terrain shields defender
+// from attacker's priority move.
+func terrainBlocksPriority(field *Field, attacker, defender *BattlePokemon, move *pokemon.MoveInfo) bool {
+	return field.terrain() == "psychic-terrain" && move.Priority > 0 && attacker != defender && grounded(defender)
+}
+
+// conditionsEndOfTurn heals grounded Pokémon on Grassy Terrain and counts
+// every side and field condition down, announcing those that end.
+func (f *Field) conditionsEndOfTurn(actives ...*BattlePokemon) []Event {
+	if f == nil {
+		return nil
+	}
+	var events []Event
+	if f.Terrain == "grassy-terrain" {
+		for _, bp := range actives {
+			if bp == nil || bp.Fainted || !grounded(bp) {
+				continue
+			}
+			events = append(events, bp.restore(bp.MaxHP()/16, "grassy-terrain")...)
+		}
+	}
+
+	for _, side := range f.Sides {
+		if side == nil {
+			continue
+		}
+		for _, name := range countDown(side.Conditions) {
+			events = append(events, ConditionChanged{Side: side.Name, Condition: name, Ended: true})
+		}
+	}
+	for _, name := range countDown(f.Conditions) {
+		events = append(events, ConditionChanged{Condition: name, Ended: true})
+	}
+	if f.Terrain != "" {
+		f.TerrainTurns--
+		if f.TerrainTurns <= 0 {
+			events = append(events, ConditionChanged{Condition: f.Terrain, Ended: true})
+			f.Terrain, f.TerrainTurns = "", 0
+		}
+	}
+	return events
+}
+
+// countDown takes a turn off every condition and returns the names of those
+// that ran out, sorted so end of turn messages come in a stable order.
+func countDown(conditions map[string]int) []string {
+	var ended []string
+	for name := range conditions {
+		conditions[name]--
+		if conditions[name] <= 0 {
+			delete(conditions, name)
+			ended = append(ended, name)
+		}
+	}
+	sort.Strings(ended)
+	return ended
+}
@@ -0,0 +1,104 @@
+package battle_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+// twoSides seats a and b on opposite sides of a fresh field.
+func twoSides(a, b *battle.BattlePokemon) *battle.Field {
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{a})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{b})
+	return field
+}
+
+func TestReflectHalvesPhysicalDamageAndWearsOff(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "tackle", "splash")
+	alakazam := newBattler(t, "alakazam", "", "reflect")
+	field := twoSides(machamp, alakazam)
+
+	average := func() float64 {
+		total := 0
+		for range 300 {
+			alakazam.CurrentHP = alakazam.MaxHP()
+			machamp.MovePP["tackle"] = 35
+			for _, e := range battle.Act(machamp, alakazam, machamp.Moves[0], field) {
+				if hit, ok := e.(battle.Damage); ok && !hit.Critical {
+					total += hit.Amount
+				}
+			}
+		}
+		return float64(total)
+	}
+	before := average()
+	battle.Act(alakazam, machamp, alakazam.Moves[0], field)
+	if field.Sides[1].Conditions["reflect"] != 5 {
+		t.Fatalf("Expected Reflect on the opposing side, got %v", field.Sides[1].Conditions)
+	}
+	if ratio := average() / before; ratio < 0.4 || ratio > 0.6 {
+		t.Errorf("Expected Reflect to halve physical damage, got ratio %.2f", ratio)
+	}
+
+	var events []battle.Event
+	for range 5 {
+		events = battle.ExecuteTurn(machamp, alakazam, nil, nil, field)
+	}
+	if !slices.Contains(events, battle.Event(battle.ConditionChanged{Side: "the opposing team", Condition: "reflect", Ended: true})) {
+		t.Errorf("Expected Reflect to wear off after 5 turns, got %v", events)
+	}
+}
+
+func TestTrickRoomAndTailwindChangeTurnOrder(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "tackle")
+	sneasel := newBattler(t, "sneasel", "", "tackle")
+	field := twoSides(snorlax, sneasel)
+
+	field.Conditions["trick-room"] = 5
+	first, _, _, _ := battle.ResolveTurn(snorlax, sneasel, snorlax.Moves[0], sneasel.Moves[0], field)
+	if first != snorlax {
+		t.Errorf("Expected slower Snorlax to move first under Trick Room")
+	}
+
+	delete(field.Conditions, "trick-room")
+	field.Sides[0].Conditions["tailwind"] = 4
+	snorlax.ApplyStatStage("speed", 1)
+	first, _, _, _ = battle.ResolveTurn(snorlax, sneasel, snorlax.Moves[0], sneasel.Moves[0], field)
+	if first != snorlax {
+		t.Errorf("Expected Tailwind to let +1 Snorlax outspeed Sneasel")
+	}
+}
+
+func TestTerrainsAffectGroundedPokemon(t *testing.T) {
+	gengar := newBattler(t, "gengar", "levitate", "hypnosis")
+	snorlax := newBattler(t, "snorlax", "")
+	field := twoSides(gengar, snorlax)
+
+	field.Terrain, field.TerrainTurns = "misty-terrain", 5
+	for range 20 {
+		gengar.MovePP["hypnosis"] = 20
+		battle.Act(gengar, snorlax, gengar.Moves[0], field)
+	}
+	if snorlax.Status != "" {
+		t.Errorf("Misty Terrain failed to protect grounded Snorlax, status %q", snorlax.Status)
+	}
+
+	field.Terrain = "psychic-terrain"
+	sneasel := newBattler(t, "sneasel", "", "quick-attack")
+	events := battle.Act(sneasel, snorlax, sneasel.Moves[0], field)
+	if snorlax.CurrentHP != snorlax.MaxHP() {
+		t.Errorf("Expected Psychic Terrain to block Quick Attack, events %v", events)
+	}
+
+	field.Terrain, field.TerrainTurns = "grassy-terrain", 1
+	snorlax.CurrentHP = snorlax.MaxHP() / 2
+	events = battle.ExecuteTurn(gengar, snorlax, nil, nil, field)
+	if snorlax.CurrentHP <= snorlax.MaxHP()/2 {
+		t.Errorf("Expected Grassy Terrain to heal Snorlax, events %v", events)
+	}
+	if field.Terrain != "" || events[len(events)-1] != (battle.ConditionChanged{Condition: "grassy-terrain", Ended: true}) {
+		t.Errorf("Expected the terrain to end, %d turns left, events %v", field.TerrainTurns, events)
+	}
+}
@@ -1 +1,146 @@
 package battle
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// critChances is the critical hit chance, in percent, for each crit stage.
+var critChances = []float64{6.25, 12.5, 50, 100}
+
+func critChance(stage int) float64 {
+	return critChances[max(0, min(stage, len(critChances)-1))]
+}
+
+// statusCategories are the meta categories of status moves the engine can
+// carry out through applyMoveEffects.
+var statusCategories = map[string]bool{
+	"ailment":        true,
+	"net-good-stats": true,
+	"heal":           true,
+	"swagger":        true,
+}
+
+// MoveSupported reports whether the engine can carry out move. Squads are
+// only dealt moves it accepts.
+func MoveSupported(move *pokemon.MoveInfo) bool {
+	if move.DamageClass.Name != "status" || statusCategories[move.Meta.Category.Name] {
+		return true
+	}
+	_, condition := conditionMoves[move.Name]
+	_, hazard := hazardMoves[move.Name]
+	_, protect := protectMoves[move.Name]
+	return weatherMoves[move.Name] != "" || condition || hazard || protect || move.Name == "substitute"
+}
+
+// applyMoveEffects applies everything in a move's meta block besides its
+// damage: drain and recoil, including Struggle's, healing, ailments,
+// flinching and stat changes, then hazard removal for Rapid Spin and Defog.
+// dealt is the damage the move did, which is 0 for status moves.
+func applyMoveEffects(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, dealt int, field *Field) []Event {
+	var events []Event
+	meta := move.Meta
+	status := move.DamageClass.Name == "status"
+	// A substitute shields the defender from the move's secondary effects.
+	shielded := behindSubstitute(attacker, defender)
+
+	if dealt > 0 && meta.Drain != 0 {
+		events = append(events, drainOrRecoil(attacker, defender, dealt, meta.Drain)...)
+	}
+	if dealt > 0 && move.Name == Struggle.Name {
+		events = append(events, struggleRecoil(attacker)...)
+	}
+
+	if healing := healingPercent(move, field); healing > 0 && !attacker.Fainted {
+		if healed := attacker.restore(attacker.MaxHP()*healing/100, ""); healed != nil {
+			events = append(events, healed...)
+		} else if status {
+			events = append(events, Fail{Pokemon: attacker.Base.Name, Side: attacker.side, Reason: "hp-full"})
+		}
+	}
+
+	if ailment := meta.Ailment.Name; ailment != "" && ailment != "none" && !defender.Fainted && !shielded && roll(attacker.rng(), meta.AilmentChance, status) {
+		events = append(events, inflictAilment(defender, move, ailment, status, field)...)
+	}
+
+	if meta.FlinchChance > 0 && !defender.Fainted && !shielded && roll(attacker.rng(), meta.FlinchChance, false) {
+		defender.setVolatile("flinch", move.Name, 0)
+	}
+
+	if len(move.StatChanges) > 0 && roll(attacker.rng(), meta.StatChance, true) {
+		target := defender
+		if meta.Category.Name == "damage+raise" || move.Target.Name == "user" || move.Target.Name == "user-and-allies" {
+			target = attacker
+		}
+		if !target.Fainted && (target == attacker || !shielded) {
+			for _, change := range move.StatChanges {
+				events = append(events, target.changeStage(change.Stat.Name, change.Change)...)
+			}
+		}
+	}
+	return append(events, hazardClearing(attacker, defender, move, field)...)
+}
+
+// roll succeeds with the given percent chance. A chance of 0 means the effect
+// is the move's main purpose when always is set, and never happens otherwise.
+func roll(r *rand.Rand, chance int, always bool) bool {
+	if chance == 0 {
+		return always
+	}
+	return r.Float64()*100 < float64(chance)
+}
+
+func drainOrRecoil(attacker, defender *BattlePokemon, dealt, drain int) []Event {
+	amount := math.Max(1, math.Floor(float64(dealt)*math.Abs(float64(drain))/100))
+	if attacker.Fainted {
+		return nil
+	}
+	if drain > 0 {
+		events := attacker.restore(amount, "drain")
+		for i := range events {
+			heal := events[i].(Heal)
+			heal.Source = defender.Base.Name
+			events[i] = heal
+		}
+		return events
+	}
+	return attacker.hurt(amount, "recoil")
+}
+
+// inflictAilment applies a move's ailment to target. Status moves that
+// cannot take effect report that they failed; secondary effects fail
+// silently.
+func inflictAilment(target *BattlePokemon, move *pokemon.MoveInfo, ailment string, status bool, field *Field) []Event {
+	failed := func(reason string) []Event {
+		if status {
+			return []Event{Fail{Pokemon: target.Base.Name, Side: target.side, Reason: reason}}
+		}
+		return nil
+	}
+
+	if terrainBlocksStatus(field, target, ailmentStatus[ailment]) {
+		return failed("terrain")
+	}
+
+	if ailment == "confusion" {
+		if target.Volatile.Has("confusion") {
+			return failed("confused")
+		}
+		target.setVolatile("confusion", move.Name, confusionTurns(target))
+		return []Event{StatusApplied{Pokemon: target.Base.Name, Side: target.side, Status: "confusion"}}
+	}
+
+	major, ok := ailmentStatus[ailment]
+	if !ok {
+		return failed("")
+	}
+	if major == "psn" && move.Name == "toxic" {
+		major = "tox"
+	}
+	if target.Status != "" || statusImmune(target, major) {
+		return failed("")
+	}
+	return target.inflictStatus(major, "", "")
+}
@@ -0,0 +1,120 @@
+package battle_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestGigaDrainRestoresHalfTheDamage(t *testing.T) {
+	venusaur := newBattler(t, "venusaur", "", "giga-drain")
+	swampert := newBattler(t, "swampert", "")
+	maxHP := venusaur.MaxHP()
+	venusaur.ApplyDamage(maxHP / 2)
+
+	before := swampert.CurrentHP
+	battle.Act(venusaur, swampert, venusaur.Moves[0], nil)
+	dealt := before - swampert.CurrentHP
+	if dealt == 0 {
+		t.Skip("Giga Drain missed")
+	}
+	if want := min(maxHP, maxHP/2+float64(int(dealt/2))); venusaur.CurrentHP != want {
+		t.Errorf("Expected Giga Drain to restore HP to %.1f after dealing %.1f, got %.1f", want, dealt, venusaur.CurrentHP)
+	}
+}
+
+func TestCloseCombatLowersUsersDefenses(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	snorlax := newBattler(t, "snorlax", "")
+
+	for i := 0; i < 20 && machamp.StatStages["defense"] == 0; i++ {
+		snorlax.CurrentHP = snorlax.MaxHP()
+		battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	}
+	if machamp.StatStages["defense"] != -1 || machamp.StatStages["special-defense"] != -1 {
+		t.Errorf("Expected Close Combat to lower the user's defenses, stages are %v", machamp.StatStages)
+	}
+	if snorlax.StatStages["defense"] != 0 {
+		t.Errorf("Close Combat lowered the target's defense")
+	}
+}
+
+func TestGrowlLowersTargetAttack(t *testing.T) {
+	pikachu := newBattler(t, "pikachu", "", "growl")
+	machamp := newBattler(t, "machamp", "")
+
+	battle.Act(pikachu, machamp, pikachu.Moves[0], nil)
+	if machamp.StatStages["attack"] != -1 {
+		t.Errorf("Expected Growl to lower attack by one stage, got %d", machamp.StatStages["attack"])
+	}
+}
+
+func TestStatusMovesInflictAilments(t *testing.T) {
+	tests := []struct {
+		move, target, want string
+	}{
+		{"thunder-wave", "snorlax", "par"},
+		{"toxic", "snorlax", "tox"},
+		{"thunder-wave", "raichu", ""},
+		{"toxic", "venusaur", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.move+"/"+tt.target, func(t *testing.T) {
+			user := newBattler(t, "chansey", "", tt.move)
+			target := newBattler(t, tt.target, "")
+			for i := 0; i < 20 && target.Status == ""; i++ {
+				user.MovePP[tt.move] = 10
+				battle.Act(user, target, user.Moves[0], nil)
+			}
+			if target.Status != tt.want {
+				t.Errorf("Expected status %q, got %q", tt.want, target.Status)
+			}
+		})
+	}
+}
+
+func TestSelfTargetingStatusMoves(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "swords-dance", "bulk-up")
+	snorlax := newBattler(t, "snorlax", "")
+
+	battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	battle.Act(machamp, snorlax, machamp.Moves[1], nil)
+	if machamp.StatStages["attack"] != 3 || machamp.StatStages["defense"] != 1 {
+		t.Errorf("Expected +3 attack and +1 defense, got %v", machamp.StatStages)
+	}
+	if snorlax.StatStages["attack"] != 0 {
+		t.Errorf("Swords Dance raised the target's attack")
+	}
+
+	chansey := newBattler(t, "chansey", "", "soft-boiled")
+	maxHP := chansey.MaxHP()
+	chansey.ApplyDamage(maxHP * 3 / 4)
+	battle.Act(chansey, snorlax, chansey.Moves[0], nil)
+	if want := maxHP/4 + maxHP/2; chansey.CurrentHP != want {
+		t.Errorf("Expected Soft-Boiled to restore HP to %.1f, got %.1f", want, chansey.CurrentHP)
+	}
+}
+
+func TestMoveSupported(t *testing.T) {
+	tests := map[string]bool{
+		"tackle":       true,
+		"will-o-wisp":  true,
+		"swords-dance": true,
+		"recover":      true,
+		"confuse-ray":  true,
+		"stealth-rock": true,
+		"splash":       false,
+	}
+	src := pokemon.BundledSource()
+	for name, want := range tests {
+		move, err := src.MoveByName(context.Background(), name)
+		if err != nil {
+			t.Fatalf("Failed to fetch %s: %v", name, err)
+		}
+		if got := battle.MoveSupported(move); got != want {
+			t.Errorf("MoveSupported(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
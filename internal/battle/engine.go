@@ -1,152 +1,304 @@
 package battle
 
 import (
-	"fmt"
 	"log"
 	"math"
-	"math/rand/v2"
 
 	"github.com/ross1116/pokebattlecli/internal/pokemon"
-	"github.com/ross1116/pokebattlecli/internal/stats"
 )
 
-func DamageCalc(attacker *BattlePokemon, defender *BattlePokemon, move *pokemon.MoveInfo) (int, float64, []string) {
-	events := []string{}
+func DamageCalc(attacker *BattlePokemon, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) (int, float64, []Event) {
+	hit, events := damageCalc(attacker, defender, move, field, true)
+	return hit.Amount, hit.Percent, events
+}
+
+// damageCalc works out one hit of move, returned as the Damage it would do.
+// The events report why a hit does no damage. Later hits of a multi-hit
+// move skip the accuracy check the first hit already passed.
+func damageCalc(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field, checkAccuracy bool) (Damage, []Event) {
+	events := []Event{}
 	if attacker == nil || defender == nil || move == nil || attacker.Base == nil || defender.Base == nil {
 		log.Println("Error: DamageCalc received nil input.")
-		return 0, 0, events
+		return Damage{}, events
 	}
+	result := Damage{Pokemon: defender.Base.Name, Side: defender.side, Effectiveness: 1}
 
-	level := 100
-	var atkStat, defStat int
-
-	switch move.DamageClass.Name {
-	case "physical":
-		atkStat = int(stats.StatCalc(stats.GetStat(attacker.Base, "attack")))
-		defStat = int(stats.StatCalc(stats.GetStat(defender.Base, "defense")))
-		if attacker.Status == "brn" {
-			atkStat /= 2
-		}
-	case "special":
-		atkStat = int(stats.StatCalc(stats.GetStat(attacker.Base, "special-attack")))
-		defStat = int(stats.StatCalc(stats.GetStat(defender.Base, "special-defense")))
-	default:
+	if !damagingClass(move) {
 		if move.Power > 0 {
 			log.Printf("Unsupported move damage class: %s for move %s", move.DamageClass.Name, move.Name)
 		}
-		return 0, 0, events
-	}
-	if atkStat <= 0 || defStat <= 0 {
-		log.Printf("Stat calculation error for move %s. Atk: %d, Def: %d", move.Name, atkStat, defStat)
-		return 0, 0, events
-	}
-	if move.Power == 0 {
-		return 0, 0, events
+		return result, events
 	}
 
-	acc := float64(move.Accuracy)
-	if acc > 0 {
-		if rand.Float64()*100 > acc {
-			events = append(events, fmt.Sprintf("%s's attack missed!", attacker.Base.Name))
-			return 0, 0, events
-		}
+	crit := attacker.rng().Float64()*100 < critChance(move.Meta.CritRate)
+	if move.Power == 0 && !fixedDamageMove(move) {
+		return result, events
 	}
 
-	stab := 1.0
-	if attacker.Base.Types != nil {
-		for _, t := range attacker.Base.Types {
-			if t.Type.Name == move.Type.Name {
-				stab = 1.5
-				break
-			}
-		}
+	if immune, immuneEvents := abilityImmunity(defender, move); immune {
+		return result, append(events, immuneEvents...)
 	}
 
-	effectiveness := 1.0
-	if defender.Base.Types != nil && pokemon.TypeEffectiveness != nil {
-		for _, t := range defender.Base.Types {
-			if attackerEffectiveness, ok := pokemon.TypeEffectiveness[move.Type.Name]; ok {
-				if mult, ok2 := attackerEffectiveness[t.Type.Name]; ok2 {
-					effectiveness *= mult
-				}
-			}
-		}
+	if checkAccuracy && !accuracyHits(attacker, defender, move, field) {
+		return result, append(events, Miss{Pokemon: attacker.Base.Name, Side: attacker.side})
 	}
 
-	if effectiveness > 1.0 {
-		events = append(events, "It's super effective!")
-	} else if effectiveness < 1.0 && effectiveness > 0 {
-		events = append(events, "It's not very effective...")
-	} else if effectiveness == 0 {
-		events = append(events, fmt.Sprintf("It doesn't affect %s!", defender.Base.Name))
-		return 0, 0, events
+	effectiveness := moveEffectiveness(move, defender, field)
+	if effectiveness == 0 {
+		return result, append(events, Immune{Pokemon: defender.Base.Name, Side: defender.side})
 	}
+	if fixedDamageMove(move) {
+		dmg := fixedDamage(attacker, defender, move)
+		if dmg <= 0 {
+			return result, append(events, Fail{Pokemon: attacker.Base.Name, Side: attacker.side})
+		}
+		result.Amount, result.Percent, result.OHKO = dmg, percentOfMaxHP(defender, dmg), ohko(move)
+		return result, events
+	}
+	result.Effectiveness = effectiveness
 
-	baseDmg := (((2.0 * float64(level) / 5.0) + 2.0) * float64(move.Power) * float64(atkStat) / float64(defStat)) / 50.0
-	if baseDmg < 1.0 && effectiveness > 0 {
-		baseDmg = 1.0
-	} else {
-		baseDmg += 2.0
+	scaled, multiplier := hitDamage(attacker, defender, move, field, crit, effectiveness)
+	if scaled == 0 {
+		return result, events
 	}
 
-	randomFactor := 0.85 + (rand.Float64() * 0.15)
+	randomFactor := 0.85 + (attacker.rng().Float64() * 0.15)
 
-	critChance := 6.25
-	critRoll := rand.Float64() * 100
 	critMultiplier := 1.0
-	if critRoll < critChance {
-		events = append(events, "Critical hit!")
+	if crit {
+		result.Critical = true
 		critMultiplier = 1.5
 	}
 
-	finalDmg := baseDmg * stab * effectiveness * randomFactor * critMultiplier
+	finalDmg := scaled * randomFactor * critMultiplier * multiplier
 
 	roundedDmg := int(math.Floor(finalDmg))
 	if roundedDmg < 1 && effectiveness > 0 {
 		roundedDmg = 1
 	}
-	percent := 0.0
-	baseHp := stats.GetStat(defender.Base, "hp")
-	if baseHp > 0 {
-		totalHp := stats.HpCalc(baseHp)
-		if totalHp > 0 {
-			percent = (float64(roundedDmg) / totalHp) * 100.0
-		}
+	result.Amount, result.Percent = roundedDmg, percentOfMaxHP(defender, roundedDmg)
+	return result, events
+}
+
+// ExpectedDamage is the damage move can be expected to do to defender,
+// averaged over its accuracy, critical hits, the random roll and the number
+// of hits, and capped at defender's HP. It works it out with DamageCalc's
+// formula but draws nothing from the battle's generator, so agents can
+// weigh moves without changing the battle. Status moves expect none.
+func ExpectedDamage(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) float64 {
+	if attacker == nil || defender == nil || move == nil || attacker.Base == nil || defender.Base == nil || defender.Fainted {
+		return 0
+	}
+	effectiveness := damageEffectiveness(defender, move, field)
+	if effectiveness == 0 {
+		return 0
 	}
 
-	return roundedDmg, percent, events
+	damage, chance := expectedHit(attacker, defender, move, field, effectiveness)
+	return min(chance*damage, defender.CurrentHP)
 }
 
-func ExecuteBattleTurn(player *BattlePokemon, enemy *BattlePokemon, playerMove *pokemon.MoveInfo, enemyMove *pokemon.MoveInfo) []string {
-	turnEvents := []string{}
-	first, second, firstMove, secondMove := ResolveTurn(player, enemy, playerMove, enemyMove)
+// damageEffectiveness is the type multiplier of move against defender, or 0
+// when the move cannot damage it at all.
+func damageEffectiveness(defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) float64 {
+	if !damagingClass(move) || move.Power == 0 && !fixedDamageMove(move) || abilityBlocks(defender, move) {
+		return 0
+	}
+	return moveEffectiveness(move, defender, field)
+}
 
-	if first != nil && firstMove != nil {
-		var moveEvents []string
-		if first == player {
-			moveEvents = ProcessPlayerTurn(first, second, firstMove)
-		} else {
-			moveEvents = ProcessEnemyTurn(second, first, firstMove)
-		}
-		turnEvents = append(turnEvents, moveEvents...)
-		if second.Fainted {
-			goto EndTurnEffects
+// expectedHit splits what ExpectedDamage works out into the damage move
+// does on average when it hits and the chance that it hits, before any cap.
+func expectedHit(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field, effectiveness float64) (float64, float64) {
+	var perHit float64
+	switch {
+	case move.Name == "psywave":
+		perHit = float64(attacker.level())
+	case fixedDamageMove(move):
+		perHit = float64(fixedDamage(attacker, defender, move))
+	default:
+		crit := critChance(move.Meta.CritRate) / 100
+		normal, multiplier := hitDamage(attacker, defender, move, field, false, effectiveness)
+		critical, critMultiplier := hitDamage(attacker, defender, move, field, true, effectiveness)
+		perHit = 0.925 * ((1-crit)*normal*multiplier + crit*critical*1.5*critMultiplier)
+	}
+	return perHit * expectedHits(move), hitChance(attacker, defender, move, field)
+}
+
+func damagingClass(move *pokemon.MoveInfo) bool {
+	return move.DamageClass.Name == "physical" || move.DamageClass.Name == "special"
+}
+
+// moveEffectiveness is the type multiplier of move against defender, with
+// Sheer Cold failing on Ice types.
+func moveEffectiveness(move *pokemon.MoveInfo, defender *BattlePokemon, field *Field) float64 {
+	if move.Name == "sheer-cold" && hasType(defender, "ice") {
+		return 0
+	}
+	return effectivenessCheck(move, defender, field)
+}
+
+// hitDamage works out a hit of move before the random roll and critical
+// multiplier: the base formula scaled by STAB and effectiveness, and the
+// multiplier the hooks, screens and terrain put on the final damage. It
+// draws nothing from the battle's generator, and returns 0 when the stats
+// do not allow a hit.
+func hitDamage(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field, crit bool, effectiveness float64) (float64, float64) {
+	atkName, defName := "attack", "defense"
+	if move.DamageClass.Name == "special" {
+		atkName, defName = "special-attack", "special-defense"
+	}
+
+	hit := &Hit{Attacker: attacker, Defender: defender, Move: move, Power: 1, Attack: 1, Defense: 1, Damage: 1}
+	if hook := attacker.ability().ModifyHit; hook != nil {
+		hook(attacker, hit)
+	}
+	if hook := defender.ability().ModifyHit; hook != nil {
+		hook(defender, hit)
+	}
+	if hook := attacker.item().ModifyHit; hook != nil {
+		hook(attacker, hit)
+	}
+	if hook := defender.item().ModifyHit; hook != nil {
+		hook(defender, hit)
+	}
+	if move.Name == "knock-off" && defender.Item != "" {
+		hit.Power *= 1.5
+	}
+	weatherModifyHit(field, hit)
+	hiddenModifyHit(hit)
+
+	atkStage, defStage := attacker.StatStages[atkName], defender.StatStages[defName]
+	if crit {
+		// Critical hits ignore the attacker's drops and the defender's boosts.
+		atkStage, defStage = max(atkStage, 0), min(defStage, 0)
+	}
+	conditionModifyHit(field, hit, crit)
+	atkStat := int(attacker.stat(atkName) * stageMultiplier(atkStage) * hit.Attack)
+	defStat := int(defender.stat(defName) * stageMultiplier(defStage) * hit.Defense)
+	if atkName == "attack" && attacker.Status == "brn" && !hit.IgnoreBurn {
+		atkStat /= 2
+	}
+	if atkStat <= 0 || defStat <= 0 {
+		log.Printf("Stat calculation error for move %s. Atk: %d, Def: %d", move.Name, atkStat, defStat)
+		return 0, 0
+	}
+
+	level := attacker.level()
+	baseDmg := (((2.0 * float64(level) / 5.0) + 2.0) * float64(move.Power) * hit.Power * float64(atkStat) / float64(defStat)) / 50.0
+	if baseDmg < 1.0 && effectiveness > 0 {
+		baseDmg = 1.0
+	} else {
+		baseDmg += 2.0
+	}
+
+	stab := 1.0
+	if attacker.Base.Types != nil {
+		for _, t := range attacker.Base.Types {
+			if t.Type.Name == move.Type.Name {
+				stab = 1.5
+				break
+			}
 		}
-	} else if first != nil && !first.Fainted {
 	}
+	return baseDmg * stab * effectiveness, hit.Damage
+}
+
+func percentOfMaxHP(bp *BattlePokemon, dmg int) float64 {
+	if totalHp := bp.MaxHP(); totalHp > 0 {
+		return (float64(dmg) / totalHp) * 100.0
+	}
+	return 0
+}
+
+// stageMultiplier converts a stat stage in [-6, 6] into the multiplier the
+// games apply to attack, defense, special attack, special defense and speed.
+func stageMultiplier(stage int) float64 {
+	if stage >= 0 {
+		return float64(2+stage) / 2
+	}
+	return 2 / float64(2-stage)
+}
+
+// accuracyMultiplier converts the difference between the user's accuracy
+// stage and the target's evasion stage into a multiplier on move accuracy.
+func accuracyMultiplier(stage int) float64 {
+	stage = max(-6, min(6, stage))
+	if stage >= 0 {
+		return float64(3+stage) / 3
+	}
+	return 3 / float64(3-stage)
+}
+
+// accuracyHits rolls move's accuracy for attacker against defender. Moves
+// without an accuracy never miss.
+func accuracyHits(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) bool {
+	if ohko(move) {
+		return ohkoHits(attacker, defender, move)
+	}
+	accuracy := weatherAccuracy(move, field)
+	if accuracy <= 0 {
+		return true
+	}
+	stage := attacker.StatStages["accuracy"] - defender.StatStages["evasion"]
+	return attacker.rng().Float64()*100 < float64(accuracy)*accuracyMultiplier(stage)
+}
 
-	if second != nil && secondMove != nil && !second.Fainted {
-		var moveEvents []string
-		if second == player {
-			moveEvents = ProcessPlayerTurn(second, first, secondMove)
-		} else {
-			moveEvents = ProcessEnemyTurn(first, second, secondMove)
+// hitChance is the chance, from 0 to 1, that accuracyHits lets move hit.
+func hitChance(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) float64 {
+	if ohko(move) {
+		if diff := attacker.level() - defender.level(); diff >= 0 {
+			return min(1, float64(move.Accuracy+diff)/100)
 		}
-		turnEvents = append(turnEvents, moveEvents...)
-	} else if second != nil && !second.Fainted {
+		return 0
 	}
+	accuracy := weatherAccuracy(move, field)
+	if accuracy <= 0 {
+		return 1
+	}
+	stage := attacker.StatStages["accuracy"] - defender.StatStages["evasion"]
+	return min(1, float64(accuracy)*accuracyMultiplier(stage)/100)
+}
+
+// takeHit applies a hit of move to defender, running its ability hooks
+// around the HP loss and the attacker's item afterwards. It returns the
+// damage actually dealt and the events for the hit, the faint it causes and
+// what the hooks did.
+func takeHit(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, hit Damage) (int, []Event) {
+	dmg := hit.Amount
+	var hookEvents []Event
+	if hook := defender.ability().BeforeDamage; hook != nil {
+		var beforeEvents []Event
+		dmg, beforeEvents = hook(defender, attacker, move, dmg)
+		hookEvents = append(hookEvents, beforeEvents...)
+	}
+	dmg, endureEvents := endure(defender, dmg)
+	hookEvents = append(hookEvents, endureEvents...)
+	defender.ApplyDamage(float64(dmg))
+	recordHit(defender, move, dmg, false)
+	hit.Percent *= float64(dmg) / float64(hit.Amount)
+	hit.Amount = dmg
+	events := faintCheck(defender, []Event{hit})
+	events = append(events, hookEvents...)
+	if hook := defender.ability().AfterDamage; hook != nil {
+		events = append(events, hook(defender, attacker, move, dmg)...)
+	}
+	events = append(events, knockOff(attacker, defender, move)...)
+	if hook := attacker.item().AfterAttack; hook != nil {
+		events = append(events, hook(attacker, defender, move, dmg)...)
+	}
+	return dmg, events
+}
+
+// executeTurn plays one turn between the active Pokémon: both moves in
+// priority and speed order, then the end of turn effects.
+func executeTurn(player *BattlePokemon, enemy *BattlePokemon, playerMove *pokemon.MoveInfo, enemyMove *pokemon.MoveInfo, field *Field) []Event {
+	turnEvents := []Event{}
+	first, second, firstMove, secondMove := ResolveTurn(player, enemy, playerMove, enemyMove, field)
+	turnEvents = append(turnEvents, processAction(first, second, firstMove, field)...)
+	turnEvents = append(turnEvents, processAction(second, first, secondMove, field)...)
 
-EndTurnEffects:
+	turnEvents = append(turnEvents, field.weatherEndOfTurn(first, second)...)
 	if first != nil && !first.Fainted {
 		effectEvents := first.HandleTurnEffects()
 		turnEvents = append(turnEvents, effectEvents...)
@@ -157,6 +309,7 @@ EndTurnEffects:
 			turnEvents = append(turnEvents, effectEvents...)
 		}
 	}
+	turnEvents = append(turnEvents, field.conditionsEndOfTurn(first, second)...)
 
 	return turnEvents
 }
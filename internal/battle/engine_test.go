@@ -1,29 +1,32 @@
 package battle_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ross1116/pokebattlecli/internal/battle"
 	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
-func TestExecuteBattleTurn(t *testing.T) {
-	charmander, err := pokemon.FetchPokemonData("charmander")
+func TestExecuteTurn(t *testing.T) {
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+	charmander, err := src.Pokemon(ctx, "charmander")
 	if err != nil {
 		t.Fatalf("Failed to fetch Charmander: %v", err)
 	}
 
-	squirtle, err := pokemon.FetchPokemonData("squirtle")
+	squirtle, err := src.Pokemon(ctx, "squirtle")
 	if err != nil {
 		t.Fatalf("Failed to fetch Squirtle: %v", err)
 	}
 
-	ember, err := pokemon.FetchMoveByName("ember")
+	ember, err := src.MoveByName(ctx, "ember")
 	if err != nil {
 		t.Fatalf("Failed to fetch move Ember: %v", err)
 	}
 
-	tackle, err := pokemon.FetchMoveByName("tackle")
+	tackle, err := src.MoveByName(ctx, "tackle")
 	if err != nil {
 		t.Fatalf("Failed to fetch move Tackle: %v", err)
 	}
@@ -39,7 +42,10 @@ func TestExecuteBattleTurn(t *testing.T) {
 		MovePP:    map[string]int{"tackle": 10},
 	}
 
-	battle.ExecuteBattleTurn(charmanderBP, squirtleBP, ember, tackle)
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{charmanderBP})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{squirtleBP})
+	battle.ExecuteTurn(charmanderBP, squirtleBP, ember, tackle, field)
 
 	if charmanderBP.Fainted {
 		t.Logf("Charmander fainted.")
@@ -55,22 +61,24 @@ func TestExecuteBattleTurn(t *testing.T) {
 }
 
 func TestPriorityOverridesSpeed(t *testing.T) {
-	sneasel, err := pokemon.FetchPokemonData("sneasel") // faster
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+	sneasel, err := src.Pokemon(ctx, "sneasel") // faster
 	if err != nil {
 		t.Fatalf("Failed to fetch Sneasel: %v", err)
 	}
 
-	slowbro, err := pokemon.FetchPokemonData("slowbro") //slow
+	slowbro, err := src.Pokemon(ctx, "slowbro") //slow
 	if err != nil {
 		t.Fatalf("Failed to fetch Slowbro: %v", err)
 	}
 
-	tackle, err := pokemon.FetchMoveByName("tackle") // priority 0
+	tackle, err := src.MoveByName(ctx, "tackle") // priority 0
 	if err != nil {
 		t.Fatalf("Failed to fetch Tackle: %v", err)
 	}
 
-	quickAttack, err := pokemon.FetchMoveByName("quick-attack") // priority +1
+	quickAttack, err := src.MoveByName(ctx, "quick-attack") // priority +1
 	if err != nil {
 		t.Fatalf("Failed to fetch Quick Attack: %v", err)
 	}
@@ -86,7 +94,10 @@ func TestPriorityOverridesSpeed(t *testing.T) {
 		MovePP:    map[string]int{"quick-attack": 10},
 	}
 
-	battle.ExecuteBattleTurn(slowbroBP, sneaselBP, quickAttack, tackle)
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{slowbroBP})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{sneaselBP})
+	battle.ExecuteTurn(slowbroBP, sneaselBP, quickAttack, tackle, field)
 
 	t.Logf("Post-turn HP: Slowbro: %.1f | Sneasel: %.1f", slowbroBP.CurrentHP, sneaselBP.CurrentHP)
 }
@@ -0,0 +1,275 @@
+package battle
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Event is one thing that happened in a battle. Every kind is a struct of
+// plain values, so a client can render, translate, animate or record it;
+// Render turns events into the text the terminal shows. Pokémon are named
+// by species and the name of the side they battle on.
+type Event interface {
+	// Kind names the event on the wire, such as "damage".
+	Kind() string
+}
+
+// MoveUsed is a Pokémon using a move.
+type MoveUsed struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Move    string `json:"move"`
+}
+
+// Damage is HP a Pokémon lost. Cause is "" for the hit of a move and
+// otherwise names what hurt it: "recoil", "confusion", a status such as
+// "brn", a hazard, a weather or an item.
+type Damage struct {
+	Pokemon string  `json:"pokemon"`
+	Side    string  `json:"side,omitempty"`
+	Amount  int     `json:"amount"`
+	Percent float64 `json:"percent"`
+	Cause   string  `json:"cause,omitempty"`
+	// Effectiveness is the type multiplier of a move's hit, 1 for neutral.
+	Effectiveness float64 `json:"effectiveness,omitempty"`
+	Critical      bool    `json:"critical,omitempty"`
+	OHKO          bool    `json:"ohko,omitempty"`
+	// Substitute is set when the Pokémon's substitute took the hit.
+	Substitute bool `json:"substitute,omitempty"`
+}
+
+// Heal is HP a Pokémon regained. Cause is "" for a healing move and
+// otherwise names the source, such as "drain", "leftovers" or an ability;
+// Source is the Pokémon drained.
+type Heal struct {
+	Pokemon string  `json:"pokemon"`
+	Side    string  `json:"side,omitempty"`
+	Amount  int     `json:"amount"`
+	Percent float64 `json:"percent"`
+	Cause   string  `json:"cause,omitempty"`
+	Source  string  `json:"source,omitempty"`
+}
+
+// Miss is a Pokémon's move missing its target.
+type Miss struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+}
+
+// Immune is a move that does not affect the Pokémon, because of its type or
+// of Ability if set.
+type Immune struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Ability string `json:"ability,omitempty"`
+}
+
+// Fail is a move that did nothing. Reason is "" for a plain failure or
+// explains it, such as "hp-full" or "terrain".
+type Fail struct {
+	Pokemon string `json:"pokemon,omitempty"`
+	Side    string `json:"side,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Faint is a Pokémon fainting.
+type Faint struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+}
+
+// Switch is a side sending a Pokémon out.
+type Switch struct {
+	Side    string `json:"side"`
+	Pokemon string `json:"pokemon"`
+}
+
+// StatusApplied is a Pokémon gaining a major status or confusion. Cause
+// names what inflicted it if that was not a move, such as "static" or
+// "toxic-spikes"; Source is the Pokémon whose ability did.
+type StatusApplied struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Status  string `json:"status"`
+	Cause   string `json:"cause,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// StatusCured is a Pokémon losing a major status or confusion. Cause names
+// the move or berry that cured it, or is "" when the status ran its course.
+type StatusCured struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Status  string `json:"status"`
+	Cause   string `json:"cause,omitempty"`
+}
+
+// StatChange is a change of a Pokémon's stat stage. Applied is how far the
+// stage actually moved, 0 when it was already at its limit.
+type StatChange struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Stat    string `json:"stat"`
+	Change  int    `json:"change"`
+	Applied int    `json:"applied"`
+}
+
+// The phases of WeatherChanged.
+const (
+	WeatherStarted   = "start"
+	WeatherContinues = "upkeep"
+	WeatherEnded     = "end"
+)
+
+// WeatherChanged is weather starting, carrying on at the end of a turn or
+// ending. Weather set by an ability names the Pokémon and its ability.
+type WeatherChanged struct {
+	Weather string `json:"weather"`
+	Phase   string `json:"phase"`
+	Pokemon string `json:"pokemon,omitempty"`
+	Side    string `json:"side,omitempty"`
+	Ability string `json:"ability,omitempty"`
+}
+
+// ConditionChanged is a side or field condition, such as Reflect or a
+// terrain, starting or ending. Side is "" for field conditions.
+type ConditionChanged struct {
+	Side      string `json:"side,omitempty"`
+	Condition string `json:"condition"`
+	Ended     bool   `json:"ended,omitempty"`
+}
+
+// HazardSet is a layer of an entry hazard laid on a side.
+type HazardSet struct {
+	Side   string `json:"side"`
+	Hazard string `json:"hazard"`
+}
+
+// HazardCleared is an entry hazard removed from a side by Pokemon's Move,
+// or absorbed by Pokemon switching in when Move is "".
+type HazardCleared struct {
+	Side    string `json:"side"`
+	Hazard  string `json:"hazard"`
+	Pokemon string `json:"pokemon"`
+	Move    string `json:"move,omitempty"`
+}
+
+// CantMove is a Pokémon losing its action: to a status ("slp", "frz",
+// "par"), "flinch", "recharge", or being "locked" into or having "no-pp"
+// for Move.
+type CantMove struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Reason  string `json:"reason"`
+	Move    string `json:"move,omitempty"`
+}
+
+// Activate is an ability, item, move or volatile effect taking effect on a
+// Pokémon, such as Intimidate, Quick Claw, Protect or a charge turn. Target
+// is the other Pokémon involved, if any.
+type Activate struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Effect  string `json:"effect"`
+	Target  string `json:"target,omitempty"`
+}
+
+// ItemRemoved is a Pokémon losing its held item to By's Knock Off.
+type ItemRemoved struct {
+	Pokemon string `json:"pokemon"`
+	Side    string `json:"side,omitempty"`
+	Item    string `json:"item"`
+	By      string `json:"by"`
+}
+
+// HitCount is how many times a multi-hit move struck.
+type HitCount struct {
+	Hits int `json:"hits"`
+}
+
+// Message is free text from outside the engine, such as a server notice.
+type Message struct {
+	Text string `json:"text"`
+}
+
+func (MoveUsed) Kind() string         { return "move" }
+func (Damage) Kind() string           { return "damage" }
+func (Heal) Kind() string             { return "heal" }
+func (Miss) Kind() string             { return "miss" }
+func (Immune) Kind() string           { return "immune" }
+func (Fail) Kind() string             { return "fail" }
+func (Faint) Kind() string            { return "faint" }
+func (Switch) Kind() string           { return "switch" }
+func (StatusApplied) Kind() string    { return "status" }
+func (StatusCured) Kind() string      { return "cure" }
+func (StatChange) Kind() string       { return "stat" }
+func (WeatherChanged) Kind() string   { return "weather" }
+func (ConditionChanged) Kind() string { return "condition" }
+func (HazardSet) Kind() string        { return "hazard" }
+func (HazardCleared) Kind() string    { return "hazard-cleared" }
+func (CantMove) Kind() string         { return "cant" }
+func (Activate) Kind() string         { return "activate" }
+func (ItemRemoved) Kind() string      { return "item-removed" }
+func (HitCount) Kind() string         { return "hits" }
+func (Message) Kind() string          { return "message" }
+
+// eventTypes maps each kind to its type, for decoding.
+var eventTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, e := range []Event{
+		MoveUsed{}, Damage{}, Heal{}, Miss{}, Immune{}, Fail{}, Faint{}, Switch{},
+		StatusApplied{}, StatusCured{}, StatChange{}, WeatherChanged{},
+		ConditionChanged{}, HazardSet{}, HazardCleared{}, CantMove{}, Activate{},
+		ItemRemoved{}, HitCount{}, Message{},
+	} {
+		eventTypes[e.Kind()] = reflect.TypeOf(e)
+	}
+}
+
+// Record carries an event over the wire with its kind, so the receiver can
+// decode it back into the right type.
+type Record struct {
+	Kind  string `json:"kind"`
+	Event Event  `json:"event"`
+}
+
+// Records wraps events for sending.
+func Records(events []Event) []Record {
+	records := make([]Record, len(events))
+	for i, e := range events {
+		records[i] = Record{Kind: e.Kind(), Event: e}
+	}
+	return records
+}
+
+// UnmarshalJSON decodes the event into the type its kind names.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Kind  string          `json:"kind"`
+		Event json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t, ok := eventTypes[raw.Kind]
+	if !ok {
+		return fmt.Errorf("unknown event kind %q", raw.Kind)
+	}
+	e := reflect.New(t)
+	if err := json.Unmarshal(raw.Event, e.Interface()); err != nil {
+		return fmt.Errorf("decoding %s event: %w", raw.Kind, err)
+	}
+	r.Kind, r.Event = raw.Kind, e.Elem().Interface().(Event)
+	return nil
+}
+
+// Events unwraps received records.
+func Events(records []Record) []Event {
+	events := make([]Event, len(records))
+	for i, r := range records {
+		events[i] = r.Event
+	}
+	return events
+}
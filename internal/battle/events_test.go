@@ -0,0 +1,107 @@
+package battle_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestRecordsRoundTripEveryEvent(t *testing.T) {
+	events := []battle.Event{
+		battle.MoveUsed{Pokemon: "machamp", Move: "close-combat"},
+		battle.Damage{Pokemon: "snorlax", Amount: 120, Percent: 25.5, Effectiveness: 2, Critical: true},
+		battle.Faint{Pokemon: "snorlax"},
+		battle.StatChange{Pokemon: "machamp", Stat: "defense", Change: -1, Applied: -1},
+		battle.WeatherChanged{Weather: "rain", Phase: battle.WeatherStarted, Pokemon: "politoed", Ability: "drizzle"},
+		battle.Message{Text: "ash failed to select a valid action!"},
+	}
+	data, err := json.Marshal(battle.Records(events))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []battle.Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	if got := battle.Events(records); !slices.Equal(got, events) {
+		t.Errorf("Expected the events to survive the wire, got %v", got)
+	}
+
+	var unknown battle.Record
+	if err := json.Unmarshal([]byte(`{"kind":"dance","event":{}}`), &unknown); err == nil {
+		t.Error("Expected an unknown kind to fail to decode")
+	}
+}
+
+func TestRenderReportsEffectivenessOncePerMove(t *testing.T) {
+	hit := battle.Damage{Pokemon: "snorlax", Amount: 30, Percent: 6.5, Effectiveness: 2}
+	lines := battle.Render([]battle.Event{
+		battle.MoveUsed{Pokemon: "machamp", Move: "double-kick"},
+		hit, hit,
+		battle.HitCount{Hits: 2},
+	})
+	want := []string{
+		"machamp used double-kick!",
+		"It's super effective!",
+		"snorlax took 30 damage! (6.5%)",
+		"snorlax took 30 damage! (6.5%)",
+		"Hit 2 time(s)!",
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("Expected\n%q\ngot\n%q", want, lines)
+	}
+}
+
+func TestEngineReportsTypedEvents(t *testing.T) {
+	gengar := newBattler(t, "gengar", "", "will-o-wisp")
+	snorlax := newBattler(t, "snorlax", "")
+	wisp := *gengar.Moves[0]
+	wisp.Accuracy = 0
+
+	events := battle.Act(gengar, snorlax, &wisp, nil)
+	want := []battle.Event{
+		battle.MoveUsed{Pokemon: "gengar", Move: "will-o-wisp"},
+		battle.StatusApplied{Pokemon: "snorlax", Status: "brn"},
+	}
+	if !slices.Equal(events, want) {
+		t.Fatalf("Expected %v, got %v", want, events)
+	}
+
+	events = snorlax.HandleTurnEffects()
+	if len(events) != 1 {
+		t.Fatalf("Expected one burn event, got %v", events)
+	}
+	if burn, ok := events[0].(battle.Damage); !ok || burn.Cause != "brn" || burn.Amount != int(snorlax.MaxHP()/16) {
+		t.Errorf("Expected a sixteenth of Snorlax's HP in burn damage, got %v", events[0])
+	}
+}
+
+func TestEventsTellMirrorMatchesApart(t *testing.T) {
+	b := newBattle([]*battle.BattlePokemon{newBattler(t, "snorlax", "", "tackle")}, []*battle.BattlePokemon{newBattler(t, "snorlax", "", "tackle")})
+	b.Start()
+	for side := range 2 {
+		if err := b.Submit(side, battle.MoveAction(0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, _, err := b.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sides := map[string]bool{}
+	for _, e := range events {
+		if used, ok := e.(battle.MoveUsed); ok {
+			sides[used.Side] = true
+		}
+	}
+	if !sides["your team"] || !sides["the opposing team"] {
+		t.Errorf("Expected each Snorlax to be named with its side, got %v", events)
+	}
+	lines := battle.Render(events)
+	if !slices.Contains(lines, "your team's snorlax used tackle!") || !slices.Contains(lines, "the opposing team's snorlax used tackle!") {
+		t.Errorf("Expected the rendered moves to name each side, got %q", lines)
+	}
+}
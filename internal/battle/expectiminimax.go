@@ -0,0 +1,364 @@
+package battle
+
+import (
+	"math"
+	"slices"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// defaultSearchDepth is how many turns ExpectiminimaxAgent looks ahead when
+// its Depth is unset.
+const defaultSearchDepth = 2
+
+// searchAliveBonus is what each member still standing adds to a position,
+// on top of its share of HP left.
+const searchAliveBonus = 0.5
+
+// ExpectiminimaxAgent looks Depth turns ahead over a simplified model of the
+// battle. In the model each damaging move does what ExpectedDamage works
+// out for a hit and misses by its accuracy, the foe answers every choice
+// with the reply that is worst for the agent, and a fainted Pokémon is
+// replaced by the member with the best matchup. Status moves, stat changes
+// and residual damage are left out, which keeps a two turn search quick.
+type ExpectiminimaxAgent struct {
+	Depth int
+}
+
+func (a ExpectiminimaxAgent) Choose(v View) Action {
+	req := v.Request
+	actions := legalActions(req)
+	if len(actions) == 0 {
+		return req.DefaultAction()
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	depth := a.Depth
+	if depth <= 0 {
+		depth = defaultSearchDepth
+	}
+
+	m := newSearchModel(v.Battle, v.Side)
+	s := m.start(v.Battle)
+	best, bestValue := actions[0], math.Inf(-1)
+	for _, action := range actions {
+		var value float64
+		switch {
+		case req.ForceSwitch:
+			next := s
+			next.active[v.Side] = action.Switch
+			value = m.search(next, depth)
+		case action.Kind == ActionSwitch:
+			value = m.reply(s, searchAction{move: -1, switchTo: action.Switch}, depth, bestValue)
+		default:
+			value = m.reply(s, searchAction{move: action.Move, switchTo: -1}, depth, bestValue)
+		}
+		if value > bestValue {
+			best, bestValue = action, value
+		}
+	}
+	return best
+}
+
+// legalActions lists every action req allows.
+func legalActions(req *Request) []Action {
+	var actions []Action
+	for _, i := range req.usableMoves() {
+		actions = append(actions, MoveAction(i))
+	}
+	if req.ForceSwitch || req.CanSwitch {
+		for _, i := range req.Switches {
+			actions = append(actions, SwitchAction(i))
+		}
+	}
+	return actions
+}
+
+// searchMove is one of a member's moves in the search model.
+type searchMove struct {
+	priority int
+	usable   bool
+	// damage is what the move does to each member of the other side when
+	// it hits, and accuracy its chance of hitting them.
+	damage   []float64
+	accuracy []float64
+	// recoil is the HP the user loses when the move hits.
+	recoil float64
+}
+
+// searchMember is what the search model knows of a squad member.
+type searchMember struct {
+	maxHP float64
+	speed float64
+	// moves follow the member's moveset order.
+	moves []searchMove
+}
+
+// searchModel is the simplified battle ExpectiminimaxAgent searches, seen
+// from side.
+type searchModel struct {
+	side      int
+	trickRoom bool
+	members   [2][]searchMember
+}
+
+// searchState is a position in the search: every member's HP and who is
+// active.
+type searchState struct {
+	hp     [2][]float64
+	active [2]int
+}
+
+func (s searchState) clone() searchState {
+	s.hp = [2][]float64{slices.Clone(s.hp[0]), slices.Clone(s.hp[1])}
+	return s
+}
+
+func (s searchState) lost(side int) bool {
+	for _, hp := range s.hp[side] {
+		if hp > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// searchAction is a side's choice in the search.
+type searchAction struct {
+	// move indexes the active member's moves, or is -1 for none.
+	move int
+	// switchTo is the member to switch to, or -1 to stay in.
+	switchTo int
+}
+
+func newSearchModel(b *Battle, side int) *searchModel {
+	m := &searchModel{side: side, trickRoom: b.Field.trickRoom()}
+	for s := range m.members {
+		team, foes := b.Team(s), b.Team(1-s)
+		m.members[s] = make([]searchMember, len(team))
+		for i, bp := range team {
+			if bp == nil {
+				m.members[s][i] = searchMember{maxHP: 1}
+				continue
+			}
+			member := searchMember{maxHP: bp.MaxHP(), speed: effectiveSpeed(bp, b.Field)}
+			moves := bp.Moves
+			// A member with nothing left to pick Struggles, which is its only
+			// move option. The foe's moves are only what it has revealed, so
+			// it is not taken to Struggle before it has shown any.
+			struggling := bp.MustStruggle() && (s == side || len(bp.Moves) > 0)
+			if struggling {
+				moves = []*pokemon.MoveInfo{Struggle}
+			}
+			for _, move := range moves {
+				sm := searchMove{
+					priority: move.Priority,
+					usable:   struggling || bp.MovePP[move.Name] > 0,
+					damage:   make([]float64, len(foes)),
+					accuracy: make([]float64, len(foes)),
+				}
+				if struggling {
+					sm.recoil = math.Max(1, math.Floor(bp.MaxHP()/4))
+				}
+				for j, foe := range foes {
+					if foe == nil || foe.Base == nil || bp.Base == nil {
+						continue
+					}
+					if effectiveness := damageEffectiveness(foe, move, b.Field); effectiveness > 0 {
+						sm.damage[j], sm.accuracy[j] = expectedHit(bp, foe, move, b.Field, effectiveness)
+					}
+				}
+				member.moves = append(member.moves, sm)
+			}
+			m.members[s][i] = member
+		}
+	}
+	return m
+}
+
+// start is the battle's current position.
+func (m *searchModel) start(b *Battle) searchState {
+	s := searchState{active: b.Active}
+	for side := range s.hp {
+		s.hp[side] = make([]float64, len(b.Team(side)))
+		for i, bp := range b.Team(side) {
+			if bp != nil && !bp.Fainted {
+				s.hp[side][i] = bp.CurrentHP
+			}
+		}
+	}
+	return s
+}
+
+// search is the value of s to the agent with depth turns left to play.
+func (m *searchModel) search(s searchState, depth int) float64 {
+	if depth == 0 || s.lost(0) || s.lost(1) {
+		return m.evaluate(s)
+	}
+	best := math.Inf(-1)
+	for _, own := range m.actions(s, m.side) {
+		best = max(best, m.reply(s, own, depth, best))
+	}
+	return best
+}
+
+// reply is the value of the agent playing own in s once the foe answers
+// with its best reply. It stops looking as soon as the value falls to
+// floor, the value of an option the agent already has.
+func (m *searchModel) reply(s searchState, own searchAction, depth int, floor float64) float64 {
+	worst := math.Inf(1)
+	for _, foe := range m.actions(s, 1-m.side) {
+		var acts [2]searchAction
+		acts[m.side], acts[1-m.side] = own, foe
+		worst = min(worst, m.turn(s, acts, depth))
+		if worst <= floor {
+			break
+		}
+	}
+	return worst
+}
+
+// actions lists side's choices in s: its active member's damaging moves,
+// or doing nothing if it has none, and every switch.
+func (m *searchModel) actions(s searchState, side int) []searchAction {
+	var actions []searchAction
+	active, target := s.active[side], s.active[1-side]
+	for i, move := range m.members[side][active].moves {
+		if move.usable && move.damage[target] > 0 {
+			actions = append(actions, searchAction{move: i, switchTo: -1})
+		}
+	}
+	if len(actions) == 0 {
+		actions = append(actions, searchAction{move: -1, switchTo: -1})
+	}
+	for i, hp := range s.hp[side] {
+		if hp > 0 && i != active {
+			actions = append(actions, searchAction{move: -1, switchTo: i})
+		}
+	}
+	return actions
+}
+
+// turn is the expected value of a turn in which both sides play acts.
+// Switches come first, then the moves by priority and speed, with a speed
+// tie settled either way with equal odds.
+func (m *searchModel) turn(s searchState, acts [2]searchAction, depth int) float64 {
+	for side, act := range acts {
+		if act.switchTo >= 0 {
+			s.active[side] = act.switchTo
+		}
+	}
+	priority := [2]int{}
+	speed := [2]float64{}
+	for side, act := range acts {
+		member := m.members[side][s.active[side]]
+		if act.move >= 0 {
+			priority[side] = member.moves[act.move].priority
+		}
+		speed[side] = member.speed
+	}
+	if m.trickRoom {
+		speed[0], speed[1] = speed[1], speed[0]
+	}
+	switch {
+	case priority[0] != priority[1]:
+		if priority[1] > priority[0] {
+			return m.strike(s, acts, [2]int{1, 0}, 0, depth)
+		}
+	case speed[0] == speed[1]:
+		return (m.strike(s, acts, [2]int{0, 1}, 0, depth) + m.strike(s, acts, [2]int{1, 0}, 0, depth)) / 2
+	case speed[1] > speed[0]:
+		return m.strike(s, acts, [2]int{1, 0}, 0, depth)
+	}
+	return m.strike(s, acts, [2]int{0, 1}, 0, depth)
+}
+
+// strike plays the k-th move of the turn in order and those after it,
+// weighing whether it hits or misses.
+func (m *searchModel) strike(s searchState, acts [2]searchAction, order [2]int, k, depth int) float64 {
+	if k == len(order) {
+		return m.endTurn(s, depth)
+	}
+	side := order[k]
+	attacker, target := s.active[side], s.active[1-side]
+	act := acts[side]
+	if act.move < 0 || s.hp[side][attacker] <= 0 {
+		return m.strike(s, acts, order, k+1, depth)
+	}
+	move := m.members[side][attacker].moves[act.move]
+	damage, chance := move.damage[target], move.accuracy[target]
+	if damage <= 0 || chance <= 0 {
+		return m.strike(s, acts, order, k+1, depth)
+	}
+	hit := s.clone()
+	hit.hp[1-side][target] = max(0, hit.hp[1-side][target]-damage)
+	hit.hp[side][attacker] = max(0, hit.hp[side][attacker]-move.recoil)
+	value := chance * m.strike(hit, acts, order, k+1, depth)
+	if chance < 1 {
+		value += (1 - chance) * m.strike(s, acts, order, k+1, depth)
+	}
+	return value
+}
+
+// endTurn replaces fainted active members and searches on from the next
+// turn.
+func (m *searchModel) endTurn(s searchState, depth int) float64 {
+	for side := range s.active {
+		if s.hp[side][s.active[side]] <= 0 && !s.lost(side) {
+			s.active[side] = m.replacement(s, side)
+		}
+	}
+	return m.search(s, depth-1)
+}
+
+// replacement picks the member side sends in for its fainted active one:
+// the one with the best matchup against the foe's active member.
+func (m *searchModel) replacement(s searchState, side int) int {
+	best, bestScore := -1, math.Inf(-1)
+	foe := s.active[1-side]
+	for i, hp := range s.hp[side] {
+		if hp <= 0 {
+			continue
+		}
+		if score := m.threat(s, side, i, foe) - m.threat(s, 1-side, foe, i); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// threat is the share of target's HP that member of side can be expected
+// to take with its best move, from 0 to 1.
+func (m *searchModel) threat(s searchState, side, member, target int) float64 {
+	hp := s.hp[1-side][target]
+	if hp <= 0 {
+		return 0
+	}
+	best := 0.0
+	for _, move := range m.members[side][member].moves {
+		if move.usable {
+			best = max(best, move.damage[target]*move.accuracy[target])
+		}
+	}
+	return min(best/hp, 1)
+}
+
+// evaluate scores s for the agent: the share of HP each side has left over
+// its squad, plus a bonus for every member still standing, counted for the
+// agent and against the foe.
+func (m *searchModel) evaluate(s searchState) float64 {
+	value := 0.0
+	for side := range s.hp {
+		sign := 1.0
+		if side != m.side {
+			sign = -1
+		}
+		for i, hp := range s.hp[side] {
+			if hp > 0 {
+				value += sign * (hp/m.members[side][i].maxHP + searchAliveBonus)
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,9 @@
+package battle
+
+// Act has attacker use move on defender outside of a Battle, and
+// ExecuteTurn plays a whole turn between two Pokémon, so tests can look at
+// one piece of the engine at a time.
+var (
+	Act         = processAction
+	ExecuteTurn = executeTurn
+)
@@ -0,0 +1,77 @@
+package battle
+
+import (
+	"maps"
+	"math/rand/v2"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// Field is the battle-wide state shared by both active Pokémon. A nil *Field
+// behaves like a clear field under the latest type chart.
+type Field struct {
+	Weather string
+	// WeatherTurns counts down the turns left before Weather ends.
+	WeatherTurns int
+	Terrain      string
+	TerrainTurns int
+	// Conditions maps field-wide conditions such as Trick Room to the turns
+	// they have left.
+	Conditions map[string]int
+	// Sides holds each player's side once squads have joined the field.
+	Sides [2]*Side
+	Chart *pokemon.TypeChart
+	// Seed is the seed the battle's random decisions follow from. Replaying
+	// the same actions on a field with the same seed repeats the battle.
+	Seed uint64
+	rand *rand.Rand
+	// format is the rules the field was started under.
+	format Format
+}
+
+// NewField starts a clear field under format's rules whose random decisions
+// follow from seed.
+func NewField(format Format, seed uint64) *Field {
+	chart := format.Chart
+	if chart == nil {
+		chart = pokemon.BundledTypeChart(format.Generation)
+	}
+	return &Field{Chart: chart, Conditions: make(map[string]int), Seed: seed, rand: NewRand(seed), format: format}
+}
+
+// clone copies the field and both squads for Battle.Clone, with random
+// decisions following from seed.
+func (f *Field) clone(seed uint64) *Field {
+	c := *f
+	c.Conditions = maps.Clone(f.Conditions)
+	c.Seed, c.rand = seed, NewRand(seed)
+	for i, side := range f.Sides {
+		if side == nil {
+			continue
+		}
+		members := make([]*BattlePokemon, len(side.Members))
+		for j, bp := range side.Members {
+			if bp != nil {
+				members[j] = bp.Clone()
+			}
+		}
+		c.Join(i, side.Name, members)
+		c.Sides[i].Conditions = maps.Clone(side.Conditions)
+		c.Sides[i].Hazards = maps.Clone(side.Hazards)
+	}
+	return &c
+}
+
+func (f *Field) chart() *pokemon.TypeChart {
+	if f == nil || f.Chart == nil {
+		return pokemon.BundledTypeChart(pokemon.LatestGeneration)
+	}
+	return f.Chart
+}
+
+func (f *Field) weather() string {
+	if f == nil {
+		return ""
+	}
+	return f.Weather
+}
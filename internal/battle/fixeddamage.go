@@ -0,0 +1,90 @@
+package battle
+
+import (
+	"math"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// fixedDamageMoves deal damage that ignores the damage formula: a set
+// amount, the user's level, a share of the target's HP, or a multiple of
+// the last hit the user took.
+var fixedDamageMoves = map[string]bool{
+	"seismic-toss":    true,
+	"night-shade":     true,
+	"dragon-rage":     true,
+	"sonic-boom":      true,
+	"super-fang":      true,
+	"natures-madness": true,
+	"ruination":       true,
+	"psywave":         true,
+	"endeavor":        true,
+	"counter":         true,
+	"mirror-coat":     true,
+	"metal-burst":     true,
+}
+
+// ohko reports whether move knocks out its target in one hit.
+func ohko(move *pokemon.MoveInfo) bool {
+	return move.Meta.Category.Name == "ohko"
+}
+
+func fixedDamageMove(move *pokemon.MoveInfo) bool {
+	return fixedDamageMoves[move.Name] || ohko(move)
+}
+
+// fixedDamage works out the damage of a fixed damage or OHKO move. Zero
+// means the move fails.
+func fixedDamage(attacker, defender *BattlePokemon, move *pokemon.MoveInfo) int {
+	if ohko(move) {
+		return int(math.Ceil(defender.CurrentHP))
+	}
+	switch move.Name {
+	case "seismic-toss", "night-shade":
+		return attacker.level()
+	case "dragon-rage":
+		return 40
+	case "sonic-boom":
+		return 20
+	case "super-fang", "natures-madness", "ruination":
+		return max(1, int(defender.CurrentHP/2))
+	case "psywave":
+		return max(1, attacker.level()*(50+attacker.rng().IntN(101))/100)
+	case "endeavor":
+		return max(0, int(defender.CurrentHP-attacker.CurrentHP))
+	case "counter":
+		return retaliation(attacker, "physical", 2)
+	case "mirror-coat":
+		return retaliation(attacker, "special", 2)
+	case "metal-burst":
+		return retaliation(attacker, "", 1.5)
+	}
+	return 0
+}
+
+// recordHit remembers the hit move dealt bp for Counter and its kin. A hit
+// its substitute took never reached bp, so it leaves nothing to return.
+func recordHit(bp *BattlePokemon, move *pokemon.MoveInfo, dmg int, substitute bool) {
+	if substitute {
+		bp.LastDamage, bp.LastDamageClass = 0, ""
+		return
+	}
+	bp.LastDamage, bp.LastDamageClass = dmg, move.DamageClass.Name
+}
+
+// retaliation returns multiplier times the damage user last took this turn
+// from a move of the given damage class, or of any class when class is "".
+func retaliation(user *BattlePokemon, class string, multiplier float64) int {
+	if user.LastDamage == 0 || class != "" && user.LastDamageClass != class {
+		return 0
+	}
+	return int(float64(user.LastDamage) * multiplier)
+}
+
+// ohkoHits rolls an OHKO move's accuracy, which ignores accuracy and
+// evasion stages and rises by one for each level the user has over the
+// target. It always fails against a higher level target.
+func ohkoHits(attacker, defender *BattlePokemon, move *pokemon.MoveInfo) bool {
+	diff := attacker.level() - defender.level()
+	return diff >= 0 && attacker.rng().Float64()*100 < float64(move.Accuracy+diff)
+}
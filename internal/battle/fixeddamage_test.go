@@ -0,0 +1,121 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestFixedDamageMovesIgnoreTheFormula(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "seismic-toss", "super-fang")
+	blastoise := newBattler(t, "blastoise", "", "dragon-rage")
+	snorlax := newBattler(t, "snorlax", "")
+	gengar := newBattler(t, "gengar", "")
+
+	toss := *machamp.Moves[0]
+	toss.Accuracy = 0
+	if dmg, _, _ := battle.DamageCalc(machamp, snorlax, &toss, nil); dmg != machamp.Spread.Level {
+		t.Errorf("Expected Seismic Toss to deal the user's level %d, got %d", machamp.Spread.Level, dmg)
+	}
+	if dmg, _, events := battle.DamageCalc(machamp, gengar, &toss, nil); dmg != 0 {
+		t.Errorf("Expected Seismic Toss not to affect Gengar, got %d: %v", dmg, events)
+	}
+
+	rage := *blastoise.Moves[0]
+	rage.Accuracy = 0
+	if dmg, _, _ := battle.DamageCalc(blastoise, snorlax, &rage, nil); dmg != 40 {
+		t.Errorf("Expected Dragon Rage to deal 40, got %d", dmg)
+	}
+
+	fang := *machamp.Moves[1]
+	fang.Accuracy = 0
+	snorlax.CurrentHP = 101
+	if dmg, _, _ := battle.DamageCalc(machamp, snorlax, &fang, nil); dmg != 50 {
+		t.Errorf("Expected Super Fang to halve 101 HP for 50, got %d", dmg)
+	}
+}
+
+func TestCounterReturnsDoubleThePhysicalDamageTaken(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "counter")
+	snorlax := newBattler(t, "snorlax", "", "tackle", "mirror-coat")
+
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if !hasEvent(events, "But it failed!") {
+		t.Errorf("Expected Counter to fail without a hit to return, got %v", events)
+	}
+
+	battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+	taken := machamp.LastDamage
+	if taken == 0 || machamp.LastDamageClass != "physical" {
+		t.Fatalf("Expected Machamp to remember Tackle's damage, got %d (%s)", taken, machamp.LastDamageClass)
+	}
+	hp := snorlax.CurrentHP
+	battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if dealt := hp - snorlax.CurrentHP; dealt != float64(2*taken) {
+		t.Errorf("Expected Counter to deal %d, dealt %.0f", 2*taken, dealt)
+	}
+
+	events = battle.Act(snorlax, machamp, snorlax.Moves[1], nil)
+	if !hasEvent(events, "But it failed!") {
+		t.Errorf("Expected Mirror Coat to fail against physical damage, got %v", events)
+	}
+
+	machamp.HandleTurnEffects()
+	if machamp.LastDamage != 0 {
+		t.Error("Expected the damage record to clear at the end of the turn")
+	}
+}
+
+func TestCounterIgnoresHitsTakenBySubstitute(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "counter", "substitute")
+	snorlax := newBattler(t, "snorlax", "", "tackle")
+
+	battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+	if machamp.LastDamage == 0 {
+		t.Fatal("Expected Machamp to remember Tackle's damage")
+	}
+	battle.Act(machamp, snorlax, machamp.Moves[1], nil)
+	battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+
+	hp := snorlax.CurrentHP
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if snorlax.CurrentHP != hp || !hasEvent(events, "But it failed!") {
+		t.Errorf("Expected Counter to fail after its last hit struck the substitute, got %v", events)
+	}
+}
+
+func TestOHKOMoves(t *testing.T) {
+	golem := newBattler(t, "golem", "", "fissure")
+	sturdy := newBattler(t, "golem", "sturdy")
+	snorlax := newBattler(t, "snorlax", "")
+	fissure := golem.Moves[0]
+
+	if _, _, events := battle.DamageCalc(golem, sturdy, fissure, nil); !hasEvent(events, "Sturdy") {
+		t.Errorf("Expected Sturdy to block Fissure, got %v", events)
+	}
+
+	hits := 0
+	for range 600 {
+		snorlax.CurrentHP, snorlax.Fainted = snorlax.MaxHP(), false
+		golem.MovePP[fissure.Name] = fissure.Pp
+		events := battle.Act(golem, snorlax, fissure, nil)
+		for _, e := range events {
+			if hit, ok := e.(battle.Damage); ok {
+				hits++
+				if !hit.OHKO || !snorlax.Fainted {
+					t.Fatalf("Expected Fissure to knock out in one hit, did %d: %v", hit.Amount, events)
+				}
+			}
+		}
+	}
+	if rate := float64(hits) / 600; rate < 0.22 || rate > 0.38 {
+		t.Errorf("Expected Fissure to hit about 30%% of the time at equal levels, got %.2f", rate)
+	}
+
+	snorlax.Spread.Level = golem.Spread.Level + 1
+	for range 100 {
+		if dmg, _, _ := battle.DamageCalc(golem, snorlax, fissure, nil); dmg > 0 {
+			t.Fatal("Expected Fissure to always fail against a higher level target")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package battle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+	"github.com/ross1116/pokebattlecli/internal/stats"
+)
+
+// Format holds the rules a battle is played under.
+type Format struct {
+	Name string
+	// Level every Pokémon battles at. Zero scales each species by its base
+	// stat total instead, so weak species stay competitive in random
+	// battles.
+	Level int
+	// Generation whose type chart applies. Zero means the latest.
+	Generation int
+	// Chart is the type chart loaded by LoadChart. Battles fall back to the
+	// bundled chart for Generation while it is nil.
+	Chart *pokemon.TypeChart
+}
+
+const DefaultFormat = "random"
+
+var formats = map[string]Format{
+	"random":     {Name: "random"},
+	"singles":    {Name: "singles", Level: 100},
+	"vgc":        {Name: "vgc", Level: 50},
+	"little-cup": {Name: "little-cup", Level: 5},
+}
+
+// LookupFormat resolves a -format flag value. Besides the named formats,
+// "level-N" plays every Pokémon at level N, and a "genN-" prefix such as
+// "gen1-singles" uses that generation's type chart.
+func LookupFormat(name string) (Format, error) {
+	if name == "" {
+		name = DefaultFormat
+	}
+	generation := 0
+	rules := name
+	if rest, ok := strings.CutPrefix(name, "gen"); ok {
+		number, tail, found := strings.Cut(rest, "-")
+		gen, err := strconv.Atoi(number)
+		if !found || err != nil || gen < 1 || gen > pokemon.LatestGeneration {
+			return Format{}, fmt.Errorf("invalid generation in format %q", name)
+		}
+		generation, rules = gen, tail
+	}
+
+	var f Format
+	if named, ok := formats[rules]; ok {
+		f = named
+	} else if rest, ok := strings.CutPrefix(rules, "level-"); ok {
+		level, err := strconv.Atoi(rest)
+		if err != nil || level < 1 || level > stats.MaxLevel {
+			return Format{}, fmt.Errorf("invalid level in format %q", name)
+		}
+		f.Level = level
+	} else {
+		return Format{}, fmt.Errorf("unknown format %q (want one of %s, or level-N, optionally prefixed with genN-)", name, strings.Join(FormatNames(), ", "))
+	}
+	f.Name = name
+	f.Generation = generation
+	return f, nil
+}
+
+// LoadChart loads the format's type chart from src.
+func (f *Format) LoadChart(ctx context.Context, src pokemon.DataSource) error {
+	chart, err := pokemon.LoadTypeChart(ctx, src, f.Generation)
+	if err != nil {
+		return err
+	}
+	f.Chart = chart
+	return nil
+}
+
+// FormatNames lists the named formats, sorted.
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LevelFor returns the level p battles at in this format.
+func (f Format) LevelFor(p *pokemon.Pokemon) int {
+	if f.Level > 0 {
+		return f.Level
+	}
+	return AutoLevel(p)
+}
+
+// Bounds for AutoLevel: the weakest species battle at level 100 and the
+// strongest at 70.
+const (
+	autoLevelWeakBST   = 250
+	autoLevelStrongBST = 680
+	autoLevelMin       = 70
+)
+
+// AutoLevel picks a level from the species' base stat total, interpolating
+// between level 100 for the weakest species and level 70 for legendaries.
+func AutoLevel(p *pokemon.Pokemon) int {
+	bst := 0
+	for _, name := range stats.Names {
+		bst += stats.GetStat(p, name)
+	}
+	span := float64(stats.MaxLevel - autoLevelMin)
+	strength := float64(bst-autoLevelWeakBST) / float64(autoLevelStrongBST-autoLevelWeakBST)
+	level := stats.MaxLevel - int(math.Round(strength*span))
+	return max(autoLevelMin, min(stats.MaxLevel, level))
+}
+
+// spreadFor is the spread a Pokémon is given when squads are drawn: perfect
+// IVs, no EVs, a nature drawn from r and the format's level.
+func (f Format) spreadFor(p *pokemon.Pokemon, r *rand.Rand) stats.StatSpread {
+	spread := stats.DefaultSpread()
+	spread.Nature = stats.RandomNature(r)
+	spread.Level = f.LevelFor(p)
+	return spread
+}
@@ -0,0 +1,76 @@
+package battle_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestLookupFormat(t *testing.T) {
+	for name, level := range map[string]int{"vgc": 50, "little-cup": 5, "singles": 100, "level-37": 37, "": 0} {
+		f, err := battle.LookupFormat(name)
+		if err != nil {
+			t.Errorf("LookupFormat(%q): %v", name, err)
+			continue
+		}
+		if f.Level != level {
+			t.Errorf("LookupFormat(%q).Level = %d, want %d", name, f.Level, level)
+		}
+	}
+	for _, name := range []string{"level-0", "level-101", "level-x", "ubers"} {
+		if _, err := battle.LookupFormat(name); err == nil {
+			t.Errorf("LookupFormat(%q) should fail", name)
+		}
+	}
+}
+
+func TestAutoLevelFavoursWeakSpecies(t *testing.T) {
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+	magikarp, err := src.Pokemon(ctx, "magikarp")
+	if err != nil {
+		t.Fatalf("Failed to fetch Magikarp: %v", err)
+	}
+	dragonite, err := src.Pokemon(ctx, "dragonite")
+	if err != nil {
+		t.Fatalf("Failed to fetch Dragonite: %v", err)
+	}
+
+	weak, strong := battle.AutoLevel(magikarp), battle.AutoLevel(dragonite)
+	if weak != 100 {
+		t.Errorf("Expected Magikarp at level 100, got %d", weak)
+	}
+	if strong >= 85 || strong < 70 {
+		t.Errorf("Expected Dragonite between 70 and 85, got %d", strong)
+	}
+
+	random, _ := battle.LookupFormat("random")
+	if got := random.LevelFor(dragonite); got != strong {
+		t.Errorf("Random format should auto-level, got %d", got)
+	}
+}
+
+func TestLookupFormatGenerationPrefix(t *testing.T) {
+	f, err := battle.LookupFormat("gen1-singles")
+	if err != nil {
+		t.Fatalf("LookupFormat: %v", err)
+	}
+	if f.Generation != 1 || f.Level != 100 {
+		t.Errorf("Expected a level 100 generation 1 format, got %+v", f)
+	}
+
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+	gengar := newBattler(t, "gengar", "")
+	alakazam := newBattler(t, "alakazam", "")
+	shadowBall, err := src.MoveByName(ctx, "shadow-ball")
+	if err != nil {
+		t.Fatalf("Failed to fetch Shadow Ball: %v", err)
+	}
+	dmg, _, events := battle.DamageCalc(gengar, alakazam, shadowBall, battle.NewField(f, 1))
+	if dmg != 0 || len(events) == 0 || events[len(events)-1] != (battle.Immune{Pokemon: "alakazam"}) {
+		t.Errorf("Expected Ghost moves not to affect Psychic types in generation 1, got %d damage, %v", dmg, events)
+	}
+}
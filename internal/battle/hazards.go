@@ -0,0 +1,143 @@
+package battle
+
+import (
+	"sort"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// hazard describes an entry hazard: a move that lays it on the foe's side
+// and hurts or hinders Pokémon switching in there.
+type hazard struct {
+	maxLayers int
+}
+
+var hazardMoves = map[string]hazard{
+	"stealth-rock": {maxLayers: 1},
+	"spikes":       {maxLayers: 3},
+	"toxic-spikes": {maxLayers: 2},
+	"sticky-web":   {maxLayers: 1},
+}
+
+// spikesDamage is the fraction of max HP Spikes take for one to three
+// layers.
+var spikesDamage = []float64{0, 1.0 / 8, 1.0 / 6, 1.0 / 4}
+
+// layHazard adds a layer of move's hazard to the side opposite user.
+func (f *Field) layHazard(user, foe *BattlePokemon, move *pokemon.MoveInfo) []Event {
+	h := hazardMoves[move.Name]
+	side := f.SideOf(foe)
+	if side == nil || side.Hazards[move.Name] >= h.maxLayers {
+		return nil
+	}
+	if side.Hazards == nil {
+		side.Hazards = make(map[string]int)
+	}
+	side.Hazards[move.Name]++
+	return []Event{HazardSet{Side: side.Name, Hazard: move.Name}}
+}
+
+// clearHazards removes every hazard from side and reports what went, blown
+// away by user's move.
+func clearHazards(side *Side, user *BattlePokemon, move string) []Event {
+	if side == nil || len(side.Hazards) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(side.Hazards))
+	for name := range side.Hazards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	side.Hazards = make(map[string]int)
+
+	events := make([]Event, len(names))
+	for i, name := range names {
+		events[i] = HazardCleared{Side: side.Name, Hazard: name, Pokemon: user.Base.Name, Move: move}
+	}
+	return events
+}
+
+// hazardClearing removes hazards after Rapid Spin hits or Defog is used.
+// Rapid Spin clears the user's side; Defog clears both sides along with the
+// target side's screens.
+func hazardClearing(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) []Event {
+	if attacker.Fainted {
+		return nil
+	}
+	switch move.Name {
+	case "rapid-spin":
+		return clearHazards(field.SideOf(attacker), attacker, move.Name)
+	case "defog":
+		events := clearHazards(field.SideOf(defender), attacker, move.Name)
+		events = append(events, clearHazards(field.SideOf(attacker), attacker, move.Name)...)
+		if side := field.SideOf(defender); side != nil {
+			delete(side.Conditions, "reflect")
+			delete(side.Conditions, "light-screen")
+		}
+		return events
+	}
+	return nil
+}
+
+// entryHazards hurts or hinders bp as it switches in to side.
+func (f *Field) entryHazards(bp *BattlePokemon, side *Side) []Event {
+	if side == nil || len(side.Hazards) == 0 {
+		return nil
+	}
+	var events []Event
+	if side.Hazards["stealth-rock"] > 0 {
+		rock := &pokemon.MoveInfo{Type: pokemon.ApiResource{Name: "rock"}}
+		if effectiveness := effectivenessCheck(rock, bp, f); effectiveness > 0 {
+			events = append(events, bp.hurt(bp.MaxHP()*effectiveness/8, "stealth-rock")...)
+		}
+	}
+	if !grounded(bp) {
+		return events
+	}
+	if layers := side.Hazards["spikes"]; layers > 0 && !bp.Fainted {
+		events = append(events, bp.hurt(bp.MaxHP()*spikesDamage[layers], "spikes")...)
+	}
+	if layers := side.Hazards["toxic-spikes"]; layers > 0 && !bp.Fainted {
+		switch {
+		case hasType(bp, "poison"):
+			delete(side.Hazards, "toxic-spikes")
+			events = append(events, HazardCleared{Side: side.Name, Hazard: "toxic-spikes", Pokemon: bp.Base.Name})
+		case bp.Status == "" && !statusImmune(bp, "psn") && !terrainBlocksStatus(f, bp, "psn"):
+			status := "psn"
+			if layers > 1 {
+				status = "tox"
+			}
+			events = append(events, bp.inflictStatus(status, "toxic-spikes", "")...)
+		}
+	}
+	if side.Hazards["sticky-web"] > 0 && !bp.Fainted {
+		events = append(events, Activate{Pokemon: bp.Base.Name, Side: bp.side, Effect: "sticky-web"})
+		events = append(events, bp.changeStage("speed", -1)...)
+	}
+	return events
+}
+
+func faintCheck(bp *BattlePokemon, events []Event) []Event {
+	if bp.Fainted {
+		events = append(events, Faint{Pokemon: bp.Base.Name, Side: bp.side})
+	}
+	return events
+}
+
+// SendOut is the switch pipeline every battle loop runs when a Pokémon
+// enters the field: outgoing, if any, leaves and loses its volatile state,
+// then incoming takes the entry hazards on its side and its switch-in
+// ability triggers against foe.
+func (f *Field) SendOut(outgoing, incoming, foe *BattlePokemon) []Event {
+	if outgoing != nil && outgoing != incoming {
+		SwitchOut(outgoing)
+	}
+	if incoming == nil || incoming.Fainted {
+		return nil
+	}
+	events := f.entryHazards(incoming, f.SideOf(incoming))
+	if incoming.Fainted {
+		return events
+	}
+	return append(events, SwitchIn(incoming, foe, f)...)
+}
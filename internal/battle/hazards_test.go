@@ -0,0 +1,79 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestStealthRockScalesWithType(t *testing.T) {
+	golem := newBattler(t, "golem", "", "stealth-rock")
+	snorlax := newBattler(t, "snorlax", "")
+	charizard := newBattler(t, "charizard", "")
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{golem})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{snorlax, charizard})
+
+	battle.Act(golem, snorlax, golem.Moves[0], field)
+	if field.Sides[1].Hazards["stealth-rock"] != 1 {
+		t.Fatalf("Expected Stealth Rock on the opposing side, got %v", field.Sides[1].Hazards)
+	}
+
+	field.SendOut(snorlax, charizard, golem)
+	if want := charizard.MaxHP() - charizard.MaxHP()/2; charizard.CurrentHP != want {
+		t.Errorf("Expected Stealth Rock to take half of Charizard's HP, left %.1f want %.1f", charizard.CurrentHP, want)
+	}
+	field.SendOut(charizard, snorlax, golem)
+	if want := snorlax.MaxHP() - snorlax.MaxHP()/8; snorlax.CurrentHP != want {
+		t.Errorf("Expected Stealth Rock to take an eighth of Snorlax's HP, left %.1f want %.1f", snorlax.CurrentHP, want)
+	}
+}
+
+func TestGroundHazardsSkipFlyingPokemon(t *testing.T) {
+	cloyster := newBattler(t, "cloyster", "", "spikes", "toxic-spikes")
+	snorlax := newBattler(t, "snorlax", "")
+	skarmory := newBattler(t, "skarmory", "")
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{cloyster})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{snorlax, skarmory})
+
+	for range 4 {
+		cloyster.MovePP["spikes"] = 20
+		battle.Act(cloyster, snorlax, cloyster.Moves[0], field)
+	}
+	for range 2 {
+		battle.Act(cloyster, snorlax, cloyster.Moves[1], field)
+	}
+	if got := field.Sides[1].Hazards; got["spikes"] != 3 || got["toxic-spikes"] != 2 {
+		t.Fatalf("Expected 3 layers of Spikes and 2 of Toxic Spikes, got %v", got)
+	}
+
+	field.SendOut(snorlax, skarmory, cloyster)
+	if skarmory.CurrentHP != skarmory.MaxHP() || skarmory.Status != "" {
+		t.Errorf("Flying-type Skarmory was hit by ground hazards")
+	}
+	field.SendOut(skarmory, snorlax, cloyster)
+	if want := snorlax.MaxHP() - snorlax.MaxHP()/4; snorlax.CurrentHP != want {
+		t.Errorf("Expected 3 layers of Spikes to take a quarter of Snorlax's HP, left %.1f want %.1f", snorlax.CurrentHP, want)
+	}
+	if snorlax.Status != "tox" {
+		t.Errorf("Expected 2 layers of Toxic Spikes to badly poison, got %q", snorlax.Status)
+	}
+}
+
+func TestRapidSpinClearsHazards(t *testing.T) {
+	blastoise := newBattler(t, "blastoise", "", "rapid-spin")
+	snorlax := newBattler(t, "snorlax", "")
+	field := battle.NewField(battle.Format{}, 1)
+	field.Join(0, "your team", []*battle.BattlePokemon{blastoise})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{snorlax})
+	field.Sides[0].Hazards["stealth-rock"] = 1
+	field.Sides[0].Hazards["sticky-web"] = 1
+
+	for i := 0; i < 10 && len(field.Sides[0].Hazards) > 0; i++ {
+		battle.Act(blastoise, snorlax, blastoise.Moves[0], field)
+	}
+	if len(field.Sides[0].Hazards) != 0 {
+		t.Errorf("Expected Rapid Spin to clear the user's side, got %v", field.Sides[0].Hazards)
+	}
+}
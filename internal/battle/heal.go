@@ -0,0 +1,55 @@
+package battle
+
+import "github.com/ross1116/pokebattlecli/internal/pokemon"
+
+// restSleepTurns is how many turns Rest's sleep always lasts.
+const restSleepTurns = 2
+
+// weatherHealMoves heal more in sun and less in any other weather.
+var weatherHealMoves = map[string]bool{
+	"moonlight":   true,
+	"synthesis":   true,
+	"morning-sun": true,
+}
+
+// healingPercent is the share of max HP move restores to its user under the
+// current weather.
+func healingPercent(move *pokemon.MoveInfo, field *Field) float64 {
+	if !weatherHealMoves[move.Name] {
+		return float64(move.Meta.Healing)
+	}
+	switch field.weather() {
+	case "":
+		return float64(move.Meta.Healing)
+	case "sun":
+		return 200.0 / 3
+	default:
+		return 25
+	}
+}
+
+// rest fully heals user and puts it to sleep in place of any other status.
+func rest(user *BattlePokemon, field *Field) []Event {
+	if user.CurrentHP >= user.MaxHP() {
+		return []Event{Fail{Pokemon: user.Base.Name, Side: user.side, Reason: "hp-full"}}
+	}
+	if user.Status == "slp" || terrainBlocksStatus(field, user, "slp") {
+		return []Event{Fail{Pokemon: user.Base.Name, Side: user.side}}
+	}
+	user.Status, user.StatusTurns = "", 0
+	events := user.restore(user.MaxHP(), "rest")
+	events = append(events, user.inflictStatus("slp", "rest", "")...)
+	if user.Status == "slp" {
+		user.StatusTurns = restSleepTurns
+	}
+	return events
+}
+
+// roost grounds a Flying type for the rest of the turn after it heals.
+func roost(user, foe *BattlePokemon, move *pokemon.MoveInfo, field *Field) []Event {
+	events := applyMoveEffects(user, foe, move, 0, field)
+	if hasType(user, "flying") {
+		user.setVolatile("roost", move.Name, 1)
+	}
+	return events
+}
@@ -0,0 +1,64 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestRecoverHealsHalfMaxHP(t *testing.T) {
+	alakazam := newBattler(t, "alakazam", "", "recover")
+	snorlax := newBattler(t, "snorlax", "")
+	alakazam.CurrentHP = 10
+
+	battle.Act(alakazam, snorlax, alakazam.Moves[0], nil)
+	if want := 10 + alakazam.MaxHP()/2; alakazam.CurrentHP != want {
+		t.Errorf("Expected Recover to restore half of max HP to %.1f, got %.1f", want, alakazam.CurrentHP)
+	}
+}
+
+func TestRestHealsFullyAndSleepsTwoTurns(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "rest", "tackle")
+	machamp := newBattler(t, "machamp", "")
+	snorlax.CurrentHP = 10
+	snorlax.Status = "brn"
+
+	battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+	if snorlax.CurrentHP != snorlax.MaxHP() || snorlax.Status != "slp" {
+		t.Fatalf("Expected Rest to heal fully and put Snorlax to sleep, got %.0f HP and status %q", snorlax.CurrentHP, snorlax.Status)
+	}
+	snorlax.HandleTurnEffects()
+
+	for turn := 1; turn <= 2; turn++ {
+		events := battle.Act(snorlax, machamp, snorlax.Moves[1], nil)
+		if !hasEvent(events, "fast asleep") {
+			t.Fatalf("Expected Snorlax to sleep through turn %d after Rest, got %v", turn, events)
+		}
+		snorlax.HandleTurnEffects()
+	}
+	events := battle.Act(snorlax, machamp, snorlax.Moves[1], nil)
+	if snorlax.Status != "" || !hasEvent(events, "woke up") {
+		t.Errorf("Expected Snorlax to wake up on its third turn, still %q: %v", snorlax.Status, events)
+	}
+}
+
+func TestRoostGroundsFlyingTypesForTheTurn(t *testing.T) {
+	charizard := newBattler(t, "charizard", "", "roost")
+	golem := newBattler(t, "golem", "", "earthquake")
+	charizard.CurrentHP = charizard.MaxHP() / 2
+
+	if dmg, _, _ := battle.DamageCalc(golem, charizard, golem.Moves[0], nil); dmg != 0 {
+		t.Fatalf("Expected Earthquake to miss an airborne Charizard, did %d", dmg)
+	}
+	battle.Act(charizard, golem, charizard.Moves[0], nil)
+	if charizard.CurrentHP != charizard.MaxHP() {
+		t.Errorf("Expected Roost to heal Charizard, got %.0f/%.0f HP", charizard.CurrentHP, charizard.MaxHP())
+	}
+	if dmg, _, _ := battle.DamageCalc(golem, charizard, golem.Moves[0], nil); dmg == 0 {
+		t.Error("Expected Earthquake to hit Charizard after Roost")
+	}
+	charizard.HandleTurnEffects()
+	if dmg, _, _ := battle.DamageCalc(golem, charizard, golem.Moves[0], nil); dmg != 0 {
+		t.Errorf("Expected Charizard to be airborne again next turn, Earthquake did %d", dmg)
+	}
+}
@@ -0,0 +1,332 @@
+package battle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// Item describes how a held item hooks into the battle. Like Ability, every
+// hook is optional and receives the holder as self.
+type Item struct {
+	Name string
+
+	// ModifyHit adjusts a damaging move while DamageCalc works it out. It is
+	// called for both the attacker and the defender.
+	ModifyHit func(self *BattlePokemon, hit *Hit)
+	// AfterAttack runs on the attacker once its move has dealt dmg.
+	AfterAttack func(self, target *BattlePokemon, move *pokemon.MoveInfo, dmg int) []Event
+	// EndOfTurn runs with the holder's residual effects.
+	EndOfTurn func(self *BattlePokemon) []Event
+	// OnStatus runs right after the holder gains a major status.
+	OnStatus func(self *BattlePokemon) []Event
+	// ModifySpeed scales the holder's speed when ordering the turn.
+	ModifySpeed func(self *BattlePokemon, field *Field) float64
+	// MovesFirst reports whether the holder jumps ahead of its priority
+	// bracket this turn.
+	MovesFirst func(self *BattlePokemon) bool
+	// Choice locks the holder into the first move it selects.
+	Choice bool
+	// Suits reports whether the item is a sensible pick for bp when items are
+	// handed out at random. Nil means any holder.
+	Suits func(bp *BattlePokemon) bool
+}
+
+// How a Pokémon lost its item, recorded in BattlePokemon.ItemLoss.
+const (
+	ItemConsumed   = "consumed"
+	ItemKnockedOff = "knocked-off"
+)
+
+var noItem = &Item{}
+
+var items = map[string]*Item{}
+
+func registerItem(it *Item) {
+	items[it.Name] = it
+}
+
+// LookupItem returns a held item's battle effect, or nil if it has none.
+func LookupItem(name string) *Item {
+	return items[name]
+}
+
+// ItemNames lists every held item the engine implements, sorted by name.
+func ItemNames() []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (bp *BattlePokemon) item() *Item {
+	if it, ok := items[bp.Item]; ok {
+		return it
+	}
+	return noItem
+}
+
+// LoadItemCatalogue fetches the documents for the given items from src.
+// Items the source does not hold are left out, so an older local store
+// simply means fewer items to hand out.
+func LoadItemCatalogue(ctx context.Context, src pokemon.DataSource, names []string) (map[string]*pokemon.ItemInfo, error) {
+	infos := make([]*pokemon.ItemInfo, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			infos[i], errs[i] = src.Item(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	catalogue := make(map[string]*pokemon.ItemInfo, len(names))
+	for i, name := range names {
+		if errs[i] != nil {
+			if errors.Is(errs[i], pokemon.ErrNotFound) || errors.Is(errs[i], pokemon.ErrDecode) {
+				continue
+			}
+			return nil, fmt.Errorf("loading item %s: %w", name, errs[i])
+		}
+		catalogue[name] = infos[i]
+	}
+	return catalogue, nil
+}
+
+// PickRandItem chooses a held item from catalogue that suits bp, drawing
+// from r. It returns "" when nothing fits.
+func PickRandItem(bp *BattlePokemon, catalogue map[string]*pokemon.ItemInfo, r *rand.Rand) string {
+	var candidates []string
+	for _, name := range ItemNames() {
+		if _, ok := catalogue[name]; !ok {
+			continue
+		}
+		if suits := items[name].Suits; suits != nil && !suits(bp) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[r.IntN(len(candidates))]
+}
+
+// SetItem gives the Pokémon a held item the engine knows about. An empty
+// name takes the item away.
+func (bp *BattlePokemon) SetItem(name string) error {
+	if name != "" && LookupItem(name) == nil {
+		return fmt.Errorf("unknown held item %s", name)
+	}
+	bp.Item = name
+	bp.LostItem, bp.ItemLoss = "", ""
+	bp.ChoiceLock = ""
+	return nil
+}
+
+// loseItem removes the held item for the rest of the battle and records
+// why, so it is not restored on switch-out.
+func (bp *BattlePokemon) loseItem(reason string) string {
+	lost := bp.Item
+	bp.Item = ""
+	bp.LostItem, bp.ItemLoss = lost, reason
+	bp.ChoiceLock = ""
+	return lost
+}
+
+// LockedMove returns the move a multi-turn move or a Choice item has locked
+// the Pokémon into, or "" when it may pick freely.
+func (bp *BattlePokemon) LockedMove() string {
+	if bp.ForcedMove != "" {
+		return bp.ForcedMove
+	}
+	if !bp.item().Choice {
+		return ""
+	}
+	return bp.ChoiceLock
+}
+
+func init() {
+	registerItem(&Item{
+		Name: "choice-band",
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && hit.Move.DamageClass.Name == "physical" {
+				hit.Attack *= 1.5
+			}
+		},
+		Choice: true,
+		Suits:  func(bp *BattlePokemon) bool { return countMoves(bp, "physical") >= 3 },
+	})
+
+	registerItem(&Item{
+		Name: "choice-specs",
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && hit.Move.DamageClass.Name == "special" {
+				hit.Attack *= 1.5
+			}
+		},
+		Choice: true,
+		Suits:  func(bp *BattlePokemon) bool { return countMoves(bp, "special") >= 3 },
+	})
+
+	registerItem(&Item{
+		Name: "choice-scarf",
+		ModifySpeed: func(self *BattlePokemon, field *Field) float64 {
+			return 1.5
+		},
+		Choice: true,
+		Suits: func(bp *BattlePokemon) bool {
+			return countMoves(bp, "physical")+countMoves(bp, "special") >= 3
+		},
+	})
+
+	registerItem(&Item{
+		Name: "life-orb",
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self {
+				hit.Damage *= 1.3
+			}
+		},
+		AfterAttack: func(self, target *BattlePokemon, move *pokemon.MoveInfo, dmg int) []Event {
+			if dmg <= 0 || self.Fainted || fixedDamageMove(move) {
+				return nil
+			}
+			return self.hurt(self.MaxHP()/10, "life-orb")
+		},
+		Suits: func(bp *BattlePokemon) bool {
+			return countMoves(bp, "physical")+countMoves(bp, "special") >= 2
+		},
+	})
+
+	for name, moveType := range map[string]string{
+		"silver-powder": "bug", "metal-coat": "steel", "soft-sand": "ground",
+		"hard-stone": "rock", "miracle-seed": "grass", "black-glasses": "dark",
+		"black-belt": "fighting", "magnet": "electric", "mystic-water": "water",
+		"sharp-beak": "flying", "poison-barb": "poison", "never-melt-ice": "ice",
+		"spell-tag": "ghost", "twisted-spoon": "psychic", "charcoal": "fire",
+		"dragon-fang": "dragon", "silk-scarf": "normal",
+	} {
+		registerItem(typeBoostItem(name, moveType))
+	}
+
+	registerItem(&Item{
+		Name: "leftovers",
+		EndOfTurn: func(self *BattlePokemon) []Event {
+			return self.restore(self.MaxHP()/16, "leftovers")
+		},
+	})
+
+	registerItem(&Item{
+		Name: "black-sludge",
+		EndOfTurn: func(self *BattlePokemon) []Event {
+			if hasType(self, "poison") {
+				return self.restore(self.MaxHP()/16, "black-sludge")
+			}
+			return self.hurt(self.MaxHP()/8, "black-sludge")
+		},
+		Suits: func(bp *BattlePokemon) bool { return hasType(bp, "poison") },
+	})
+
+	registerItem(statusOrb("flame-orb", "brn"))
+	registerItem(statusOrb("toxic-orb", "tox"))
+
+	registerItem(&Item{
+		Name: "lum-berry",
+		OnStatus: func(self *BattlePokemon) []Event {
+			return cureWithBerry(self, "lum-berry")
+		},
+	})
+
+	registerItem(&Item{
+		Name: "chesto-berry",
+		OnStatus: func(self *BattlePokemon) []Event {
+			if self.Status != "slp" {
+				return nil
+			}
+			return cureWithBerry(self, "chesto-berry")
+		},
+		Suits: func(bp *BattlePokemon) bool { return bp.MovePP["rest"] > 0 },
+	})
+
+	registerItem(&Item{
+		Name: "quick-claw",
+		MovesFirst: func(self *BattlePokemon) bool {
+			return self.rng().Float64() < 0.2
+		},
+	})
+}
+
+// typeBoostItem raises the power of the holder's moves of one type by a
+// fifth (Charcoal, Mystic Water and friends).
+func typeBoostItem(name, moveType string) *Item {
+	return &Item{
+		Name: name,
+		ModifyHit: func(self *BattlePokemon, hit *Hit) {
+			if hit.Attacker == self && hit.Move.Type.Name == moveType {
+				hit.Power *= 1.2
+			}
+		},
+		Suits: func(bp *BattlePokemon) bool {
+			for _, m := range bp.Moves {
+				if m != nil && m.Power > 0 && m.Type.Name == moveType {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// statusOrb inflicts status on the holder at the end of every turn unless
+// one of its types is immune. Only Guts users are handed one at random.
+func statusOrb(name, status string) *Item {
+	return &Item{
+		Name: name,
+		EndOfTurn: func(self *BattlePokemon) []Event {
+			if self.Status != "" || statusImmune(self, status) {
+				return nil
+			}
+			return self.inflictStatus(status, name, "")
+		},
+		Suits: func(bp *BattlePokemon) bool { return bp.Ability == "guts" },
+	}
+}
+
+func cureWithBerry(self *BattlePokemon, berry string) []Event {
+	if self.Status == "" {
+		return nil
+	}
+	status := self.Status
+	self.Status = ""
+	self.StatusTurns = 0
+	self.loseItem(ItemConsumed)
+	return []Event{StatusCured{Pokemon: self.Base.Name, Side: self.side, Status: status, Cause: berry}}
+}
+
+// knockOff removes the defender's item after Knock Off connects.
+func knockOff(attacker, defender *BattlePokemon, move *pokemon.MoveInfo) []Event {
+	if move.Name != "knock-off" || defender.Item == "" {
+		return nil
+	}
+	lost := defender.loseItem(ItemKnockedOff)
+	return []Event{ItemRemoved{Pokemon: defender.Base.Name, Side: defender.side, Item: lost, By: attacker.Base.Name}}
+}
+
+func countMoves(bp *BattlePokemon, damageClass string) int {
+	n := 0
+	for _, m := range bp.Moves {
+		if m != nil && m.Power > 0 && m.DamageClass.Name == damageClass {
+			n++
+		}
+	}
+	return n
+}
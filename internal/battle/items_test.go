@@ -0,0 +1,107 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func holding(t *testing.T, bp *battle.BattlePokemon, item string) *battle.BattlePokemon {
+	t.Helper()
+	if err := bp.SetItem(item); err != nil {
+		t.Fatalf("SetItem: %v", err)
+	}
+	return bp
+}
+
+func TestChoiceBandLocksFirstMove(t *testing.T) {
+	machamp := holding(t, newBattler(t, "machamp", "guts", "tackle", "knock-off"), "choice-band")
+	snorlax := newBattler(t, "snorlax", "")
+
+	battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if got := machamp.LockedMove(); got != "tackle" {
+		t.Fatalf("Expected Choice Band to lock into tackle, got %q", got)
+	}
+
+	pp := machamp.MovePP["knock-off"]
+	events := battle.Act(machamp, snorlax, machamp.Moves[1], nil)
+	if machamp.MovePP["knock-off"] != pp {
+		t.Errorf("Locked Pokémon spent PP on another move")
+	}
+	if len(events) == 0 || events[len(events)-1] != (battle.CantMove{Pokemon: "machamp", Reason: "locked", Move: "tackle"}) {
+		t.Errorf("Expected a lock message, got %v", events)
+	}
+
+	battle.SwitchIn(machamp, snorlax, nil)
+	if got := machamp.LockedMove(); got != "" {
+		t.Errorf("Expected switching out to clear the lock, still locked into %q", got)
+	}
+}
+
+func TestLeftoversHealsAtEndOfTurn(t *testing.T) {
+	snorlax := holding(t, newBattler(t, "snorlax", ""), "leftovers")
+	maxHP := battle.GetPokemonFullView(snorlax).MaxHP
+	snorlax.ApplyDamage(maxHP / 2)
+
+	events := snorlax.HandleTurnEffects()
+	if want := maxHP/2 + maxHP/16; snorlax.CurrentHP != want {
+		t.Errorf("Expected Leftovers to restore HP to %.1f, got %.1f", want, snorlax.CurrentHP)
+	}
+	if len(events) != 1 || events[0].(battle.Heal).Cause != "leftovers" {
+		t.Errorf("Expected a Leftovers message, got %v", events)
+	}
+}
+
+func TestLumBerryCuresAndIsConsumed(t *testing.T) {
+	machamp := holding(t, newBattler(t, "machamp", "guts", "tackle"), "lum-berry")
+	pikachu := newBattler(t, "pikachu", "static")
+
+	for i := 0; i < 200 && machamp.Item != ""; i++ {
+		pikachu.CurrentHP = battle.GetPokemonFullView(pikachu).MaxHP
+		machamp.MovePP["tackle"] = 35
+		battle.Act(machamp, pikachu, machamp.Moves[0], nil)
+	}
+	if machamp.Item != "" {
+		t.Fatal("Static never triggered the Lum Berry")
+	}
+	if machamp.Status != "" {
+		t.Errorf("Expected Lum Berry to cure paralysis, status is %q", machamp.Status)
+	}
+	if machamp.LostItem != "lum-berry" || machamp.ItemLoss != battle.ItemConsumed {
+		t.Errorf("Expected the berry to be recorded as consumed, got %q (%q)", machamp.LostItem, machamp.ItemLoss)
+	}
+}
+
+func TestKnockOffRemovesItem(t *testing.T) {
+	machamp := newBattler(t, "machamp", "guts", "knock-off")
+	snorlax := holding(t, newBattler(t, "snorlax", ""), "leftovers")
+
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if snorlax.Item != "" {
+		t.Fatalf("Expected Knock Off to remove Leftovers, events: %v", events)
+	}
+	if snorlax.LostItem != "leftovers" || snorlax.ItemLoss != battle.ItemKnockedOff {
+		t.Errorf("Expected Leftovers to be recorded as knocked off, got %q (%q)", snorlax.LostItem, snorlax.ItemLoss)
+	}
+	before := snorlax.CurrentHP
+	snorlax.HandleTurnEffects()
+	if snorlax.CurrentHP != before {
+		t.Errorf("Knocked-off Leftovers still healed")
+	}
+}
+
+func TestLifeOrbSparesFixedDamageMoves(t *testing.T) {
+	machamp := holding(t, newBattler(t, "machamp", "guts", "seismic-toss", "body-slam"), "life-orb")
+	snorlax := newBattler(t, "snorlax", "")
+	maxHP := battle.GetPokemonFullView(machamp).MaxHP
+
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if machamp.CurrentHP != maxHP {
+		t.Errorf("Expected Seismic Toss to take no Life Orb recoil, HP is %.0f of %.0f: %v", machamp.CurrentHP, maxHP, events)
+	}
+
+	events = battle.Act(machamp, snorlax, machamp.Moves[1], nil)
+	if want := maxHP - maxHP/10; machamp.CurrentHP != want {
+		t.Errorf("Expected Body Slam to cost a tenth of Machamp's HP, HP is %.0f of %.0f: %v", machamp.CurrentHP, maxHP, events)
+	}
+}
@@ -0,0 +1,382 @@
+package battle
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+	"github.com/ross1116/pokebattlecli/internal/stats"
+)
+
+// SetPool is what the species of a squad might bring to battle: every move
+// each species can learn that the engine supports, by species, and the
+// items held items are dealt from. MCTSAgent deals the foe's hidden sets
+// from it.
+type SetPool struct {
+	Moves map[string][]*pokemon.MoveInfo
+	Items map[string]*pokemon.ItemInfo
+}
+
+// poolFetchLimit caps how many move documents LoadSetPool fetches at once.
+const poolFetchLimit = 16
+
+// LoadSetPool fetches the learnable moves of every species in squad, as
+// FilterMoveByLearn lists them, and the item catalogue. Moves the source
+// cannot provide are left out, as they are when movesets are dealt.
+func LoadSetPool(ctx context.Context, src pokemon.DataSource, squad []*BattlePokemon) (*SetPool, error) {
+	catalogue, err := LoadItemCatalogue(ctx, src, ItemNames())
+	if err != nil {
+		return nil, err
+	}
+	pool := &SetPool{Moves: map[string][]*pokemon.MoveInfo{}, Items: catalogue}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	semaphore := make(chan struct{}, poolFetchLimit)
+	for _, bp := range squad {
+		if bp == nil || bp.Base == nil {
+			continue
+		}
+		species := bp.Base.Name
+		if _, ok := pool.Moves[species]; ok {
+			continue
+		}
+		pool.Moves[species] = nil
+		seen := map[string]bool{}
+		for _, move := range pokemon.FilterMoveByLearn(bp.Base) {
+			if seen[move.Name] {
+				continue
+			}
+			seen[move.Name] = true
+			semaphore <- struct{}{}
+			wg.Add(1)
+			go func(url string) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				info, err := src.MoveByURL(ctx, url)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case errors.Is(err, pokemon.ErrNotFound) || errors.Is(err, pokemon.ErrDecode):
+				case err != nil:
+					if firstErr == nil {
+						firstErr = fmt.Errorf("loading moves for %s: %w", species, err)
+					}
+				case MoveSupported(info):
+					pool.Moves[species] = append(pool.Moves[species], info)
+				}
+			}(move.URL)
+		}
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// Fetches finish in any order; sort so a seeded agent deals the same
+	// sets every time.
+	for _, moves := range pool.Moves {
+		slices.SortFunc(moves, func(a, b *pokemon.MoveInfo) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+	return pool, nil
+}
+
+// defaultIterations is how many playouts MCTSAgent runs per decision when
+// it is given no budget.
+const defaultIterations = 500
+
+// defaultPlayoutTurns is how many turns a playout runs before the position
+// is scored as it stands.
+const defaultPlayoutTurns = 20
+
+// explorationWeight balances trying rarely played actions against replaying
+// the best so far in the tree search.
+const explorationWeight = 0.7
+
+// MCTSAgent decides by Monte Carlo tree search. Each iteration deals the
+// foe a set it could have, clones the battle onto a fresh seed, walks down
+// the tree picking both sides' actions by their upper confidence bound, and
+// plays the rest out with GreedyAgent on both sides before scoring the
+// result. Both sides keep separate statistics in every node, since they
+// choose at the same time.
+type MCTSAgent struct {
+	// Pool is where the foe's unrevealed moves, its ability and its item are
+	// drawn from. Without it, or for species it lacks, the foe keeps only
+	// what it has revealed.
+	Pool *SetPool
+	// Iterations caps the playouts per decision and Budget the time spent on
+	// one. Whichever runs out first ends the search; with neither set it
+	// runs defaultIterations playouts.
+	Iterations int
+	Budget     time.Duration
+	// PlayoutTurns caps how many turns each playout runs, defaultPlayoutTurns
+	// if unset.
+	PlayoutTurns int
+	// Rand deals the foe's sets and seeds every playout, so a seeded agent
+	// with an iteration budget always decides the same way. Without it the
+	// agent forks the battle's generator.
+	Rand *rand.Rand
+}
+
+// mctsArm holds one action's statistics in a node, for the side choosing
+// it. Available counts the iterations it could have been picked in, since
+// the foe's options change with the set it is dealt.
+type mctsArm struct {
+	visits    int
+	available int
+	reward    float64
+}
+
+// mctsNode is a position in the search tree, reached by the actions both
+// sides played to get there rather than by the exact state, which the dice
+// and the foe's dealt sets change from one iteration to the next.
+type mctsNode struct {
+	arms     [2]map[string]*mctsArm
+	children map[[2]string]*mctsNode
+}
+
+func newMCTSNode() *mctsNode {
+	return &mctsNode{
+		arms:     [2]map[string]*mctsArm{{}, {}},
+		children: map[[2]string]*mctsNode{},
+	}
+}
+
+func (a MCTSAgent) Choose(v View) Action {
+	req := v.Request
+	actions := legalActions(req)
+	if len(actions) == 0 {
+		return req.DefaultAction()
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+
+	iterations, deadline := a.Iterations, time.Time{}
+	if a.Budget > 0 {
+		deadline = time.Now().Add(a.Budget)
+	} else if iterations <= 0 {
+		iterations = defaultIterations
+	}
+	r := a.Rand
+	if r == nil {
+		r = forkRand(v.Battle.Field.Rand())
+	}
+
+	root := newMCTSNode()
+	for i := 0; iterations <= 0 || i < iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		world := v.Battle.Clone(r.Uint64())
+		world.actions = [2]*Action{}
+		a.determinize(world, 1-v.Side, r)
+		// A playout the engine rejects teaches nothing; the next one is
+		// dealt afresh.
+		_ = a.iterate(root, world, v.Side)
+	}
+
+	best, bestVisits := actions[0], -1
+	for _, action := range actions {
+		if arm := root.arms[v.Side][actionLabel(req, action)]; arm != nil && arm.visits > bestVisits {
+			best, bestVisits = action, arm.visits
+		}
+	}
+	return best
+}
+
+// iterate runs one iteration of the search from root on world, a
+// determinized copy of the battle, and scores it for side.
+func (a MCTSAgent) iterate(root *mctsNode, world *Battle, side int) error {
+	type visit struct {
+		node   *mctsNode
+		labels [2]string
+	}
+	var path []visit
+	node := root
+	for node != nil && !world.Over() {
+		var labels [2]string
+		for s := range labels {
+			req := world.RequestsFor(s)
+			if req == nil {
+				continue
+			}
+			action := node.pick(s, req)
+			labels[s] = actionLabel(req, action)
+			if err := world.Submit(s, action); err != nil {
+				return err
+			}
+		}
+		if _, _, err := world.Step(); err != nil {
+			return err
+		}
+		path = append(path, visit{node, labels})
+		child, ok := node.children[labels]
+		if !ok {
+			node.children[labels] = newMCTSNode()
+		}
+		node = child
+	}
+
+	turns := a.PlayoutTurns
+	if turns <= 0 {
+		turns = defaultPlayoutTurns
+	}
+	if err := play(world, [2]Agent{GreedyAgent{}, GreedyAgent{}}, turns, fullView); err != nil {
+		return err
+	}
+
+	value := outcome(world, side)
+	for _, step := range path {
+		for s, label := range step.labels {
+			if label == "" {
+				continue
+			}
+			arm := step.node.arms[s][label]
+			arm.visits++
+			if s == side {
+				arm.reward += value
+			} else {
+				arm.reward += 1 - value
+			}
+		}
+	}
+	return nil
+}
+
+// pick chooses side's action at n by its upper confidence bound, trying
+// every action once first.
+func (n *mctsNode) pick(side int, req *Request) Action {
+	actions := legalActions(req)
+	if len(actions) == 0 {
+		return req.DefaultAction()
+	}
+	arms := make([]*mctsArm, len(actions))
+	for i, action := range actions {
+		label := actionLabel(req, action)
+		arm := n.arms[side][label]
+		if arm == nil {
+			arm = &mctsArm{}
+			n.arms[side][label] = arm
+		}
+		arm.available++
+		arms[i] = arm
+	}
+	best, bestScore := 0, math.Inf(-1)
+	for i, arm := range arms {
+		if arm.visits == 0 {
+			return actions[i]
+		}
+		score := arm.reward/float64(arm.visits) + explorationWeight*math.Sqrt(math.Log(float64(arm.available))/float64(arm.visits))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return actions[best]
+}
+
+// actionLabel names action the same way in every determinization: moves by
+// name, since the foe's move options change with the set it is dealt.
+func actionLabel(req *Request, action Action) string {
+	if action.Kind == ActionSwitch {
+		return "switch:" + strconv.Itoa(action.Switch)
+	}
+	return "move:" + req.Moves[action.Move].Name
+}
+
+// fullView shows side all of b, for playouts on a world the agent dealt
+// itself.
+func fullView(b *Battle, side int, req *Request) View {
+	return View{Battle: b, Side: side, Request: req}
+}
+
+// outcome scores b for side from 0 for a loss to 1 for a win. Unfinished
+// battles score by the share of HP and members each side has left.
+func outcome(b *Battle, side int) float64 {
+	switch {
+	case b.Lost(side) && b.Lost(1-side):
+		return 0.5
+	case b.Lost(1 - side):
+		return 1
+	case b.Lost(side):
+		return 0
+	}
+	health := func(s int) float64 {
+		total := 0.0
+		for _, bp := range b.Team(s) {
+			if bp != nil && !bp.Fainted && bp.MaxHP() > 0 {
+				total += 0.5 + 0.5*bp.CurrentHP/bp.MaxHP()
+			}
+		}
+		return total
+	}
+	size := float64(max(len(b.Team(0)), len(b.Team(1)), 1))
+	return 0.5 + (health(side)-health(1-side))/(2*size)
+}
+
+// determinize deals side's squad in world sets it could have: each member
+// keeps the moves it has revealed and draws the rest of a full moveset from
+// its species' learnable moves, a spread from the format's, and whichever
+// of its ability and item it has not revealed. A member seen losing its
+// item is known to hold none.
+func (a MCTSAgent) determinize(world *Battle, side int, r *rand.Rand) {
+	if a.Pool == nil {
+		return
+	}
+	for i, bp := range world.Team(side) {
+		if bp == nil || bp.Base == nil {
+			continue
+		}
+		learnable := a.Pool.Moves[bp.Base.Name]
+		if len(learnable) == 0 {
+			continue
+		}
+
+		var moves []*pokemon.MoveInfo
+		pp := map[string]int{}
+		for _, name := range world.Revealed(side, i) {
+			if j := slices.IndexFunc(bp.Moves, func(m *pokemon.MoveInfo) bool { return m.Name == name }); j >= 0 {
+				moves = append(moves, bp.Moves[j])
+				pp[name] = bp.MovePP[name]
+			}
+		}
+		for _, j := range r.Perm(len(learnable)) {
+			if len(moves) == 4 {
+				break
+			}
+			if move := learnable[j]; !dealt(pp, move.Name) {
+				moves = append(moves, move)
+				pp[move.Name] = move.Pp
+			}
+		}
+		bp.Moves, bp.MovePP = moves, pp
+
+		spread := world.Field.format.spreadFor(bp.Base, r)
+		bp.Spread, bp.Stats = spread, stats.Compute(bp.Base, spread)
+
+		set := world.revealedSet(side, i)
+		if !set.ability {
+			bp.Ability = pokemon.PickRandAbility(bp.Base, r)
+		}
+		if !set.item && bp.LostItem == "" {
+			bp.Item = PickRandItem(bp, a.Pool.Items, r)
+		}
+	}
+}
+
+func dealt(pp map[string]int, name string) bool {
+	_, ok := pp[name]
+	return ok
+}
@@ -0,0 +1,126 @@
+package battle_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestCloneLeavesTheBattleUntouched(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat", "bulk-up")
+	snorlax := newBattler(t, "snorlax", "", "body-slam")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{snorlax})
+	b.Start()
+
+	clone := b.Clone(99)
+	if err := battle.Play(clone, [2]battle.Agent{battle.GreedyAgent{}, battle.GreedyAgent{}}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if clone.Turn == b.Turn {
+		t.Fatal("Expected the clone to have played on")
+	}
+	if b.Turn != 1 || machamp.CurrentHP != machamp.MaxHP() || snorlax.CurrentHP != snorlax.MaxHP() {
+		t.Errorf("Expected the battle to be untouched, got turn %d with %.0f and %.0f HP", b.Turn, machamp.CurrentHP, snorlax.CurrentHP)
+	}
+	if machamp.MovePP["close-combat"] != machamp.Moves[0].Pp || len(b.Revealed(0, 0)) != 0 {
+		t.Errorf("Expected Machamp's PP and revealed moves to be untouched, got %v and %v", machamp.MovePP, b.Revealed(0, 0))
+	}
+	if got := clone.Revealed(0, 0); !slices.Equal(got, []string{"close-combat"}) {
+		t.Errorf("Expected the clone to reveal Close Combat, got %v", got)
+	}
+}
+
+func TestLoadSetPoolListsLearnableMoves(t *testing.T) {
+	machamp := newBattler(t, "machamp", "")
+	pool, err := battle.LoadSetPool(context.Background(), pokemon.BundledSource(), []*battle.BattlePokemon{machamp, machamp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := pool.Moves["machamp"]
+	if len(moves) == 0 {
+		t.Fatal("Expected Machamp to have learnable moves in the bundled data")
+	}
+	learnable := map[string]bool{}
+	for _, move := range pokemon.FilterMoveByLearn(machamp.Base) {
+		learnable[move.Name] = true
+	}
+	for i, move := range moves {
+		if !learnable[move.Name] || !battle.MoveSupported(move) {
+			t.Errorf("Expected only supported learnable moves, got %s", move.Name)
+		}
+		if i > 0 && moves[i-1].Name >= move.Name {
+			t.Errorf("Expected moves sorted once each, got %s after %s", move.Name, moves[i-1].Name)
+		}
+	}
+	if len(pool.Items) == 0 {
+		t.Error("Expected the item catalogue to be loaded")
+	}
+}
+
+func TestMCTSUsesPriorityToFinishFasterFoes(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "thunder", "quick-attack")
+	alakazam := newBattler(t, "alakazam", "", "psychic")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{alakazam})
+	b.Start()
+	machamp.CurrentHP, alakazam.CurrentHP = 1, 1
+	pool, err := battle.LoadSetPool(context.Background(), pokemon.BundledSource(), b.Team(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent := battle.MCTSAgent{Pool: pool, Iterations: 200, Rand: battle.NewRand(3)}
+	if action := choose(t, agent, b, 0); action != battle.MoveAction(1) {
+		t.Errorf("Expected Quick Attack to strike before Alakazam, got %+v", action)
+	}
+}
+
+func TestMCTSDealsTheFoeHiddenSets(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	gengar := newBattler(t, "gengar", "", "shadow-ball", "sludge-bomb")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{gengar})
+	b.Start()
+	pool, err := battle.LoadSetPool(context.Background(), pokemon.BundledSource(), b.Team(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agent := battle.MCTSAgent{Pool: pool, Iterations: 50, Rand: battle.NewRand(5)}
+	first := choose(t, agent, b, 0)
+	agent.Rand = battle.NewRand(5)
+	if again := choose(t, agent, b, 0); again != first {
+		t.Errorf("Expected a seeded search to repeat its choice, got %+v then %+v", first, again)
+	}
+	if len(gengar.Moves) != 2 || gengar.MovePP["shadow-ball"] != gengar.Moves[0].Pp {
+		t.Errorf("Expected the search to leave Gengar's real set alone, got %v", gengar.MovePP)
+	}
+}
+
+func TestMCTSWithoutAPoolPlaysWhatTheFoeRevealed(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "close-combat", "quick-attack")
+	gengar := newBattler(t, "gengar", "", "shadow-ball", "sludge-bomb")
+	b := newBattle([]*battle.BattlePokemon{machamp}, []*battle.BattlePokemon{gengar})
+	b.Start()
+
+	agent := battle.MCTSAgent{Iterations: 20, Rand: battle.NewRand(5)}
+	if action := choose(t, agent, b, 0); action.Kind != battle.ActionMove {
+		t.Errorf("Expected a move without a set pool, got %+v", action)
+	}
+}
+
+func TestMasterNeedsASetPool(t *testing.T) {
+	if needs, err := battle.NeedsSetPool("master"); err != nil || !needs {
+		t.Fatalf("Expected master to need a set pool, got %v, %v", needs, err)
+	}
+	if _, err := battle.LookupAgent("master", battle.NewRand(1), nil); err == nil {
+		t.Error("Expected master without a set pool to be refused")
+	}
+	if _, err := battle.LookupAgent("master", battle.NewRand(1), &battle.SetPool{}); err != nil {
+		t.Errorf("Expected master with a set pool, got %v", err)
+	}
+	if _, err := battle.NeedsSetPool("grandmaster"); err == nil {
+		t.Error("Expected an unknown difficulty to be refused")
+	}
+}
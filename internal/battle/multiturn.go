@@ -0,0 +1,236 @@
+package battle
+
+import (
+	"math/rand/v2"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// chargeMove describes a move that spends its first turn charging and
+// strikes on the next.
+type chargeMove struct {
+	// hidden is the semi-invulnerable state the user hides in while it
+	// charges, if any.
+	hidden string
+	// weather lets the move strike without charging.
+	weather string
+}
+
+var chargeMoves = map[string]chargeMove{
+	"solar-beam":    {weather: "sun"},
+	"solar-blade":   {weather: "sun"},
+	"sky-attack":    {},
+	"razor-wind":    {},
+	"skull-bash":    {},
+	"meteor-beam":   {},
+	"fly":           {hidden: "in-the-air"},
+	"bounce":        {hidden: "in-the-air"},
+	"dig":           {hidden: "underground"},
+	"dive":          {hidden: "underwater"},
+	"phantom-force": {hidden: "vanished"},
+	"shadow-force":  {hidden: "vanished"},
+}
+
+// hiddenReach lists, for each semi-invulnerable state, the moves that can
+// still hit a Pokémon in it and whether they hit with double power.
+var hiddenReach = map[string]map[string]bool{
+	"in-the-air": {
+		"gust": true, "twister": true,
+		"thunder": false, "hurricane": false, "sky-uppercut": false, "smack-down": false,
+	},
+	"underground": {"earthquake": true, "magnitude": true, "fissure": false},
+	"underwater":  {"surf": true, "whirlpool": true},
+	"vanished":    {},
+}
+
+// rechargeMoves leave the user unable to act on the turn after they hit.
+var rechargeMoves = map[string]bool{
+	"hyper-beam":      true,
+	"giga-impact":     true,
+	"blast-burn":      true,
+	"frenzy-plant":    true,
+	"hydro-cannon":    true,
+	"rock-wrecker":    true,
+	"roar-of-time":    true,
+	"prismatic-laser": true,
+	"eternabeam":      true,
+}
+
+// rampageMoves lock the user in for the turns in the move's meta and
+// confuse it once the rampage ends.
+var rampageMoves = map[string]bool{
+	"outrage":     true,
+	"thrash":      true,
+	"petal-dance": true,
+	"raging-fury": true,
+}
+
+// LockedIn reports whether bp is in the middle of a multi-turn move and so
+// can neither pick another move nor switch out.
+func (bp *BattlePokemon) LockedIn() bool {
+	return bp.ForcedMove != "" && !bp.Fainted
+}
+
+// hiddenState returns the semi-invulnerable state bp is in, or "".
+func (bp *BattlePokemon) hiddenState() string {
+	for state := range hiddenReach {
+		if bp.Volatile.Has(state) {
+			return state
+		}
+	}
+	return ""
+}
+
+// outOfReach reports whether defender is hidden from move by a charge move.
+func outOfReach(defender *BattlePokemon, move *pokemon.MoveInfo) bool {
+	hidden := defender.hiddenState()
+	if hidden == "" {
+		return false
+	}
+	_, reaches := hiddenReach[hidden][move.Name]
+	return !reaches
+}
+
+// hiddenModifyHit doubles the power of moves that reach into the defender's
+// semi-invulnerable state, such as Earthquake against Dig.
+func hiddenModifyHit(hit *Hit) {
+	if hidden := hit.Defender.hiddenState(); hidden != "" && hiddenReach[hidden][hit.Move.Name] {
+		hit.Power *= 2
+	}
+}
+
+// rechargeTurn spends bp's action recharging if it used a recharge move
+// last turn.
+func rechargeTurn(bp *BattlePokemon) ([]Event, bool) {
+	if !bp.Volatile.Has("recharge") {
+		return nil, false
+	}
+	bp.RemoveVolatileEffect("recharge")
+	bp.ForcedMove = ""
+	return []Event{CantMove{Pokemon: bp.Base.Name, Side: bp.side, Reason: "recharge"}}, true
+}
+
+// startCharge begins a charge move's first turn. It reports false when the
+// move strikes this turn instead: because the user already charged or the
+// weather lets it skip charging.
+func startCharge(bp *BattlePokemon, move *pokemon.MoveInfo, field *Field) ([]Event, bool) {
+	charge, ok := chargeMoves[move.Name]
+	if !ok {
+		return nil, false
+	}
+	if bp.Volatile.Has("charging") {
+		bp.RemoveVolatileEffect("charging")
+		if charge.hidden != "" {
+			bp.RemoveVolatileEffect(charge.hidden)
+		}
+		bp.ForcedMove = ""
+		return nil, false
+	}
+	events := []Event{Activate{Pokemon: bp.Base.Name, Side: bp.side, Effect: move.Name}}
+	if charge.weather != "" && field.weather() == charge.weather {
+		return events, false
+	}
+	bp.setVolatile("charging", move.Name, 1)
+	if charge.hidden != "" {
+		bp.setVolatile(charge.hidden, move.Name, 1)
+	}
+	bp.ForcedMove = move.Name
+	if move.Name == "skull-bash" {
+		events = append(events, bp.changeStage("defense", 1)...)
+	}
+	if move.Name == "meteor-beam" {
+		events = append(events, bp.changeStage("special-attack", 1)...)
+	}
+	return events, true
+}
+
+// finishMove updates bp's multi-turn state once move has been used: a
+// recharge move that landed costs the next turn, and a rampage counts down,
+// confusing the user when it runs its course. A rampage that fails to land
+// ends without confusion.
+func finishMove(bp *BattlePokemon, move *pokemon.MoveInfo, landed bool, field *Field) []Event {
+	if bp.Fainted {
+		interruptMove(bp)
+		return nil
+	}
+	if rechargeMoves[move.Name] && landed {
+		bp.setVolatile("recharge", move.Name, 1)
+		bp.ForcedMove = move.Name
+		return nil
+	}
+	if !rampageMoves[move.Name] {
+		return nil
+	}
+	if !landed {
+		interruptMove(bp)
+		return nil
+	}
+	rampage := bp.Volatile["rampage"]
+	if rampage == nil {
+		rampage = bp.setVolatile("rampage", move.Name, rampageTurns(move, bp.rng()))
+		bp.ForcedMove = move.Name
+	}
+	rampage.Turns--
+	if rampage.Turns > 0 {
+		return nil
+	}
+	interruptMove(bp)
+	if bp.Volatile.Has("confusion") || terrainBlocksStatus(field, bp, "") {
+		return nil
+	}
+	bp.setVolatile("confusion", move.Name, confusionTurns(bp))
+	return []Event{StatusApplied{Pokemon: bp.Base.Name, Side: bp.side, Status: "confusion", Cause: "fatigue"}}
+}
+
+// interruptMove clears bp's multi-turn move state.
+func interruptMove(bp *BattlePokemon) {
+	if hidden := bp.hiddenState(); hidden != "" {
+		bp.RemoveVolatileEffect(hidden)
+	}
+	for _, effect := range []string{"charging", "recharge", "rampage"} {
+		bp.RemoveVolatileEffect(effect)
+	}
+	bp.ForcedMove = ""
+}
+
+func rampageTurns(move *pokemon.MoveInfo, r *rand.Rand) int {
+	lo, hi := max(move.Meta.MinTurns, 2), max(move.Meta.MaxTurns, 3)
+	return lo + r.IntN(hi-lo+1)
+}
+
+// expectedHits is how many times a multi-hit move strikes on average, by
+// the odds hitCount rolls.
+func expectedHits(move *pokemon.MoveInfo) float64 {
+	lo, hi := move.Meta.MinHits, move.Meta.MaxHits
+	switch {
+	case hi <= 1:
+		return 1
+	case lo == 2 && hi == 5:
+		return 3.1
+	}
+	return float64(max(lo, 1)+hi) / 2
+}
+
+// hitCount rolls how many times a multi-hit move strikes. Moves that hit two
+// to five times do so two or three times 35% of the time each and four or
+// five times 15% of the time each.
+func hitCount(move *pokemon.MoveInfo, r *rand.Rand) int {
+	lo, hi := move.Meta.MinHits, move.Meta.MaxHits
+	if hi <= 1 {
+		return 1
+	}
+	if lo == 2 && hi == 5 {
+		switch n := r.IntN(100); {
+		case n < 35:
+			return 2
+		case n < 70:
+			return 3
+		case n < 85:
+			return 4
+		default:
+			return 5
+		}
+	}
+	lo = max(lo, 1)
+	return lo + r.IntN(hi-lo+1)
+}
@@ -0,0 +1,166 @@
+package battle_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func hasEvent(events []battle.Event, substr string) bool {
+	return slices.ContainsFunc(battle.Render(events), func(e string) bool { return strings.Contains(e, substr) })
+}
+
+func TestSolarBeamChargesThenStrikes(t *testing.T) {
+	venusaur := newBattler(t, "venusaur", "", "solar-beam")
+	snorlax := newBattler(t, "snorlax", "")
+	beam := venusaur.Moves[0]
+	pp := venusaur.MovePP[beam.Name]
+
+	events := battle.Act(venusaur, snorlax, beam, nil)
+	if !hasEvent(events, "absorbed light") || snorlax.CurrentHP != snorlax.MaxHP() {
+		t.Fatalf("Expected Solar Beam to spend its first turn charging, got %v", events)
+	}
+	if !venusaur.LockedIn() || venusaur.LockedMove() != beam.Name {
+		t.Fatalf("Expected Venusaur to be locked into Solar Beam, got %q", venusaur.LockedMove())
+	}
+
+	events = battle.Act(venusaur, snorlax, beam, nil)
+	if snorlax.CurrentHP == snorlax.MaxHP() {
+		t.Errorf("Expected Solar Beam to strike on its second turn, got %v", events)
+	}
+	if venusaur.LockedIn() {
+		t.Error("Expected the lock to end once Solar Beam struck")
+	}
+	if venusaur.MovePP[beam.Name] != pp-1 {
+		t.Errorf("Expected Solar Beam to cost 1 PP over both turns, PP went from %d to %d", pp, venusaur.MovePP[beam.Name])
+	}
+}
+
+func TestSolarBeamSkipsChargingInSun(t *testing.T) {
+	venusaur := newBattler(t, "venusaur", "", "solar-beam")
+	snorlax := newBattler(t, "snorlax", "")
+
+	events := battle.Act(venusaur, snorlax, venusaur.Moves[0], &battle.Field{Weather: "sun"})
+	if snorlax.CurrentHP == snorlax.MaxHP() || venusaur.LockedIn() {
+		t.Errorf("Expected Solar Beam to strike at once in sun, got %v", events)
+	}
+}
+
+func TestFlyDodgesAttacksWhileAirborne(t *testing.T) {
+	charizard := newBattler(t, "charizard", "", "fly")
+	machamp := newBattler(t, "machamp", "", "double-kick")
+
+	battle.Act(charizard, machamp, charizard.Moves[0], nil)
+	events := battle.Act(machamp, charizard, machamp.Moves[0], nil)
+	if !hasEvent(events, "missed") || charizard.CurrentHP != charizard.MaxHP() {
+		t.Errorf("Expected Double Kick to miss Charizard in the air, got %v", events)
+	}
+	if view := battle.GetPokemonLimitedView(charizard); !view.Volatile["in-the-air"] {
+		t.Errorf("Expected the opponent to see Charizard in the air, got %v", view.Volatile)
+	}
+}
+
+func TestEarthquakeHitsDigWithDoublePower(t *testing.T) {
+	swampert := newBattler(t, "swampert", "", "dig")
+	golem := newBattler(t, "golem", "", "earthquake")
+	quake := golem.Moves[0]
+
+	average := func() float64 {
+		total := 0
+		for range 300 {
+			dmg, _, _ := battle.DamageCalc(golem, swampert, quake, nil)
+			total += dmg
+		}
+		return float64(total) / 300
+	}
+	surface := average()
+	battle.Act(swampert, golem, swampert.Moves[0], nil)
+	if !swampert.Volatile.Has("underground") {
+		t.Fatalf("Expected Swampert to be underground, got %v", swampert.Volatile)
+	}
+	if ratio := average() / surface; ratio < 1.8 || ratio > 2.2 {
+		t.Errorf("Expected Earthquake to double against Dig, got %.2f", ratio)
+	}
+}
+
+func TestHyperBeamMustRecharge(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "hyper-beam")
+	chansey := newBattler(t, "chansey", "")
+	beam := *snorlax.Moves[0]
+	beam.Accuracy = 0
+
+	battle.Act(snorlax, chansey, &beam, nil)
+	if !snorlax.LockedIn() {
+		t.Fatal("Expected Snorlax to be locked in after Hyper Beam landed")
+	}
+	hp := chansey.CurrentHP
+	events := battle.Act(snorlax, chansey, &beam, nil)
+	if !hasEvent(events, "must recharge") || chansey.CurrentHP != hp {
+		t.Errorf("Expected Snorlax to spend the turn recharging, got %v", events)
+	}
+	if snorlax.LockedIn() {
+		t.Error("Expected the recharge to end after one turn")
+	}
+}
+
+func TestOutrageLocksInThenConfuses(t *testing.T) {
+	for range 20 {
+		snorlax := newBattler(t, "snorlax", "", "outrage")
+		blastoise := newBattler(t, "blastoise", "")
+		outrage := snorlax.Moves[0]
+		pp := snorlax.MovePP[outrage.Name]
+
+		turns := 0
+		for {
+			blastoise.CurrentHP = blastoise.MaxHP()
+			battle.Act(snorlax, blastoise, outrage, nil)
+			turns++
+			if !snorlax.LockedIn() || turns > 3 {
+				break
+			}
+		}
+		if turns < 2 || turns > 3 {
+			t.Fatalf("Expected Outrage to last 2-3 turns, lasted %d", turns)
+		}
+		if !snorlax.Volatile.Has("confusion") {
+			t.Fatal("Expected Snorlax to be confused after its rampage")
+		}
+		if snorlax.MovePP[outrage.Name] != pp-1 {
+			t.Fatalf("Expected the whole rampage to cost 1 PP, PP went from %d to %d", pp, snorlax.MovePP[outrage.Name])
+		}
+	}
+}
+
+func TestMultiHitMovesReportEachHit(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "double-kick")
+	snorlax := newBattler(t, "snorlax", "")
+
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if !hasEvent(events, "missed") {
+		damage := slices.DeleteFunc(slices.Clone(events), func(e battle.Event) bool { _, ok := e.(battle.Damage); return !ok })
+		if len(damage) != 2 || !slices.Contains(events, battle.Event(battle.HitCount{Hits: 2})) {
+			t.Errorf("Expected Double Kick to hit exactly twice, got %v", events)
+		}
+	}
+
+	venusaur := newBattler(t, "venusaur", "", "bullet-seed")
+	seen := make(map[int]bool)
+	for range 200 {
+		snorlax.CurrentHP = snorlax.MaxHP()
+		events := battle.Act(venusaur, snorlax, venusaur.Moves[0], nil)
+		venusaur.MovePP["bullet-seed"] = 30
+		for hits := 2; hits <= 5; hits++ {
+			if hasEvent(events, fmt.Sprintf("Hit %d time(s)!", hits)) {
+				seen[hits] = true
+			}
+		}
+	}
+	for hits := 2; hits <= 5; hits++ {
+		if !seen[hits] {
+			t.Errorf("Expected Bullet Seed to hit %d times at least once in 200 uses", hits)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package battle
+
+import (
+	"math"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// protectMoves maps the moves that shield the user for the rest of the turn
+// to the volatile they set: Protect and Detect block every move aimed at the
+// user, Endure keeps it from fainting to a hit.
+var protectMoves = map[string]string{
+	"protect": "protect",
+	"detect":  "protect",
+	"endure":  "endure",
+}
+
+// protectBypass lists the moves that break through Protect.
+var protectBypass = map[string]bool{
+	"feint":         true,
+	"phantom-force": true,
+	"shadow-force":  true,
+}
+
+// useProtectMove shields user with move. Each success in a row cuts the
+// chance that the next one works to a third; the streak is kept in the
+// "stall" volatile.
+func useProtectMove(user *BattlePokemon, move *pokemon.MoveInfo) []Event {
+	streak := user.Volatile["stall"]
+	if streak != nil && user.rng().Float64() >= math.Pow(1.0/3, float64(streak.Turns)) {
+		user.RemoveVolatileEffect("stall")
+		return []Event{Fail{Pokemon: user.Base.Name, Side: user.side}}
+	}
+	if streak == nil {
+		streak = user.setVolatile("stall", move.Name, 0)
+	}
+	streak.Turns++
+
+	effect := protectMoves[move.Name]
+	user.setVolatile(effect, move.Name, 1)
+	return []Event{Activate{Pokemon: user.Base.Name, Side: user.side, Effect: effect}}
+}
+
+// protected reports whether defender's Protect stops move, and lifts the
+// protection when move breaks through it.
+func protected(defender *BattlePokemon, move *pokemon.MoveInfo) (bool, []Event) {
+	if !targetsFoe(move) || !defender.Volatile.Has("protect") {
+		return false, nil
+	}
+	if protectBypass[move.Name] {
+		defender.RemoveVolatileEffect("protect")
+		return false, []Event{Activate{Pokemon: defender.Base.Name, Side: defender.side, Effect: "protect-broken"}}
+	}
+	return true, []Event{Activate{Pokemon: defender.Base.Name, Side: defender.side, Effect: "protect-blocked"}}
+}
+
+// endure leaves defender with 1 HP when dmg would otherwise knock it out
+// while Endure is up.
+func endure(defender *BattlePokemon, dmg int) (int, []Event) {
+	if !defender.Volatile.Has("endure") || float64(dmg) < defender.CurrentHP {
+		return dmg, nil
+	}
+	return int(defender.CurrentHP) - 1, []Event{Activate{Pokemon: defender.Base.Name, Side: defender.side, Effect: "endure-hit"}}
+}
+
+// useSubstitute trades a quarter of user's max HP for a substitute with
+// that much HP.
+func useSubstitute(user *BattlePokemon) []Event {
+	if user.Volatile.Has("substitute") {
+		return []Event{Fail{Pokemon: user.Base.Name, Side: user.side, Reason: "substitute"}}
+	}
+	cost := math.Floor(user.MaxHP() / 4)
+	if user.CurrentHP <= cost {
+		return []Event{Fail{Pokemon: user.Base.Name, Side: user.side, Reason: "substitute-hp"}}
+	}
+	user.ApplyDamage(cost)
+	user.setVolatile("substitute", "substitute", 0).HP = cost
+	return []Event{Activate{Pokemon: user.Base.Name, Side: user.side, Effect: "substitute"}}
+}
+
+// behindSubstitute reports whether attacker hits defender's substitute.
+func behindSubstitute(attacker, defender *BattlePokemon) bool {
+	return attacker != defender && defender.Volatile.Has("substitute")
+}
+
+// hitSubstitute deals the hit to defender's substitute and returns the
+// damage it absorbed.
+func hitSubstitute(defender *BattlePokemon, hit Damage) (int, []Event) {
+	sub := defender.Volatile["substitute"]
+	absorbed := int(math.Min(float64(hit.Amount), sub.HP))
+	sub.HP -= float64(hit.Amount)
+	hit.Amount, hit.Substitute = absorbed, true
+	hit.Percent = float64(absorbed) / defender.MaxHP() * 100
+	events := []Event{hit}
+	if sub.HP <= 0 {
+		defender.RemoveVolatileEffect("substitute")
+		events = append(events, Activate{Pokemon: defender.Base.Name, Side: defender.side, Effect: "substitute-faded"})
+	}
+	return absorbed, events
+}
@@ -0,0 +1,98 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestProtectBlocksAttacks(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "protect")
+	machamp := newBattler(t, "machamp", "", "close-combat")
+
+	battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+	events := battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if !hasEvent(events, "protected itself") || snorlax.CurrentHP != snorlax.MaxHP() {
+		t.Errorf("Expected Protect to block Close Combat, got %v", events)
+	}
+
+	snorlax.HandleTurnEffects()
+	events = battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if snorlax.CurrentHP == snorlax.MaxHP() {
+		t.Errorf("Expected Protect to wear off at the end of the turn, got %v", events)
+	}
+}
+
+func TestProtectFailsMoreOftenInARow(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "protect", "tackle")
+	machamp := newBattler(t, "machamp", "")
+
+	successes := 0
+	for range 600 {
+		snorlax.MovePP["protect"] = 10
+		snorlax.MovePP["tackle"] = 35
+		battle.Act(snorlax, machamp, snorlax.Moves[1], nil)
+		machamp.CurrentHP = machamp.MaxHP()
+		battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+		snorlax.HandleTurnEffects()
+		events := battle.Act(snorlax, machamp, snorlax.Moves[0], nil)
+		if hasEvent(events, "protected itself") {
+			successes++
+		}
+		snorlax.HandleTurnEffects()
+	}
+	if rate := float64(successes) / 600; rate < 0.25 || rate > 0.42 {
+		t.Errorf("Expected a second Protect in a row to work about a third of the time, got %.2f", rate)
+	}
+}
+
+func TestEndureSurvivesAtOneHP(t *testing.T) {
+	sneasel := newBattler(t, "sneasel", "", "endure")
+	machamp := newBattler(t, "machamp", "", "close-combat")
+	sneasel.CurrentHP = 5
+	closeCombat := *machamp.Moves[0]
+	closeCombat.Accuracy = 0
+
+	battle.Act(sneasel, machamp, sneasel.Moves[0], nil)
+	events := battle.Act(machamp, sneasel, &closeCombat, nil)
+	if sneasel.Fainted || sneasel.CurrentHP != 1 || !hasEvent(events, "endured the hit") {
+		t.Errorf("Expected Sneasel to endure at 1 HP, got %.0f HP: %v", sneasel.CurrentHP, events)
+	}
+}
+
+func TestSubstituteAbsorbsDamageAndStatus(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "substitute")
+	raichu := newBattler(t, "raichu", "", "thunder-wave", "quick-attack")
+	maxHP := snorlax.MaxHP()
+
+	events := battle.Act(snorlax, raichu, snorlax.Moves[0], nil)
+	cost := float64(int(maxHP / 4))
+	if !snorlax.Volatile.Has("substitute") || snorlax.CurrentHP != maxHP-cost {
+		t.Fatalf("Expected a substitute for a quarter of max HP, got %.0f/%.0f HP: %v", snorlax.CurrentHP, maxHP, events)
+	}
+	if sub := snorlax.Volatile["substitute"]; sub.HP != cost || sub.Source != "substitute" {
+		t.Errorf("Expected the substitute to hold %.0f HP, got %+v", cost, sub)
+	}
+
+	thunderWave := *raichu.Moves[0]
+	thunderWave.Accuracy = 0
+	events = battle.Act(raichu, snorlax, &thunderWave, nil)
+	if snorlax.Status != "" || !hasEvent(events, "But it failed!") {
+		t.Errorf("Expected the substitute to block Thunder Wave, got %v", events)
+	}
+
+	hp := snorlax.CurrentHP
+	for range 20 {
+		raichu.MovePP["quick-attack"] = 30
+		battle.Act(raichu, snorlax, raichu.Moves[1], nil)
+		if !snorlax.Volatile.Has("substitute") {
+			break
+		}
+	}
+	if snorlax.Volatile.Has("substitute") {
+		t.Fatal("Expected Quick Attack to break the substitute eventually")
+	}
+	if snorlax.CurrentHP != hp {
+		t.Errorf("Expected the substitute to take every hit, Snorlax went from %.0f to %.0f HP", hp, snorlax.CurrentHP)
+	}
+}
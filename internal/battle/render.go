@@ -0,0 +1,341 @@
+package battle
+
+import (
+	"fmt"
+	"strings"
+)
+
+var statusMessages = map[string]string{
+	"par":       "%s is paralyzed! It may be unable to move!",
+	"slp":       "%s fell asleep!",
+	"frz":       "%s was frozen solid!",
+	"brn":       "%s was burned!",
+	"psn":       "%s was poisoned!",
+	"tox":       "%s was badly poisoned!",
+	"confusion": "%s became confused!",
+}
+
+var cureMessages = map[string]string{
+	"slp":       "%s woke up!",
+	"frz":       "%s thawed out!",
+	"confusion": "%s snapped out of its confusion!",
+}
+
+var cantMoveMessages = map[string]string{
+	"slp":      "%s is fast asleep.",
+	"frz":      "%s is frozen solid!",
+	"par":      "%s is paralyzed! It can't move!",
+	"flinch":   "%s flinched and couldn't move!",
+	"recharge": "%s must recharge!",
+	"locked":   "%s is locked into %s!",
+	"no-pp":    "%s has no PP left for %s!",
+}
+
+var damageMessages = map[string]string{
+	"recoil":       "%s is damaged by recoil!",
+	"confusion":    "It hurt itself in its confusion!",
+	"brn":          "%s took damage from its burn!",
+	"psn":          "%s took damage from poison!",
+	"tox":          "%s took heavy damage from poison!",
+	"stealth-rock": "Pointed stones dug into %s!",
+	"spikes":       "%s was hurt by the spikes!",
+	"sandstorm":    "%s is buffeted by the sandstorm!",
+	"hail":         "%s is buffeted by the hail!",
+	"life-orb":     "%s lost some of its HP!",
+	"black-sludge": "%s is hurt by its Black Sludge!",
+}
+
+var healMessages = map[string]string{
+	"":               "%s restored HP.",
+	"rest":           "%s slept and became healthy!",
+	"leftovers":      "%s restored a little HP using its Leftovers!",
+	"black-sludge":   "%s restored a little HP using its Black Sludge!",
+	"grassy-terrain": "%s's HP was restored by the Grassy Terrain.",
+}
+
+var failMessages = map[string]string{
+	"":                "But it failed!",
+	"hp-full":         "%s's HP is full!",
+	"confused":        "%s is already confused!",
+	"substitute":      "%s already has a substitute!",
+	"substitute-hp":   "But it does not have enough HP left to make a substitute!",
+	"no-effect":       "It had no effect on %s!",
+	"terrain":         "%s is protected by the terrain!",
+	"psychic-terrain": "%s is protected by the Psychic Terrain!",
+	"invalid-move":    "%s has an invalid move!",
+}
+
+// activateMessages take the Pokémon and then the target.
+var activateMessages = map[string]string{
+	"quick-claw":       "%s's Quick Claw let it move first!",
+	"intimidate":       "%s's Intimidate cuts %s's attack!",
+	"flash-fire":       "%s's Flash Fire raised the power of its Fire-type moves!",
+	"speed-boost":      "%s's Speed Boost activated!",
+	"sturdy":           "%s endured the hit with Sturdy!",
+	"protect":          "%s protected itself!",
+	"protect-blocked":  "%s protected itself!",
+	"protect-broken":   "It broke through %s's protection!",
+	"endure":           "%s braced itself!",
+	"endure-hit":       "%s endured the hit!",
+	"substitute":       "%s put in a substitute!",
+	"substitute-faded": "%s's substitute faded!",
+	"confusion":        "%s is confused!",
+	"sticky-web":       "%s was caught in a sticky web!",
+	"solar-beam":       "%s absorbed light!",
+	"solar-blade":      "%s absorbed light!",
+	"sky-attack":       "%s became cloaked in a harsh light!",
+	"razor-wind":       "%s whipped up a whirlwind!",
+	"skull-bash":       "%s tucked in its head!",
+	"meteor-beam":      "%s is overflowing with space power!",
+	"fly":              "%s flew up high!",
+	"bounce":           "%s sprang up!",
+	"dig":              "%s burrowed its way under the ground!",
+	"dive":             "%s hid underwater!",
+	"phantom-force":    "%s vanished instantly!",
+	"shadow-force":     "%s vanished instantly!",
+}
+
+var weatherMessages = map[string]map[string]string{
+	"rain": {
+		WeatherStarted:   "It started to rain!",
+		WeatherContinues: "Rain continues to fall.",
+		WeatherEnded:     "The rain stopped.",
+	},
+	"sun": {
+		WeatherStarted:   "The sunlight turned harsh!",
+		WeatherContinues: "The sunlight is strong.",
+		WeatherEnded:     "The harsh sunlight faded.",
+	},
+	"sandstorm": {
+		WeatherStarted:   "A sandstorm kicked up!",
+		WeatherContinues: "The sandstorm rages.",
+		WeatherEnded:     "The sandstorm subsided.",
+	},
+	"hail": {
+		WeatherStarted:   "It started to hail!",
+		WeatherContinues: "The hail continues to fall.",
+		WeatherEnded:     "The hail stopped.",
+	},
+}
+
+// conditionMessages hold the start and end of each condition. Side
+// condition messages take the side's name.
+var conditionMessages = map[string][2]string{
+	"reflect": {
+		"Reflect made %s stronger against physical moves!",
+		"The Reflect protecting %s wore off!",
+	},
+	"light-screen": {
+		"Light Screen made %s stronger against special moves!",
+		"The Light Screen protecting %s wore off!",
+	},
+	"tailwind": {
+		"The Tailwind blew from behind %s!",
+		"The Tailwind behind %s petered out!",
+	},
+	"trick-room": {
+		"The dimensions were twisted!",
+		"The twisted dimensions returned to normal!",
+	},
+	"electric-terrain": {
+		"An electric current ran across the battlefield!",
+		"The electricity disappeared from the battlefield.",
+	},
+	"grassy-terrain": {
+		"Grass grew to cover the battlefield!",
+		"The grass disappeared from the battlefield.",
+	},
+	"misty-terrain": {
+		"Mist swirled around the battlefield!",
+		"The mist disappeared from the battlefield.",
+	},
+	"psychic-terrain": {
+		"The battlefield got weird!",
+		"The weirdness disappeared from the battlefield!",
+	},
+}
+
+var hazardMessages = map[string]string{
+	"stealth-rock": "Pointed stones float in the air around %s!",
+	"spikes":       "Spikes were scattered on the ground all around %s!",
+	"toxic-spikes": "Poison spikes were scattered on the ground all around %s!",
+	"sticky-web":   "A sticky web has been laid out on the ground around %s!",
+}
+
+// Render formats events as the lines of text the terminal shows. The
+// effectiveness of a multi-hit move is reported with its first hit only.
+func Render(events []Event) []string {
+	var lines []string
+	reported := false
+	for _, e := range events {
+		switch e := e.(type) {
+		case MoveUsed:
+			reported = false
+		case Damage:
+			if e.Cause == "" {
+				lines = append(lines, hitLines(e, !reported)...)
+				reported = true
+				continue
+			}
+		}
+		lines = append(lines, Lines(e)...)
+	}
+	return lines
+}
+
+// Lines formats a single event. Most events take one line; a move's hit
+// reports its effectiveness and a critical hit on lines of their own, and
+// some events, such as the status Rest inflicts, need none.
+func Lines(e Event) []string {
+	switch e := e.(type) {
+	case MoveUsed:
+		return line("%s used %s!", owned(e.Pokemon, e.Side), e.Move)
+	case Damage:
+		if e.Cause == "" {
+			return hitLines(e, true)
+		}
+		return line(damageMessages[e.Cause], owned(e.Pokemon, e.Side))
+	case Heal:
+		switch msg, ok := healMessages[e.Cause]; {
+		case ok:
+			return line(msg, owned(e.Pokemon, e.Side))
+		case e.Cause == "drain":
+			return line("%s had its energy drained!", e.Source)
+		default:
+			return line("%s restored HP using its %s!", owned(e.Pokemon, e.Side), displayName(e.Cause))
+		}
+	case Miss:
+		return line("%s's attack missed!", owned(e.Pokemon, e.Side))
+	case Immune:
+		switch e.Ability {
+		case "":
+			return line("It doesn't affect %s!", owned(e.Pokemon, e.Side))
+		case "sturdy":
+			return line("%s was protected by Sturdy!", owned(e.Pokemon, e.Side))
+		}
+		return line("It doesn't affect %s because of its %s!", owned(e.Pokemon, e.Side), displayName(e.Ability))
+	case Fail:
+		return line(failMessages[e.Reason], owned(e.Pokemon, e.Side))
+	case Faint:
+		return line("%s fainted!", owned(e.Pokemon, e.Side))
+	case Switch:
+		side := e.Side
+		if side != "" {
+			side = strings.ToUpper(side[:1]) + side[1:]
+		}
+		return line("%s sent out %s!", side, e.Pokemon)
+	case StatusApplied:
+		switch {
+		case e.Cause == "rest":
+			return nil
+		case e.Cause == "static":
+			return line("%s's Static paralyzed %s! It may be unable to move!", e.Source, owned(e.Pokemon, e.Side))
+		case e.Cause == "fatigue":
+			return line("%s became confused due to fatigue!", owned(e.Pokemon, e.Side))
+		case strings.HasSuffix(e.Cause, "-orb"):
+			verb := map[string]string{"brn": "burned", "tox": "badly poisoned"}[e.Status]
+			return line("%s was %s by its %s!", owned(e.Pokemon, e.Side), verb, displayName(e.Cause))
+		}
+		return line(statusMessages[e.Status], owned(e.Pokemon, e.Side))
+	case StatusCured:
+		switch {
+		case strings.HasSuffix(e.Cause, "-berry"):
+			return line("%s's %s cured its status!", owned(e.Pokemon, e.Side), displayName(e.Cause))
+		case e.Status == "frz" && e.Cause != "":
+			return line("%s's %s melted the ice!", owned(e.Pokemon, e.Side), displayName(e.Cause))
+		}
+		return line(cureMessages[e.Status], owned(e.Pokemon, e.Side))
+	case StatChange:
+		return line(statChangeMessage(e), owned(e.Pokemon, e.Side), strings.ReplaceAll(e.Stat, "-", " "))
+	case WeatherChanged:
+		msg := weatherMessages[e.Weather][e.Phase]
+		if e.Ability != "" {
+			return []string{fmt.Sprintf("%s's %s:", owned(e.Pokemon, e.Side), displayName(e.Ability)), msg}
+		}
+		return []string{msg}
+	case ConditionChanged:
+		msg := conditionMessages[e.Condition][0]
+		if e.Ended {
+			msg = conditionMessages[e.Condition][1]
+		}
+		if e.Side == "" {
+			return []string{msg}
+		}
+		return line(msg, e.Side)
+	case HazardSet:
+		return line(hazardMessages[e.Hazard], e.Side)
+	case HazardCleared:
+		if e.Move == "" {
+			return line("%s absorbed the poison spikes!", e.Pokemon)
+		}
+		return line("%s's %s blew away %s from around %s!", e.Pokemon, displayName(e.Move), displayName(e.Hazard), e.Side)
+	case CantMove:
+		return line(cantMoveMessages[e.Reason], owned(e.Pokemon, e.Side), e.Move)
+	case Activate:
+		return line(activateMessages[e.Effect], owned(e.Pokemon, e.Side), e.Target)
+	case ItemRemoved:
+		return line("%s knocked off %s's %s!", e.By, owned(e.Pokemon, e.Side), displayName(e.Item))
+	case HitCount:
+		return line("Hit %d time(s)!", e.Hits)
+	case Message:
+		return []string{e.Text}
+	}
+	return nil
+}
+
+// owned names a Pokémon with the side it battles on, so both Pokémon of a
+// mirror match can be told apart.
+func owned(pokemon, side string) string {
+	if side == "" {
+		return pokemon
+	}
+	return side + "'s " + pokemon
+}
+
+// line fills in msg with as many of args as it takes.
+func line(msg string, args ...any) []string {
+	if msg == "" {
+		return nil
+	}
+	n := min(strings.Count(msg, "%")-2*strings.Count(msg, "%%"), len(args))
+	return []string{fmt.Sprintf(msg, args[:n]...)}
+}
+
+// hitLines formats the hit of a move, with its effectiveness when
+// withEffectiveness is set.
+func hitLines(d Damage, withEffectiveness bool) []string {
+	var lines []string
+	switch {
+	case d.OHKO:
+		lines = append(lines, "It's a one-hit KO!")
+	case !withEffectiveness:
+	case d.Effectiveness > 1:
+		lines = append(lines, "It's super effective!")
+	case d.Effectiveness > 0 && d.Effectiveness < 1:
+		lines = append(lines, "It's not very effective...")
+	}
+	if d.Critical {
+		lines = append(lines, "Critical hit!")
+	}
+	if d.Substitute {
+		return append(lines, fmt.Sprintf("The substitute took damage for %s!", owned(d.Pokemon, d.Side)))
+	}
+	return append(lines, fmt.Sprintf("%s took %d damage! (%.1f%%)", owned(d.Pokemon, d.Side), d.Amount, d.Percent))
+}
+
+func statChangeMessage(e StatChange) string {
+	switch {
+	case e.Applied == 0 && e.Change > 0:
+		return "%s's %s won't go any higher!"
+	case e.Applied == 0:
+		return "%s's %s won't go any lower!"
+	case e.Applied >= 2:
+		return "%s's %s rose sharply!"
+	case e.Applied > 0:
+		return "%s's %s rose!"
+	case e.Applied <= -2:
+		return "%s's %s harshly fell!"
+	default:
+		return "%s's %s fell!"
+	}
+}
@@ -0,0 +1,42 @@
+package battle
+
+import "math/rand/v2"
+
+// NewSeed picks a fresh battle seed. Record it to replay the battle.
+func NewSeed() uint64 {
+	return rand.Uint64()
+}
+
+// NewRand returns the generator a battle with the given seed draws from.
+func NewRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+}
+
+// forkRand derives an independent generator from r, for work that runs
+// concurrently but must still follow from the battle seed.
+func forkRand(r *rand.Rand) *rand.Rand {
+	return rand.New(rand.NewPCG(r.Uint64(), r.Uint64()))
+}
+
+// Rand is the generator every random decision in the battle draws from. A
+// field not made by NewField draws from its Seed, and a nil field from a
+// fresh generator seeded with 0.
+func (f *Field) Rand() *rand.Rand {
+	if f == nil {
+		return NewRand(0)
+	}
+	if f.rand == nil {
+		f.rand = NewRand(f.Seed)
+	}
+	return f.rand
+}
+
+// rng is the generator of the battle bp has joined. Before bp joins one it
+// rolls from a generator of its own seeded with 0, so standalone
+// calculations still repeat.
+func (bp *BattlePokemon) rng() *rand.Rand {
+	if bp.rand == nil {
+		bp.rand = NewRand(0)
+	}
+	return bp.rand
+}
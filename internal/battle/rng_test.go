@@ -0,0 +1,82 @@
+package battle_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// replay runs a fixed sequence of choices on a fresh field with seed and
+// returns everything that happened.
+func replay(t *testing.T, seed uint64) []battle.Event {
+	t.Helper()
+	gengar := newBattler(t, "gengar", "", "hypnosis", "confuse-ray", "shadow-ball")
+	snorlax := newBattler(t, "snorlax", "", "body-slam", "crunch")
+	field := battle.NewField(battle.Format{}, seed)
+	field.Join(0, "your team", []*battle.BattlePokemon{gengar})
+	field.Join(1, "the opposing team", []*battle.BattlePokemon{snorlax})
+
+	var log []battle.Event
+	for turn := 0; turn < 12 && !gengar.Fainted && !snorlax.Fainted; turn++ {
+		gengarMove, snorlaxMove := gengar.Moves[turn%3], snorlax.Moves[turn%2]
+		first, _, _, _ := battle.ResolveTurn(gengar, snorlax, gengarMove, snorlaxMove, field)
+		if first == gengar {
+			log = append(log, battle.Act(gengar, snorlax, gengarMove, field)...)
+			log = append(log, battle.Act(snorlax, gengar, snorlaxMove, field)...)
+		} else {
+			log = append(log, battle.Act(snorlax, gengar, snorlaxMove, field)...)
+			log = append(log, battle.Act(gengar, snorlax, gengarMove, field)...)
+		}
+		log = append(log, gengar.HandleTurnEffects()...)
+		log = append(log, snorlax.HandleTurnEffects()...)
+	}
+	return log
+}
+
+func TestSameSeedReplaysTheBattle(t *testing.T) {
+	first, second := replay(t, 42), replay(t, 42)
+	if !slices.Equal(first, second) {
+		t.Errorf("Expected seed 42 to replay the same battle, got\n%v\nand\n%v", first, second)
+	}
+}
+
+func TestNewRandFollowsTheSeed(t *testing.T) {
+	a, b := battle.NewRand(7), battle.NewRand(7)
+	for range 10 {
+		if a.Uint64() != b.Uint64() {
+			t.Fatal("Expected generators with the same seed to draw the same numbers")
+		}
+	}
+}
+
+func TestStandaloneCalculationsNeedNoField(t *testing.T) {
+	src := pokemon.BundledSource()
+	base, err := src.Pokemon(context.Background(), "snorlax")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := src.MoveByName(context.Background(), "body-slam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calc := func() int {
+		attacker := battle.NewBattlePokemon(base, []*pokemon.MoveInfo{move})
+		defender := battle.NewBattlePokemon(base, nil)
+		dmg, _, _ := battle.DamageCalc(attacker, defender, move, nil)
+		return dmg
+	}
+	if first, again := calc(), calc(); first <= 0 || first != again {
+		t.Errorf("Expected Pokémon off any field to roll the same damage, got %d then %d", first, again)
+	}
+
+	var none *battle.Field
+	if none.Rand().Uint64() != battle.NewRand(0).Uint64() {
+		t.Error("Expected a nil field to draw from seed 0")
+	}
+	if (&battle.Field{Seed: 9}).Rand().Uint64() != battle.NewRand(9).Uint64() {
+		t.Error("Expected a field made without NewField to draw from its seed")
+	}
+}
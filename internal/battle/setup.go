@@ -1,228 +1,82 @@
 package battle
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"math/rand/v2"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
-func SetupFullSquads() ([]*BattlePokemon, []*BattlePokemon, [][]*pokemon.MoveInfo, [][]*pokemon.MoveInfo, int, int) {
+// SetupFullSquads draws both squads from r, asks the player for a lead and
+// loads every moveset.
+func SetupFullSquads(ctx context.Context, src pokemon.DataSource, format Format, r *rand.Rand) ([]*BattlePokemon, []*BattlePokemon, [][]*pokemon.MoveInfo, [][]*pokemon.MoveInfo, int, int, error) {
 	totalStartTime := time.Now()
 
-	moveCache := make(map[string]*pokemon.MoveInfo)
-	var cacheMutex sync.Mutex
-
-	fetchMoveWithCache := func(url string) (*pokemon.MoveInfo, error) {
-		cacheMutex.Lock()
-		if cachedMove, found := moveCache[url]; found {
-			cacheMutex.Unlock()
-			return cachedMove, nil
-		}
-		cacheMutex.Unlock()
-
-		var moveData *pokemon.MoveInfo
-		var err error
-		maxRetries := 3
-
-		for attempts := 0; attempts < maxRetries; attempts++ {
-			moveData, err = pokemon.FetchMoveData(url)
-
-			if err == nil {
-				cacheMutex.Lock()
-				moveCache[url] = moveData
-				cacheMutex.Unlock()
-				return moveData, nil
-			}
-
-			time.Sleep(200 * time.Millisecond)
-		}
-
-		return nil, err
+	playerSquadBase, err := pokemon.SelectRandSquad(ctx, src, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
+	}
+	enemySquadBase, err := pokemon.SelectRandSquad(ctx, src, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
 	}
-
-	playerSquadBase := pokemon.SelectRandSquad()
-	enemySquadBase := pokemon.SelectRandSquad()
 
 	fmt.Println("Your randomly selected pokemon squad is:")
 	for i := range playerSquadBase {
-		fmt.Println(i+1, playerSquadBase[i].Name)
+		fmt.Println(i+1, playerSquadBase[i].Name, "Lv.", format.LevelFor(playerSquadBase[i]))
 	}
 
 	fmt.Println("\nEnemy randomly selected pokemon squad is:")
 	for i := range enemySquadBase {
-		fmt.Println(i+1, enemySquadBase[i].Name)
+		fmt.Println(i+1, enemySquadBase[i].Name, "Lv.", format.LevelFor(enemySquadBase[i]))
 	}
 
 	var playerSelect int
 	fmt.Print("\nSelect your first Pokémon to send out (enter a number 1 - 6): ")
 	fmt.Scan(&playerSelect)
 	playerSelect = (playerSelect - 1) % len(playerSquadBase)
+	if playerSelect < 0 {
+		playerSelect = 0
+	}
 	playerActiveIndex := playerSelect
 
-	enemySelect := rand.Intn(len(enemySquadBase))
+	enemySelect := r.IntN(len(enemySquadBase))
 	enemyActiveIndex := enemySelect
 
 	fmt.Printf("You sent out %s!\n", playerSquadBase[playerSelect].Name)
 	fmt.Printf("Enemy sent out %s!\n", enemySquadBase[enemySelect].Name)
 
-	playerSquad := make([]*BattlePokemon, len(playerSquadBase))
-	enemySquad := make([]*BattlePokemon, len(enemySquadBase))
-
-	playerMovesets := make([][]*pokemon.MoveInfo, len(playerSquadBase))
-	enemyMovesets := make([][]*pokemon.MoveInfo, len(enemySquadBase))
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
 	fmt.Println("\nLoading movesets in parallel (with optimizations)...")
 
-	for i, base := range playerSquadBase {
-		wg.Add(1)
-		go func(i int, base *pokemon.Pokemon) {
-			defer wg.Done()
-
-			pokeStartTime := time.Now()
-
-			mu.Lock()
-			fmt.Printf("Fetching moveset for your %s...\n", base.Name)
-			mu.Unlock()
-
-			moves := pokemon.PickRandMoves(base)
-			moveset := []*pokemon.MoveInfo{}
-
-			var moveWg sync.WaitGroup
-			var movesetMutex sync.Mutex
-
-			for _, moveAPI := range moves {
-				moveWg.Add(1)
-				go func(moveURL string) {
-					defer moveWg.Done()
-
-					moveData, err := fetchMoveWithCache(moveURL)
-					if err != nil {
-						mu.Lock()
-						fmt.Printf("Error fetching move for %s: %v\n", base.Name, err)
-						mu.Unlock()
-						return
-					}
-
-					movesetMutex.Lock()
-					moveset = append(moveset, moveData)
-					movesetMutex.Unlock()
-				}(moveAPI.URL)
-			}
-
-			moveWg.Wait()
-
-			playerMovesets[i] = moveset
-			playerSquad[i] = NewBattlePokemon(base, moveset)
-
-			loadTime := time.Since(pokeStartTime)
-
-			mu.Lock()
-			fmt.Printf("Completed loading moveset for your %s! (%.2f seconds)\n",
-				base.Name, loadTime.Seconds())
-			mu.Unlock()
-		}(i, base)
+	playerSquad, playerMovesets, enemySquad, enemyMovesets, err := loadSquads(ctx, src, format, playerSquadBase, enemySquadBase, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
 	}
 
-	for i, base := range enemySquadBase {
-		wg.Add(1)
-		go func(i int, base *pokemon.Pokemon) {
-			defer wg.Done()
-
-			pokeStartTime := time.Now()
-
-			mu.Lock()
-			fmt.Printf("Fetching moveset for enemy %s...\n", base.Name)
-			mu.Unlock()
-
-			moves := pokemon.PickRandMoves(base)
-			moveset := []*pokemon.MoveInfo{}
-
-			var moveWg sync.WaitGroup
-			var movesetMutex sync.Mutex
-
-			for _, moveAPI := range moves {
-				moveWg.Add(1)
-				go func(moveURL string) {
-					defer moveWg.Done()
-
-					moveData, err := fetchMoveWithCache(moveURL)
-					if err != nil {
-						mu.Lock()
-						fmt.Printf("Error fetching move for enemy %s: %v\n", base.Name, err)
-						mu.Unlock()
-						return
-					}
-
-					movesetMutex.Lock()
-					moveset = append(moveset, moveData)
-					movesetMutex.Unlock()
-				}(moveAPI.URL)
-			}
-
-			moveWg.Wait()
-
-			enemyMovesets[i] = moveset
-			enemySquad[i] = NewBattlePokemon(base, moveset)
-
-			loadTime := time.Since(pokeStartTime)
-
-			mu.Lock()
-			fmt.Printf("Completed loading moveset for enemy %s! (%.2f seconds)\n",
-				base.Name, loadTime.Seconds())
-			mu.Unlock()
-		}(i, base)
-	}
-
-	wg.Wait()
-
 	totalLoadTime := time.Since(totalStartTime)
 	fmt.Printf("\nAll movesets loaded successfully! Total loading time: %.2f seconds\n",
 		totalLoadTime.Seconds())
 
-	return playerSquad, enemySquad, playerMovesets, enemyMovesets, playerActiveIndex, enemyActiveIndex
+	return playerSquad, enemySquad, playerMovesets, enemyMovesets, playerActiveIndex, enemyActiveIndex, nil
 }
 
-func SetupMPSquad() ([]*BattlePokemon, []*BattlePokemon, [][]*pokemon.MoveInfo, [][]*pokemon.MoveInfo, int, int) {
-
-	moveCache := make(map[string]*pokemon.MoveInfo)
-	var cacheMutex sync.Mutex
-
-	fetchMoveWithCache := func(url string) (*pokemon.MoveInfo, error) {
-		cacheMutex.Lock()
-		if cachedMove, found := moveCache[url]; found {
-			cacheMutex.Unlock()
-			return cachedMove, nil
-		}
-		cacheMutex.Unlock()
-
-		var moveData *pokemon.MoveInfo
-		var err error
-		maxRetries := 3
-
-		for attempts := 0; attempts < maxRetries; attempts++ {
-			moveData, err = pokemon.FetchMoveData(url)
-
-			if err == nil {
-				cacheMutex.Lock()
-				moveCache[url] = moveData
-				cacheMutex.Unlock()
-				return moveData, nil
-			}
-
-			time.Sleep(200 * time.Millisecond)
-		}
-
-		return nil, err
+// SetupMPSquad draws both squads of a multiplayer battle from r, each led by
+// its first member.
+func SetupMPSquad(ctx context.Context, src pokemon.DataSource, format Format, r *rand.Rand) ([]*BattlePokemon, []*BattlePokemon, [][]*pokemon.MoveInfo, [][]*pokemon.MoveInfo, int, int, error) {
+	playerSquadBase, err := pokemon.SelectRandSquad(ctx, src, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
+	}
+	enemySquadBase, err := pokemon.SelectRandSquad(ctx, src, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
 	}
-
-	playerSquadBase := pokemon.SelectRandSquad()
-	enemySquadBase := pokemon.SelectRandSquad()
 
 	playerSelect := 0
 	playerActiveIndex := playerSelect
@@ -233,118 +87,140 @@ func SetupMPSquad() ([]*BattlePokemon, []*BattlePokemon, [][]*pokemon.MoveInfo,
 	fmt.Printf("You sent out %s!\n", playerSquadBase[playerSelect].Name)
 	fmt.Printf("Enemy sent out %s!\n", enemySquadBase[enemySelect].Name)
 
-	playerSquad := make([]*BattlePokemon, len(playerSquadBase))
-	enemySquad := make([]*BattlePokemon, len(enemySquadBase))
-
-	playerMovesets := make([][]*pokemon.MoveInfo, len(playerSquadBase))
-	enemyMovesets := make([][]*pokemon.MoveInfo, len(enemySquadBase))
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
 	fmt.Println("\nLoading movesets")
 
-	for i, base := range playerSquadBase {
-		wg.Add(1)
-		go func(i int, base *pokemon.Pokemon) {
-			defer wg.Done()
-
-			pokeStartTime := time.Now()
-
-			mu.Lock()
-			fmt.Printf("Fetching moveset for your %s...\n", base.Name)
-			mu.Unlock()
-
-			moves := pokemon.PickRandMoves(base)
-			moveset := []*pokemon.MoveInfo{}
-
-			var moveWg sync.WaitGroup
-			var movesetMutex sync.Mutex
-
-			for _, moveAPI := range moves {
-				moveWg.Add(1)
-				go func(moveURL string) {
-					defer moveWg.Done()
-
-					moveData, err := fetchMoveWithCache(moveURL)
-					if err != nil {
-						mu.Lock()
-						fmt.Printf("Error fetching move for %s: %v\n", base.Name, err)
-						mu.Unlock()
-						return
-					}
-
-					movesetMutex.Lock()
-					moveset = append(moveset, moveData)
-					movesetMutex.Unlock()
-				}(moveAPI.URL)
-			}
-
-			moveWg.Wait()
+	playerSquad, playerMovesets, enemySquad, enemyMovesets, err := loadSquads(ctx, src, format, playerSquadBase, enemySquadBase, r, os.Stdout)
+	if err != nil {
+		return nil, nil, nil, nil, 0, 0, err
+	}
 
-			playerMovesets[i] = moveset
-			playerSquad[i] = NewBattlePokemon(base, moveset)
+	return playerSquad, enemySquad, playerMovesets, enemyMovesets, playerActiveIndex, enemyActiveIndex, nil
+}
 
-			loadTime := time.Since(pokeStartTime)
+// SetupHeadlessSquads draws both squads of a battle between agents from r,
+// each to be led by its first member, without printing anything.
+func SetupHeadlessSquads(ctx context.Context, src pokemon.DataSource, format Format, r *rand.Rand) ([]*BattlePokemon, []*BattlePokemon, error) {
+	playerSquadBase, err := pokemon.SelectRandSquad(ctx, src, r, io.Discard)
+	if err != nil {
+		return nil, nil, err
+	}
+	enemySquadBase, err := pokemon.SelectRandSquad(ctx, src, r, io.Discard)
+	if err != nil {
+		return nil, nil, err
+	}
+	playerSquad, _, enemySquad, _, err := loadSquads(ctx, src, format, playerSquadBase, enemySquadBase, r, io.Discard)
+	return playerSquad, enemySquad, err
+}
 
-			mu.Lock()
-			fmt.Printf("Completed loading moveset for your %s! (%.2f seconds)\n",
-				base.Name, loadTime.Seconds())
-			mu.Unlock()
-		}(i, base)
+// LoadMoveset picks a moveset for base at random from r and fetches every
+// move in it. Moves the source cannot provide are reported to progress and
+// left out; network failures and cancellation are returned. Retries happen
+// inside the data source.
+func LoadMoveset(ctx context.Context, src pokemon.DataSource, base *pokemon.Pokemon, r *rand.Rand, progress io.Writer) ([]*pokemon.MoveInfo, error) {
+	moves, err := pokemon.PickRandMoves(ctx, src, base, MoveSupported, r)
+	if err != nil {
+		return nil, err
 	}
 
-	for i, base := range enemySquadBase {
+	moveset := make([]*pokemon.MoveInfo, len(moves))
+	errs := make([]error, len(moves))
+	var wg sync.WaitGroup
+	for i, moveAPI := range moves {
 		wg.Add(1)
-		go func(i int, base *pokemon.Pokemon) {
+		go func(i int, moveAPI pokemon.ApiResource) {
 			defer wg.Done()
+			moveset[i], errs[i] = src.MoveByURL(ctx, moveAPI.URL)
+		}(i, moveAPI)
+	}
+	wg.Wait()
 
-			pokeStartTime := time.Now()
-
-			mu.Lock()
-			fmt.Printf("Fetching moveset for enemy %s...\n", base.Name)
-			mu.Unlock()
+	loaded := moveset[:0]
+	for i, move := range moveset {
+		if errs[i] == nil {
+			loaded = append(loaded, move)
+			continue
+		}
+		if !errors.Is(errs[i], pokemon.ErrNotFound) && !errors.Is(errs[i], pokemon.ErrDecode) {
+			return nil, errs[i]
+		}
+		fmt.Fprintf(progress, "Error fetching move %s for %s: %v\n", moves[i].Name, base.Name, errs[i])
+	}
+	return loaded, nil
+}
 
-			moves := pokemon.PickRandMoves(base)
-			moveset := []*pokemon.MoveInfo{}
+// loadSquads deals movesets, spreads, abilities and items to both squads,
+// reporting its progress to progress.
+func loadSquads(ctx context.Context, src pokemon.DataSource, format Format, playerBase, enemyBase []*pokemon.Pokemon, r *rand.Rand, progress io.Writer) ([]*BattlePokemon, [][]*pokemon.MoveInfo, []*BattlePokemon, [][]*pokemon.MoveInfo, error) {
+	catalogue, err := LoadItemCatalogue(ctx, src, ItemNames())
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
 
-			var moveWg sync.WaitGroup
-			var movesetMutex sync.Mutex
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-			for _, moveAPI := range moves {
-				moveWg.Add(1)
-				go func(moveURL string) {
-					defer moveWg.Done()
+	playerSquad := make([]*BattlePokemon, len(playerBase))
+	enemySquad := make([]*BattlePokemon, len(enemyBase))
+	playerMovesets := make([][]*pokemon.MoveInfo, len(playerBase))
+	enemyMovesets := make([][]*pokemon.MoveInfo, len(enemyBase))
 
-					moveData, err := fetchMoveWithCache(moveURL)
-					if err != nil {
-						mu.Lock()
-						fmt.Printf("Error fetching move for enemy %s: %v\n", base.Name, err)
-						mu.Unlock()
-						return
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	load := func(owner string, bases []*pokemon.Pokemon, squad []*BattlePokemon, movesets [][]*pokemon.MoveInfo) {
+		for i, base := range bases {
+			// Each member gets its own generator, forked in squad order, so
+			// the goroutines finishing in any order cannot change the result.
+			r := forkRand(r)
+			wg.Add(1)
+			go func(i int, base *pokemon.Pokemon) {
+				defer wg.Done()
+
+				pokeStartTime := time.Now()
+
+				mu.Lock()
+				fmt.Fprintf(progress, "Fetching moveset for %s %s...\n", owner, base.Name)
+				mu.Unlock()
+
+				fail := func(err error) {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel()
 					}
-
-					movesetMutex.Lock()
-					moveset = append(moveset, moveData)
-					movesetMutex.Unlock()
-				}(moveAPI.URL)
-			}
-
-			moveWg.Wait()
-
-			enemyMovesets[i] = moveset
-			enemySquad[i] = NewBattlePokemon(base, moveset)
-
-			loadTime := time.Since(pokeStartTime)
-
-			mu.Lock()
-			fmt.Printf("Completed loading moveset for enemy %s! (%.2f seconds)\n",
-				base.Name, loadTime.Seconds())
-			mu.Unlock()
-		}(i, base)
+					mu.Unlock()
+				}
+
+				moveset, err := LoadMoveset(ctx, src, base, r, progress)
+				if err != nil {
+					fail(fmt.Errorf("loading moveset for %s: %w", base.Name, err))
+					return
+				}
+
+				movesets[i] = moveset
+				squad[i] = NewBattlePokemon(base, moveset)
+				if err := squad[i].SetSpread(format.spreadFor(base, r)); err != nil {
+					fail(fmt.Errorf("setting spread for %s: %w", base.Name, err))
+					return
+				}
+				squad[i].Ability = pokemon.PickRandAbility(base, r)
+				squad[i].Item = PickRandItem(squad[i], catalogue, r)
+
+				mu.Lock()
+				fmt.Fprintf(progress, "Completed loading moveset for %s %s! (%.2f seconds)\n",
+					owner, base.Name, time.Since(pokeStartTime).Seconds())
+				mu.Unlock()
+			}(i, base)
+		}
 	}
 
+	load("your", playerBase, playerSquad, playerMovesets)
+	load("enemy", enemyBase, enemySquad, enemyMovesets)
 	wg.Wait()
 
-	return playerSquad, enemySquad, playerMovesets, enemyMovesets, playerActiveIndex, enemyActiveIndex
+	if firstErr != nil {
+		return nil, nil, nil, nil, firstErr
+	}
+	return playerSquad, playerMovesets, enemySquad, enemyMovesets, nil
 }
@@ -0,0 +1,64 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestSpeedStagesAndParalysisDecideTurnOrder(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "tackle")
+	sneasel := newBattler(t, "sneasel", "", "tackle")
+
+	snorlax.ApplyStatStage("speed", 6)
+	first, _, _, _ := battle.ResolveTurn(snorlax, sneasel, snorlax.Moves[0], sneasel.Moves[0], nil)
+	if first != snorlax {
+		t.Errorf("Expected +6 speed Snorlax to outspeed Sneasel")
+	}
+
+	snorlax.ApplyStatStage("speed", -12)
+	sneasel.ApplyStatus("par")
+	first, _, _, _ = battle.ResolveTurn(snorlax, sneasel, snorlax.Moves[0], sneasel.Moves[0], nil)
+	if first != sneasel {
+		t.Errorf("Expected paralyzed Sneasel to still outspeed -6 Snorlax")
+	}
+	snorlax.ApplyStatStage("speed", 8)
+	first, _, _, _ = battle.ResolveTurn(snorlax, sneasel, snorlax.Moves[0], sneasel.Moves[0], nil)
+	if first != snorlax {
+		t.Errorf("Expected paralysis to halve Sneasel's speed below Snorlax's")
+	}
+}
+
+func TestAttackStagesScaleDamage(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "tackle")
+	snorlax := newBattler(t, "snorlax", "")
+
+	average := func() float64 {
+		total := 0
+		for range 200 {
+			dmg, _, _ := battle.DamageCalc(machamp, snorlax, machamp.Moves[0], nil)
+			total += dmg
+		}
+		return float64(total) / 200
+	}
+	neutral := average()
+	machamp.ApplyStatStage("attack", 2)
+	boosted := average()
+	if ratio := boosted / neutral; ratio < 1.7 || ratio > 2.2 {
+		t.Errorf("Expected +2 attack to roughly double damage, got ratio %.2f", ratio)
+	}
+}
+
+func TestSwitchOutResetsStages(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "swords-dance")
+	snorlax := newBattler(t, "snorlax", "")
+	battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	if got := battle.GetPokemonFullView(machamp).StatStages["attack"]; got != 2 {
+		t.Fatalf("Expected the view to show +2 attack, got %d", got)
+	}
+
+	battle.SwitchOut(machamp)
+	if view := battle.GetPokemonFullView(machamp); len(view.StatStages) != 0 {
+		t.Errorf("Expected switching out to reset stages, got %v", view.StatStages)
+	}
+}
@@ -3,6 +3,10 @@ package battle
 import (
 	"fmt"
 	"log"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
 	"time"
 
 	"github.com/ross1116/pokebattlecli/internal/pokemon"
@@ -12,13 +16,70 @@ import (
 type BattlePokemon struct {
 	Base        *pokemon.Pokemon
 	CurrentHP   float64
+	Moves       []*pokemon.MoveInfo
 	MovePP      map[string]int
+	Spread      stats.StatSpread
+	Stats       stats.Block
+	Ability     string
+	Item        string
+	LostItem    string
+	ItemLoss    string
+	ChoiceLock  string
 	Status      string
 	StatusTurns int
-	Fainted     bool
-	StatStages  map[string]int
-	Volatile    map[string]bool
-	UniqueID    string
+	// ForcedMove is the move bp must use next turn: the strike of a charge
+	// move, a rampage in progress or a recharge turn.
+	ForcedMove string
+	// LastDamage and LastDamageClass record the last hit bp took this turn,
+	// for Counter and Mirror Coat.
+	LastDamage      int
+	LastDamageClass string
+	Fainted         bool
+	StatStages      map[string]int
+	Volatile        Volatiles
+	UniqueID        string
+	// rand is the generator of the field bp joined and side the name of the
+	// side it battles on.
+	rand *rand.Rand
+	side string
+}
+
+// VolatileEffect is a condition that lasts only while its Pokémon stays in
+// battle, along with the state the condition needs.
+type VolatileEffect struct {
+	// Turns counts the turns the effect has left or, for Protect's streak,
+	// how many times in a row it has succeeded.
+	Turns int
+	// HP is what a substitute has left to absorb.
+	HP float64
+	// Source is the move or ability that caused the effect.
+	Source string
+}
+
+// Volatiles maps each volatile effect on a Pokémon to its state.
+type Volatiles map[string]*VolatileEffect
+
+// Has reports whether the named effect is active.
+func (v Volatiles) Has(name string) bool {
+	return v[name] != nil
+}
+
+// Names lists the active effects, for views that only show what is active.
+func (v Volatiles) Names() map[string]bool {
+	names := make(map[string]bool, len(v))
+	for name := range v {
+		names[name] = true
+	}
+	return names
+}
+
+func (v Volatiles) clone() Volatiles {
+	c := make(Volatiles, len(v))
+	for name, effect := range v {
+		copied := *effect
+		c[name] = &copied
+	}
+	return c
 }
 
 type PokemonSummary struct {
@@ -31,8 +92,12 @@ type PokemonSummary struct {
 type PokemonFullView struct {
 	Name       string
 	Types      []string
+	Level      int
+	Stats      stats.Block
 	CurrentHP  float64
 	MaxHP      float64
+	Ability    string
+	Item       string
 	Status     string
 	StatStages map[string]int
 	Volatile   map[string]bool
@@ -72,33 +137,41 @@ func NewBattlePokemon(p *pokemon.Pokemon, moves []*pokemon.MoveInfo) *BattlePoke
 		}
 	}
 
-	baseHp := 0
-	if p.Stats != nil {
-		for _, stat := range p.Stats {
-			if stat.Stat.Name == "hp" {
-				baseHp = stat.BaseStat
-				break
-			}
-		}
-	} else {
+	if p.Stats == nil {
 		log.Printf("Warning: Pokemon %s has nil Stats field.", p.Name)
 	}
-	maxCalculatedHP := stats.HpCalc(baseHp)
+	spread := stats.DefaultSpread()
+	computed := stats.Compute(p, spread)
 
 	statStages := make(map[string]int)
 
 	return &BattlePokemon{
 		Base:       p,
-		CurrentHP:  maxCalculatedHP,
+		CurrentHP:  float64(computed.HP),
+		Spread:     spread,
+		Stats:      computed,
+		Moves:      moves,
 		MovePP:     movePP,
 		Status:     "",
 		Fainted:    false,
 		StatStages: statStages,
-		Volatile:   make(map[string]bool),
+		Volatile:   make(Volatiles),
 		UniqueID:   fmt.Sprintf("%s-%d", p.Name, time.Now().UnixNano()),
 	}
 }
 
+// Clone returns an independent copy of bp, still tied to the generator of
+// the field it joined. Its species and move data are shared, since battles
+// never change them.
+func (bp *BattlePokemon) Clone() *BattlePokemon {
+	c := *bp
+	c.Moves = slices.Clone(bp.Moves)
+	c.MovePP = maps.Clone(bp.MovePP)
+	c.StatStages = maps.Clone(bp.StatStages)
+	c.Volatile = bp.Volatile.clone()
+	return &c
+}
+
 func (bp *BattlePokemon) ApplyDamage(dmg float64) {
 	if bp.Fainted {
 		return
@@ -110,6 +183,87 @@ func (bp *BattlePokemon) ApplyDamage(dmg float64) {
 	}
 }
 
+// SetAbility gives the Pokémon an ability its species can legally have.
+func (bp *BattlePokemon) SetAbility(name string) error {
+	if !pokemon.HasAbility(bp.Base, name) {
+		return fmt.Errorf("%s cannot have the ability %s", bp.Base.Name, name)
+	}
+	bp.Ability = name
+	return nil
+}
+
+// SetSpread replaces the Pokémon's IVs, EVs, nature and level and
+// recomputes its stats, keeping the same fraction of HP.
+func (bp *BattlePokemon) SetSpread(spread stats.StatSpread) error {
+	if err := spread.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", bp.Base.Name, err)
+	}
+	fraction := 1.0
+	if before := bp.MaxHP(); before > 0 {
+		fraction = bp.CurrentHP / before
+	}
+	bp.Spread = spread
+	bp.Stats = stats.Compute(bp.Base, spread)
+	bp.CurrentHP = math.Ceil(fraction * float64(bp.Stats.HP))
+	return nil
+}
+
+// statBlock returns the computed stats, working them out from the default
+// spread for Pokémon built without NewBattlePokemon.
+func (bp *BattlePokemon) statBlock() stats.Block {
+	if bp.Stats.HP == 0 && bp.Base != nil {
+		if bp.Spread.Level == 0 {
+			bp.Spread = stats.DefaultSpread()
+		}
+		bp.Stats = stats.Compute(bp.Base, bp.Spread)
+	}
+	return bp.Stats
+}
+
+func (bp *BattlePokemon) stat(name string) float64 {
+	return float64(bp.statBlock().Get(name))
+}
+
+func (bp *BattlePokemon) level() int {
+	bp.statBlock()
+	return bp.Spread.Level
+}
+
+// MaxHP is the Pokémon's computed maximum HP.
+func (bp *BattlePokemon) MaxHP() float64 {
+	return bp.stat("hp")
+}
+
+// heal restores up to amount HP and returns how much it restored.
+func (bp *BattlePokemon) heal(amount float64) float64 {
+	if bp.Fainted || amount <= 0 {
+		return 0
+	}
+	before := bp.CurrentHP
+	bp.CurrentHP = math.Min(bp.MaxHP(), bp.CurrentHP+amount)
+	return bp.CurrentHP - before
+}
+
+// hurt takes dmg HP from bp and reports the loss, put down to cause, and
+// any faint.
+func (bp *BattlePokemon) hurt(dmg float64, cause string) []Event {
+	before := bp.CurrentHP
+	bp.ApplyDamage(dmg)
+	lost := before - bp.CurrentHP
+	events := []Event{Damage{Pokemon: bp.Base.Name, Side: bp.side, Amount: int(lost), Percent: lost / bp.MaxHP() * 100, Cause: cause}}
+	return faintCheck(bp, events)
+}
+
+// restore heals bp by amount and reports it, put down to cause, or returns
+// nil if bp was already at full HP.
+func (bp *BattlePokemon) restore(amount float64, cause string) []Event {
+	healed := bp.heal(amount)
+	if healed == 0 {
+		return nil
+	}
+	return []Event{Heal{Pokemon: bp.Base.Name, Side: bp.side, Amount: int(healed), Percent: healed / bp.MaxHP() * 100, Cause: cause}}
+}
+
 func (bp *BattlePokemon) UseMove(moveName string) bool {
 	if bp.Fainted {
 		return false
@@ -121,20 +275,6 @@ func (bp *BattlePokemon) UseMove(moveName string) bool {
 	return false
 }
 
-func (bp *BattlePokemon) ApplyStatus(newStatus string) {
-	if bp.Status == "" && !bp.Fainted {
-		bp.Status = newStatus
-		bp.StatusTurns = 0
-	}
-}
-
-func (bp *BattlePokemon) ApplyStatusWithDuration(status string, turns int) {
-	if bp.Status == "" && !bp.Fainted {
-		bp.Status = status
-		bp.StatusTurns = turns
-	}
-}
-
 func (bp *BattlePokemon) ApplyStatStage(stat string, change int) {
 	currentStage := bp.StatStages[stat]
 	newStage := currentStage + change
@@ -147,9 +287,34 @@ func (bp *BattlePokemon) ApplyStatStage(stat string, change int) {
 	bp.StatStages[stat] = newStage
 }
 
+// changeStage applies a stat stage change and reports how far the stage
+// moved.
+func (bp *BattlePokemon) changeStage(stat string, change int) []Event {
+	if bp.StatStages == nil {
+		bp.StatStages = make(map[string]int)
+	}
+	before := bp.StatStages[stat]
+	bp.ApplyStatStage(stat, change)
+	return []Event{StatChange{Pokemon: bp.Base.Name, Side: bp.side, Stat: stat, Change: change, Applied: bp.StatStages[stat] - before}}
+}
+
+// setVolatile starts the named effect on bp, replacing any earlier state,
+// and returns it so callers can record more.
+func (bp *BattlePokemon) setVolatile(name, source string, turns int) *VolatileEffect {
+	effect := &VolatileEffect{Turns: turns, Source: source}
+	if bp.Fainted {
+		return effect
+	}
+	if bp.Volatile == nil {
+		bp.Volatile = make(Volatiles)
+	}
+	bp.Volatile[name] = effect
+	return effect
+}
+
 func (bp *BattlePokemon) ApplyVolatileEffect(effect string) {
-	if !bp.Fainted {
-		bp.Volatile[effect] = true
+	if !bp.Volatile.Has(effect) {
+		bp.setVolatile(effect, "", 0)
 	}
 }
 
@@ -171,61 +336,49 @@ func GetPokemonFullView(p *BattlePokemon) PokemonFullView {
 		}
 	}
 
-	maxHP := 0.0
-	if p.Base.Stats != nil {
-		for _, stat := range p.Base.Stats {
-			if stat.Stat.Name == "hp" {
-				maxHP = stats.HpCalc(stat.BaseStat)
-				break
-			}
-		}
-	}
-
-	moveViews := []MoveView{}
-	if p.Base.Moves != nil {
-		moveViews = make([]MoveView, 0, len(p.Base.Moves))
-		for _, moveSlot := range p.Base.Moves {
-			moveName := moveSlot.Move.Name
-			moveInfo, err := pokemon.FetchMoveByName(moveName)
-			if err != nil || moveInfo == nil {
-				log.Printf("Error fetching move details for %s: %v", moveName, err)
-				continue
-			}
+	maxHP := p.MaxHP()
 
-			currentPP := 0
-			if pp, ok := p.MovePP[moveName]; ok {
-				currentPP = pp
-			}
+	moveViews := make([]MoveView, 0, len(p.Moves))
+	for _, moveInfo := range p.Moves {
+		if moveInfo == nil {
+			continue
+		}
 
-			moveViews = append(moveViews, MoveView{
-				Name:     moveName,
-				Type:     moveInfo.Type.Name,
-				Power:    moveInfo.Power,
-				Accuracy: moveInfo.Accuracy,
-				PP:       currentPP,
-				MaxPP:    moveInfo.Pp,
-				Category: moveInfo.DamageClass.Name,
-			})
+		currentPP := 0
+		if pp, ok := p.MovePP[moveInfo.Name]; ok {
+			currentPP = pp
 		}
+
+		moveViews = append(moveViews, MoveView{
+			Name:     moveInfo.Name,
+			Type:     moveInfo.Type.Name,
+			Power:    moveInfo.Power,
+			Accuracy: moveInfo.Accuracy,
+			PP:       currentPP,
+			MaxPP:    moveInfo.Pp,
+			Category: moveInfo.DamageClass.Name,
+		})
 	}
 
 	statStagesCopy := make(map[string]int, len(p.StatStages))
 	for k, v := range p.StatStages {
-		statStagesCopy[k] = v
-	}
-	volatileCopy := make(map[string]bool, len(p.Volatile))
-	for k, v := range p.Volatile {
-		volatileCopy[k] = v
+		if v != 0 {
+			statStagesCopy[k] = v
+		}
 	}
 
 	return PokemonFullView{
 		Name:       p.Base.Name,
 		Types:      types,
+		Level:      p.level(),
+		Stats:      p.statBlock(),
 		CurrentHP:  p.CurrentHP,
 		MaxHP:      maxHP,
+		Ability:    p.Ability,
+		Item:       p.Item,
 		Status:     p.Status,
 		StatStages: statStagesCopy,
-		Volatile:   volatileCopy,
+		Volatile:   p.Volatile.Names(),
 		Moves:      moveViews,
 	}
 }
@@ -244,15 +397,7 @@ func GetPokemonLimitedView(p *BattlePokemon) PokemonLimitedView {
 		}
 	}
 
-	maxHP := 0.0
-	if p.Base.Stats != nil {
-		for _, stat := range p.Base.Stats {
-			if stat.Stat.Name == "hp" {
-				maxHP = stats.HpCalc(stat.BaseStat)
-				break
-			}
-		}
-	}
+	maxHP := p.MaxHP()
 
 	hpPercent := 0.0
 	if maxHP > 0 {
@@ -266,8 +411,13 @@ func GetPokemonLimitedView(p *BattlePokemon) PokemonLimitedView {
 	}
 
 	visibleVolatile := make(map[string]bool)
-	if p.Volatile["confused"] {
-		visibleVolatile["confused"] = true
+	for _, name := range []string{"confusion", "substitute", "protect"} {
+		if p.Volatile.Has(name) {
+			visibleVolatile[name] = true
+		}
+	}
+	if hidden := p.hiddenState(); hidden != "" {
+		visibleVolatile[hidden] = true
 	}
 
 	return PokemonLimitedView{
@@ -291,15 +441,7 @@ func GetTeamSummary(team []*BattlePokemon) []PokemonSummary {
 			continue
 		}
 
-		maxHP := 0.0
-		if pokemon.Base.Stats != nil {
-			for _, stat := range pokemon.Base.Stats {
-				if stat.Stat.Name == "hp" {
-					maxHP = stats.HpCalc(stat.BaseStat)
-					break
-				}
-			}
-		}
+		maxHP := pokemon.MaxHP()
 
 		hpPercent := 0.0
 		if maxHP > 0 {
@@ -333,104 +475,3 @@ func IsAllFainted(team []*BattlePokemon) bool {
 	}
 	return true
 }
-
-// func (bp *BattlePokemon) HandleTurnEffects(weather string, fieldEffects map[string]int) []string {
-// 	turnEvents := []string{}
-// 	if bp.Fainted {
-// 		return turnEvents
-// 	}
-//
-// 	maxHP := 0.0
-// 	hpStatFound := false
-// 	if bp.Base != nil && bp.Base.Stats != nil {
-// 		for _, stat := range bp.Base.Stats {
-// 			if stat.Stat.Name == "hp" {
-// 				maxHP = stats.HpCalc(stat.BaseStat)
-// 				hpStatFound = true
-// 				break
-// 			}
-// 		}
-// 	}
-// 	if !hpStatFound {
-// 		log.Printf("Warning: Could not find Max HP for %s during HandleTurnEffects.", bp.Base.Name)
-// 	}
-//
-// 	statusDamage := 0.0
-// 	statusMsg := ""
-// 	switch bp.Status {
-// 	case "psn":
-// 		if maxHP > 0 {
-// 			statusDamage = maxHP / 8.0
-// 		}
-// 		statusMsg = fmt.Sprintf("%s took damage from poison!", bp.Base.Name)
-// 	case "tox":
-// 		if maxHP > 0 {
-// 			statusDamage = maxHP / 8.0
-// 		}
-// 		statusMsg = fmt.Sprintf("%s took damage from poison!", bp.Base.Name)
-// 	case "brn":
-// 		if maxHP > 0 {
-// 			statusDamage = maxHP / 16.0
-// 		}
-// 		statusMsg = fmt.Sprintf("%s took damage from its burn!", bp.Base.Name)
-// 	}
-// 	if statusDamage > 0 {
-// 		bp.ApplyDamage(statusDamage)
-// 		turnEvents = append(turnEvents, statusMsg)
-// 		if bp.Fainted {
-// 			turnEvents = append(turnEvents, fmt.Sprintf("%s fainted!", bp.Base.Name))
-// 			return turnEvents
-// 		}
-// 	}
-//
-// 	if weather == "sandstorm" {
-// 		isImmune := false
-// 		if bp.Base != nil && bp.Base.Types != nil {
-// 			for _, t := range bp.Base.Types {
-// 				switch t.Type.Name {
-// 				case "rock", "ground", "steel":
-// 					isImmune = true
-// 					break
-// 				}
-// 			}
-// 		}
-// 		if !isImmune && maxHP > 0 {
-// 			weatherDmg := maxHP / 16.0
-// 			bp.ApplyDamage(weatherDmg)
-// 			turnEvents = append(turnEvents, fmt.Sprintf("%s took damage from the sandstorm!", bp.Base.Name))
-// 			if bp.Fainted {
-// 				turnEvents = append(turnEvents, fmt.Sprintf("%s fainted!", bp.Base.Name))
-// 				return turnEvents
-// 			}
-// 		}
-// 	}
-//
-// 	isGrounded := true
-// 	if effectTurns, ok := fieldEffects["grassy-terrain"]; ok && effectTurns > 0 && isGrounded {
-// 		if maxHP > 0 {
-// 			healAmt := maxHP / 16.0
-// 			bp.CurrentHP += healAmt
-// 			if bp.CurrentHP > maxHP {
-// 				bp.CurrentHP = maxHP
-// 			}
-// 			turnEvents = append(turnEvents, fmt.Sprintf("%s recovered some HP from the Grassy Terrain!", bp.Base.Name))
-// 		}
-// 	}
-//
-// 	if bp.Status == "slp" {
-// 		if bp.StatusTurns > 0 {
-// 			bp.StatusTurns--
-// 			if bp.StatusTurns == 0 {
-// 				bp.Status = ""
-// 				turnEvents = append(turnEvents, fmt.Sprintf("%s woke up!", bp.Base.Name))
-// 			} else {
-// 			}
-// 		} else {
-// 			bp.Status = ""
-// 			turnEvents = append(turnEvents, fmt.Sprintf("%s woke up! (Forced)", bp.Base.Name))
-// 		}
-// 	}
-//
-// 	return turnEvents
-// }
-
@@ -0,0 +1,164 @@
+package battle
+
+import "math"
+
+// ailmentStatus maps PokeAPI move ailments to the engine's major statuses.
+var ailmentStatus = map[string]string{
+	"paralysis": "par",
+	"sleep":     "slp",
+	"freeze":    "frz",
+	"burn":      "brn",
+	"poison":    "psn",
+}
+
+// statusImmuneTypes lists the types that can never gain a status.
+var statusImmuneTypes = map[string][]string{
+	"brn": {"fire"},
+	"frz": {"ice"},
+	"par": {"electric"},
+	"psn": {"poison", "steel"},
+	"tox": {"poison", "steel"},
+}
+
+// defrostMoves thaw a frozen user that uses them and, like any Fire move,
+// a frozen target they hit.
+var defrostMoves = map[string]bool{
+	"flame-wheel":     true,
+	"sacred-fire":     true,
+	"flare-blitz":     true,
+	"fusion-flare":    true,
+	"burn-up":         true,
+	"pyro-ball":       true,
+	"scald":           true,
+	"steam-eruption":  true,
+	"scorching-sands": true,
+}
+
+func statusImmune(bp *BattlePokemon, status string) bool {
+	return hasAnyType(bp, statusImmuneTypes[status])
+}
+
+func (bp *BattlePokemon) ApplyStatus(newStatus string) {
+	if bp.Status == "" && !bp.Fainted {
+		bp.Status = newStatus
+		bp.StatusTurns = 0
+	}
+}
+
+// inflictStatus gives the Pokémon a major status, put down to cause and
+// source as StatusApplied describes, and lets its held item react, as Lum
+// Berry does. Sleep lasts one to three turns.
+func (bp *BattlePokemon) inflictStatus(status, cause, source string) []Event {
+	if bp.Status != "" || bp.Fainted {
+		return nil
+	}
+	bp.ApplyStatus(status)
+	if status == "slp" {
+		bp.StatusTurns = 1 + bp.rng().IntN(3)
+	}
+	events := []Event{StatusApplied{Pokemon: bp.Base.Name, Side: bp.side, Status: status, Cause: cause, Source: source}}
+	if hook := bp.item().OnStatus; hook != nil {
+		events = append(events, hook(bp)...)
+	}
+	return events
+}
+
+// confusionTurns rolls how long confusion lasts for bp. The count runs down
+// each time bp tries to move, so it acts confused for one to four turns.
+func confusionTurns(bp *BattlePokemon) int {
+	return 2 + bp.rng().IntN(4)
+}
+
+// statusPreventsMove rolls whether bp's major status stops it moving this
+// turn. Sleep counts down only here, on the turns bp tries to move.
+func statusPreventsMove(bp *BattlePokemon) (bool, []Event) {
+	switch bp.Status {
+	case "slp":
+		if bp.StatusTurns <= 0 {
+			bp.Status = ""
+			return false, []Event{StatusCured{Pokemon: bp.Base.Name, Side: bp.side, Status: "slp"}}
+		}
+		bp.StatusTurns--
+		return true, []Event{CantMove{Pokemon: bp.Base.Name, Side: bp.side, Reason: "slp"}}
+	case "frz":
+		if bp.rng().Float64() < 0.2 {
+			bp.Status = ""
+			return false, []Event{StatusCured{Pokemon: bp.Base.Name, Side: bp.side, Status: "frz"}}
+		}
+		return true, []Event{CantMove{Pokemon: bp.Base.Name, Side: bp.side, Reason: "frz"}}
+	case "par":
+		if bp.rng().Float64() < 0.25 {
+			return true, []Event{CantMove{Pokemon: bp.Base.Name, Side: bp.side, Reason: "par"}}
+		}
+	}
+	return false, nil
+}
+
+// confusionPreventsMove counts bp's confusion down and rolls whether it
+// hurts itself instead of moving.
+func confusionPreventsMove(bp *BattlePokemon) (bool, []Event) {
+	confusion := bp.Volatile["confusion"]
+	if confusion == nil {
+		return false, nil
+	}
+	confusion.Turns--
+	if confusion.Turns <= 0 {
+		bp.RemoveVolatileEffect("confusion")
+		return false, []Event{StatusCured{Pokemon: bp.Base.Name, Side: bp.side, Status: "confusion"}}
+	}
+	events := []Event{Activate{Pokemon: bp.Base.Name, Side: bp.side, Effect: "confusion"}}
+	if bp.rng().Float64() >= 1.0/3 {
+		return false, events
+	}
+	return true, append(events, bp.hurt(confusionDamage(bp), "confusion")...)
+}
+
+// confusionDamage is the typeless 40 power physical hit a confused Pokémon
+// deals itself with its own Attack and Defense. It never crits.
+func confusionDamage(bp *BattlePokemon) float64 {
+	atk := bp.stat("attack") * stageMultiplier(bp.StatStages["attack"])
+	def := bp.stat("defense") * stageMultiplier(bp.StatStages["defense"])
+	if def <= 0 {
+		return 1
+	}
+	base := ((2.0*float64(bp.level())/5.0+2.0)*40*atk/def)/50.0 + 2.0
+	return math.Max(1, math.Floor(base*(0.85+bp.rng().Float64()*0.15)))
+}
+
+// thawUser lets a frozen Pokémon thaw itself with a defrosting move.
+func thawUser(bp *BattlePokemon, moveName string) []Event {
+	if bp.Status != "frz" || !defrostMoves[moveName] {
+		return nil
+	}
+	bp.Status = ""
+	return []Event{StatusCured{Pokemon: bp.Base.Name, Side: bp.side, Status: "frz", Cause: moveName}}
+}
+
+// thawTarget thaws a frozen defender hit by a Fire or defrosting move.
+func thawTarget(defender *BattlePokemon, moveName, moveType string) []Event {
+	if defender.Status != "frz" || defender.Fainted || moveType != "fire" && !defrostMoves[moveName] {
+		return nil
+	}
+	defender.Status = ""
+	return []Event{StatusCured{Pokemon: defender.Base.Name, Side: defender.side, Status: "frz"}}
+}
+
+// statusResidual deals burn and poison damage at the end of the turn. Toxic
+// hurts one sixteenth more each turn, up to fifteen sixteenths.
+func statusResidual(bp *BattlePokemon) []Event {
+	maxHP := bp.MaxHP()
+	var dmg float64
+	switch bp.Status {
+	case "brn":
+		dmg = maxHP / 16
+	case "psn":
+		dmg = maxHP / 8
+	case "tox":
+		bp.StatusTurns = min(bp.StatusTurns+1, 15)
+		dmg = float64(bp.StatusTurns) * maxHP / 16
+	}
+	if dmg <= 0 {
+		return nil
+	}
+	return bp.hurt(dmg, bp.Status)
+}
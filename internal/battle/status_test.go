@@ -0,0 +1,161 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestSleepLastsOneToThreeTurns(t *testing.T) {
+	for range 30 {
+		snorlax := newBattler(t, "snorlax", "", "tackle")
+		gengar := newBattler(t, "gengar", "", "hypnosis")
+		hypnosis := *gengar.Moves[0]
+		hypnosis.Accuracy = 0
+
+		battle.Act(gengar, snorlax, &hypnosis, nil)
+		if snorlax.Status != "slp" {
+			t.Fatalf("Expected Hypnosis to put Snorlax to sleep, got %q", snorlax.Status)
+		}
+		asleep := 0
+		for snorlax.Status == "slp" && asleep <= 3 {
+			events := battle.Act(snorlax, gengar, snorlax.Moves[0], nil)
+			if hasEvent(events, "fast asleep") {
+				asleep++
+			}
+			snorlax.HandleTurnEffects()
+		}
+		if asleep < 1 || asleep > 3 {
+			t.Fatalf("Expected sleep to last one to three turns, slept %d", asleep)
+		}
+	}
+}
+
+func TestFireMovesThawFrozenPokemon(t *testing.T) {
+	charizard := newBattler(t, "charizard", "", "flamethrower", "flare-blitz")
+	snorlax := newBattler(t, "snorlax", "")
+
+	snorlax.Status = "frz"
+	events := battle.Act(charizard, snorlax, charizard.Moves[0], nil)
+	if snorlax.Status == "frz" || !hasEvent(events, "thawed out") {
+		t.Errorf("Expected Flamethrower to thaw Snorlax, still %q: %v", snorlax.Status, events)
+	}
+
+	charizard.Status = "frz"
+	events = battle.Act(charizard, snorlax, charizard.Moves[1], nil)
+	if charizard.Status != "" || !hasEvent(events, "melted the ice") {
+		t.Errorf("Expected Flare Blitz to thaw its frozen user, still %q: %v", charizard.Status, events)
+	}
+}
+
+func TestConfusionLastsOneToFourTurns(t *testing.T) {
+	for range 20 {
+		machamp := newBattler(t, "machamp", "", "tackle")
+		gengar := newBattler(t, "gengar", "", "confuse-ray")
+
+		battle.Act(gengar, machamp, gengar.Moves[0], nil)
+		if !machamp.Volatile.Has("confusion") {
+			t.Fatal("Expected Confuse Ray to confuse Machamp")
+		}
+		confused := 0
+		for machamp.Volatile.Has("confusion") && confused <= 4 {
+			machamp.CurrentHP = machamp.MaxHP()
+			events := battle.Act(machamp, gengar, machamp.Moves[0], nil)
+			if hasEvent(events, "is confused") {
+				confused++
+			}
+		}
+		if confused < 1 || confused > 4 {
+			t.Fatalf("Expected confusion to last one to four turns, lasted %d", confused)
+		}
+	}
+}
+
+func TestConfusionSelfHitUsesAttackStages(t *testing.T) {
+	machamp := newBattler(t, "machamp", "", "tackle")
+	gengar := newBattler(t, "gengar", "", "confuse-ray")
+	machamp.StatStages["attack"] = 6
+
+	for range 200 {
+		if !machamp.Volatile.Has("confusion") {
+			gengar.MovePP["confuse-ray"] = 10
+			battle.Act(gengar, machamp, gengar.Moves[0], nil)
+		}
+		machamp.CurrentHP = machamp.MaxHP()
+		machamp.MovePP["tackle"] = 35
+		events := battle.Act(machamp, gengar, machamp.Moves[0], nil)
+		if !hasEvent(events, "hurt itself") {
+			continue
+		}
+		if lost := machamp.MaxHP() - machamp.CurrentHP; lost < machamp.MaxHP()/4 {
+			t.Errorf("Expected a +6 Attack self-hit to do more than a quarter of max HP, did %.0f", lost)
+		}
+		return
+	}
+	t.Fatal("Expected Machamp to hurt itself in its confusion eventually")
+}
+
+func TestToxicCounterResetsOnSwitch(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "")
+	snorlax.Status = "tox"
+	maxHP := snorlax.MaxHP()
+
+	snorlax.HandleTurnEffects()
+	snorlax.HandleTurnEffects()
+	if lost := maxHP - snorlax.CurrentHP; lost != 3*maxHP/16 {
+		t.Fatalf("Expected two turns of Toxic to take 1/16 then 2/16, lost %.1f", lost)
+	}
+
+	battle.SwitchOut(snorlax)
+	hp := snorlax.CurrentHP
+	snorlax.HandleTurnEffects()
+	if lost := hp - snorlax.CurrentHP; lost != maxHP/16 {
+		t.Errorf("Expected the Toxic counter to restart after switching, lost %.1f", lost)
+	}
+	if snorlax.Status != "tox" {
+		t.Errorf("Expected switching to keep the poison, got %q", snorlax.Status)
+	}
+}
+
+func TestTypesResistTheirStatuses(t *testing.T) {
+	cases := []struct {
+		target, user, move string
+	}{
+		{"charizard", "gengar", "will-o-wisp"},
+		{"raichu", "alakazam", "thunder-wave"},
+		{"gengar", "venusaur", "toxic"},
+		{"metagross", "venusaur", "toxic"},
+	}
+	for _, tc := range cases {
+		target := newBattler(t, tc.target, "")
+		user := newBattler(t, tc.user, "", tc.move)
+		move := *user.Moves[0]
+		move.Accuracy = 0
+
+		events := battle.Act(user, target, &move, nil)
+		if target.Status != "" {
+			t.Errorf("Expected %s to be immune to %s, got %q: %v", tc.target, tc.move, target.Status, events)
+		}
+	}
+}
+
+func TestStatusMovesRespectTypeImmunities(t *testing.T) {
+	cases := []struct {
+		target, user, move string
+	}{
+		{"golem", "raichu", "thunder-wave"},
+		{"snorlax", "gengar", "confuse-ray"},
+	}
+	for _, tc := range cases {
+		target := newBattler(t, tc.target, "")
+		user := newBattler(t, tc.user, "", tc.move)
+
+		events := battle.Act(user, target, user.Moves[0], nil)
+		if target.Status != "" || target.Volatile.Has("confusion") {
+			t.Errorf("Expected %s not to be affected by %s, got %q %v", tc.target, tc.move, target.Status, target.Volatile.Names())
+		}
+		if len(events) == 0 || events[len(events)-1] != (battle.Immune{Pokemon: tc.target}) {
+			t.Errorf("Expected %s to be immune to %s, got %v", tc.target, tc.move, events)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package battle
+
+import (
+	"math"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// Struggle is the move a Pokémon falls back on when none of its moves has
+// PP left. It is typeless, so it hits every type for neutral damage, needs
+// no PP and costs the user a quarter of its max HP in recoil.
+var Struggle = &pokemon.MoveInfo{
+	Name:        "struggle",
+	Power:       50,
+	DamageClass: pokemon.ApiResource{Name: "physical"},
+	Type:        pokemon.ApiResource{Name: "typeless"},
+	Target:      pokemon.ApiResource{Name: "random-opponent"},
+	Meta:        pokemon.MoveMeta{Category: pokemon.ApiResource{Name: "damage"}},
+}
+
+// MustStruggle reports whether bp has no move it can pick, either because
+// every move is out of PP or because the move it is Choice locked into is.
+func (bp *BattlePokemon) MustStruggle() bool {
+	if bp.Fainted || bp.LockedIn() {
+		return false
+	}
+	if locked := bp.LockedMove(); locked != "" {
+		return bp.MovePP[locked] <= 0
+	}
+	for _, pp := range bp.MovePP {
+		if pp > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// struggleRecoil takes a quarter of the user's max HP after Struggle.
+func struggleRecoil(user *BattlePokemon) []Event {
+	if user.Fainted {
+		return nil
+	}
+	return user.hurt(math.Max(1, math.Floor(user.MaxHP()/4)), "recoil")
+}
@@ -0,0 +1,44 @@
+package battle_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestStruggleOnceEveryMoveIsOutOfPP(t *testing.T) {
+	snorlax := newBattler(t, "snorlax", "", "tackle", "body-slam")
+	gengar := newBattler(t, "gengar", "")
+	if snorlax.MustStruggle() {
+		t.Fatal("Expected Snorlax with PP left not to struggle")
+	}
+	snorlax.MovePP["tackle"] = 0
+	snorlax.MovePP["body-slam"] = 0
+	if !snorlax.MustStruggle() {
+		t.Fatal("Expected Snorlax with no PP left to struggle")
+	}
+
+	events := battle.Act(snorlax, gengar, battle.Struggle, nil)
+	if gengar.CurrentHP == gengar.MaxHP() {
+		t.Errorf("Expected typeless Struggle to hit Gengar, got %v", events)
+	}
+	if recoil := snorlax.MaxHP() - snorlax.CurrentHP; recoil != float64(int(snorlax.MaxHP()/4)) {
+		t.Errorf("Expected a quarter of max HP in recoil, took %.0f: %v", recoil, events)
+	}
+}
+
+func TestStruggleWhenChoiceLockedMoveIsOutOfPP(t *testing.T) {
+	machamp := holding(t, newBattler(t, "machamp", "", "tackle", "close-combat"), "choice-band")
+	snorlax := newBattler(t, "snorlax", "")
+
+	battle.Act(machamp, snorlax, machamp.Moves[0], nil)
+	machamp.MovePP["tackle"] = 0
+	if !machamp.MustStruggle() {
+		t.Fatal("Expected Machamp locked into a move without PP to struggle")
+	}
+	hp := snorlax.CurrentHP
+	events := battle.Act(machamp, snorlax, battle.Struggle, nil)
+	if snorlax.CurrentHP == hp || machamp.LockedMove() != "tackle" {
+		t.Errorf("Expected Struggle to go through the Choice lock and leave it in place, got %v", events)
+	}
+}
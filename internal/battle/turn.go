@@ -1,22 +1,26 @@
 package battle
 
-import (
-	"fmt"
-	"math/rand/v2"
+import "github.com/ross1116/pokebattlecli/internal/pokemon"
 
-	"github.com/ross1116/pokebattlecli/internal/pokemon"
-	"github.com/ross1116/pokebattlecli/internal/stats"
-)
-
-func ResolveTurn(player *BattlePokemon, enemy *BattlePokemon, playerMove *pokemon.MoveInfo, enemyMove *pokemon.MoveInfo) (*BattlePokemon, *BattlePokemon, *pokemon.MoveInfo, *pokemon.MoveInfo) {
+func ResolveTurn(player *BattlePokemon, enemy *BattlePokemon, playerMove *pokemon.MoveInfo, enemyMove *pokemon.MoveInfo, field *Field) (*BattlePokemon, *BattlePokemon, *pokemon.MoveInfo, *pokemon.MoveInfo) {
 	playerPriority := getMovePriority(playerMove)
 	enemyPriority := getMovePriority(enemyMove)
 
 	if playerPriority == enemyPriority {
-		playerSpeed := stats.StatCalc(stats.GetStat(player.Base, "speed"))
-		enemySpeed := stats.StatCalc(stats.GetStat(enemy.Base, "speed"))
+		playerFirst, enemyFirst := movesFirst(player), movesFirst(enemy)
+		if playerFirst && !enemyFirst {
+			return player, enemy, playerMove, enemyMove
+		}
+		if enemyFirst && !playerFirst {
+			return enemy, player, enemyMove, playerMove
+		}
+		playerSpeed := effectiveSpeed(player, field)
+		enemySpeed := effectiveSpeed(enemy, field)
+		if field.trickRoom() {
+			playerSpeed, enemySpeed = enemySpeed, playerSpeed
+		}
 		if playerSpeed == enemySpeed {
-			if rand.Float64() < 0.5 {
+			if player.rng().Float64() < 0.5 {
 				return player, enemy, playerMove, enemyMove
 			} else {
 				return enemy, player, enemyMove, playerMove
@@ -35,6 +39,37 @@ func ResolveTurn(player *BattlePokemon, enemy *BattlePokemon, playerMove *pokemo
 	}
 }
 
+// effectiveSpeed is the speed bp moves at this turn: its stat after stages,
+// paralysis, Tailwind, ability and item.
+func effectiveSpeed(bp *BattlePokemon, field *Field) float64 {
+	speed := bp.stat("speed") * stageMultiplier(bp.StatStages["speed"])
+	if bp.Status == "par" {
+		speed /= 2
+	}
+	if field.sideHas(bp, "tailwind") {
+		speed *= 2
+	}
+	if hook := bp.ability().ModifySpeed; hook != nil {
+		speed *= hook(bp, field)
+	}
+	if hook := bp.item().ModifySpeed; hook != nil {
+		speed *= hook(bp, field)
+	}
+	return speed
+}
+
+// movesFirst rolls the Pokémon's held item, such as Quick Claw, for moving
+// ahead of its priority bracket. A successful roll is announced when the
+// Pokémon acts.
+func movesFirst(bp *BattlePokemon) bool {
+	hook := bp.item().MovesFirst
+	if hook == nil || !hook(bp) {
+		return false
+	}
+	bp.setVolatile("quick-claw", bp.Item, 0)
+	return true
+}
+
 func getMovePriority(move *pokemon.MoveInfo) int {
 	if move != nil {
 		return move.Priority
@@ -42,174 +77,206 @@ func getMovePriority(move *pokemon.MoveInfo) int {
 	return 0
 }
 
-func (bp *BattlePokemon) HandleTurnEffects() []string {
-	events := []string{}
+func (bp *BattlePokemon) HandleTurnEffects() []Event {
+	events := []Event{}
+	bp.LastDamage, bp.LastDamageClass = 0, ""
 	if bp.Fainted {
 		return events
 	}
-	maxHP := 0.0
-	if bp.Base != nil && bp.Base.Stats != nil {
-		for _, stat := range bp.Base.Stats {
-			if stat.Stat.Name == "hp" {
-				maxHP = stats.HpCalc(stat.BaseStat)
-				break
-			}
-		}
+	events = append(events, statusResidual(bp)...)
+	if bp.Fainted {
+		return events
 	}
 
-	statusDamage := 0.0
-	statusMsg := ""
-	switch bp.Status {
-	case "brn":
-		if maxHP > 0 {
-			statusDamage = maxHP / 16.0
-		}
-		statusMsg = fmt.Sprintf("%s took damage from its burn!", bp.Base.Name)
-	case "psn":
-		if maxHP > 0 {
-			statusDamage = maxHP / 8.0
-		}
-		statusMsg = fmt.Sprintf("%s took damage from poison!", bp.Base.Name)
-	case "tox":
-		stacks := 1
-		if bp.StatusTurns > 0 {
-			stacks = bp.StatusTurns
-		}
-		if maxHP > 0 {
-			statusDamage = float64(stacks) * maxHP / 16.0
-		}
-		statusMsg = fmt.Sprintf("%s took heavy damage from poison!", bp.Base.Name)
-		bp.StatusTurns++
+	for _, effect := range []string{"flinch", "protect", "endure", "roost"} {
+		bp.RemoveVolatileEffect(effect)
+	}
+
+	if hook := bp.ability().EndOfTurn; hook != nil {
+		events = append(events, hook(bp)...)
 	}
-	if statusDamage > 0 {
-		bp.ApplyDamage(statusDamage)
-		events = append(events, statusMsg)
+	if hook := bp.item().EndOfTurn; hook != nil && !bp.Fainted {
+		events = append(events, hook(bp)...)
 		if bp.Fainted {
-			events = append(events, fmt.Sprintf("%s fainted!", bp.Base.Name))
 			return events
 		}
 	}
 
-	switch bp.Status {
-	case "slp":
-		if bp.StatusTurns > 0 {
-			bp.StatusTurns--
-			if bp.StatusTurns == 0 {
-				bp.Status = ""
-				events = append(events, fmt.Sprintf("%s woke up!", bp.Base.Name))
-			}
-		} else {
-			bp.Status = ""
-			events = append(events, fmt.Sprintf("%s woke up! (Forced)", bp.Base.Name))
-		}
-	case "frz":
-		break
-	}
 	return events
 }
 
-func (bp *BattlePokemon) CanAct() (bool, []string) {
-	events := []string{}
+func (bp *BattlePokemon) CanAct() (bool, []Event) {
+	events := []Event{}
 	if bp.Fainted {
 		return false, events
 	}
-	if bp.Volatile["flinch"] {
-		events = append(events, fmt.Sprintf("%s flinched and couldn't move!", bp.Base.Name))
+	if bp.Volatile.Has("flinch") {
+		events = append(events, CantMove{Pokemon: bp.Base.Name, Side: bp.side, Reason: "flinch"})
 		bp.RemoveVolatileEffect("flinch")
 		return false, events
 	}
-	switch bp.Status {
-	case "slp":
-		if bp.StatusTurns > 0 {
-			events = append(events, fmt.Sprintf("%s is fast asleep.", bp.Base.Name))
-			return false, events
-		} else {
-			bp.Status = ""
-			events = append(events, fmt.Sprintf("%s woke up!", bp.Base.Name))
-		}
-	case "frz":
-		if rand.Float64() < 0.2 {
-			bp.Status = ""
-			events = append(events, fmt.Sprintf("%s thawed out!", bp.Base.Name))
-		} else {
-			events = append(events, fmt.Sprintf("%s is frozen solid!", bp.Base.Name))
-			return false, events
-		}
-	case "par":
-		if rand.Float64() < 0.25 {
-			events = append(events, fmt.Sprintf("%s is paralyzed! It can't move!", bp.Base.Name))
-			return false, events
-		}
-	}
-	if bp.Volatile["confusion"] {
-		events = append(events, fmt.Sprintf("%s is confused!", bp.Base.Name))
-		if rand.Float64() < 0.33 {
-			events = append(events, "It hurt itself in its confusion!")
-			maxHP := 0.0
-			hpStatFound := false
-			if bp.Base != nil && bp.Base.Stats != nil {
-				for _, stat := range bp.Base.Stats {
-					if stat.Stat.Name == "hp" {
-						maxHP = stats.HpCalc(stat.BaseStat)
-						hpStatFound = true
-						break
-					}
-				}
-			}
-			if hpStatFound && maxHP > 0 {
-				dmg := maxHP / 16.0
-				bp.ApplyDamage(dmg)
-				if bp.Fainted {
-					events = append(events, fmt.Sprintf("%s fainted!", bp.Base.Name))
-				}
-			} else {
-				bp.ApplyDamage(10)
-				events = append(events, "(Took confusion damage)")
-			}
-			return false, events
-		}
+	blocked, statusEvents := statusPreventsMove(bp)
+	events = append(events, statusEvents...)
+	if blocked {
+		return false, events
 	}
-	return true, events
-}
-
-func ProcessPlayerTurn(player *BattlePokemon, enemy *BattlePokemon, move *pokemon.MoveInfo) []string {
-	return processAction(player, enemy, move)
-}
-
-func ProcessEnemyTurn(player *BattlePokemon, enemy *BattlePokemon, move *pokemon.MoveInfo) []string {
-	return processAction(enemy, player, move)
+	blocked, confusionEvents := confusionPreventsMove(bp)
+	events = append(events, confusionEvents...)
+	return !blocked, events
 }
 
-func processAction(attacker *BattlePokemon, defender *BattlePokemon, move *pokemon.MoveInfo) []string {
-	events := []string{}
-	if attacker == nil || defender == nil || move == nil || attacker.Fainted {
+// processAction has attacker use move on defender, from the checks that can
+// stop it acting through the move's hit and effects.
+func processAction(attacker *BattlePokemon, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) []Event {
+	events := []Event{}
+	if attacker == nil || defender == nil || move == nil || attacker.Fainted || defender.Fainted {
 		return events
 	}
+	if attacker.Volatile.Has("quick-claw") {
+		attacker.RemoveVolatileEffect("quick-claw")
+		events = append(events, Activate{Pokemon: attacker.Base.Name, Side: attacker.side, Effect: "quick-claw"})
+	}
+	if rechargeEvents, recharging := rechargeTurn(attacker); recharging {
+		return append(events, rechargeEvents...)
+	}
+	events = append(events, thawUser(attacker, move.Name)...)
 	canAct, preEvents := attacker.CanAct()
 	events = append(events, preEvents...)
 	if !canAct {
+		interruptMove(attacker)
+		return events
+	}
+	struggling := move.Name == Struggle.Name
+	if locked := attacker.LockedMove(); locked != "" && locked != move.Name && !struggling {
+		events = append(events, CantMove{Pokemon: attacker.Base.Name, Side: attacker.side, Reason: "locked", Move: locked})
 		return events
 	}
-	if !attacker.UseMove(move.Name) {
-		events = append(events, fmt.Sprintf("%s has no PP left for %s!", attacker.Base.Name, move.Name))
+	// Struggle, the strike of a charged move and later turns of a rampage
+	// cost no PP.
+	if attacker.ForcedMove != move.Name && !struggling && !attacker.UseMove(move.Name) {
+		events = append(events, CantMove{Pokemon: attacker.Base.Name, Side: attacker.side, Reason: "no-pp", Move: move.Name})
 		return events
 	}
-	events = append(events, fmt.Sprintf("%s used %s!", attacker.Base.Name, move.Name))
+	if attacker.item().Choice && !struggling {
+		attacker.ChoiceLock = move.Name
+	}
+	events = append(events, MoveUsed{Pokemon: attacker.Base.Name, Side: attacker.side, Move: move.Name})
+	if _, ok := protectMoves[move.Name]; !ok {
+		attacker.RemoveVolatileEffect("stall")
+	}
+
+	chargeEvents, charging := startCharge(attacker, move, field)
+	events = append(events, chargeEvents...)
+	if charging {
+		return events
+	}
+
+	if terrainBlocksPriority(field, attacker, defender, move) {
+		events = append(events, Fail{Pokemon: defender.Base.Name, Side: defender.side, Reason: "psychic-terrain"})
+		return append(events, finishMove(attacker, move, false, field)...)
+	}
+	blocked, protectEvents := protected(defender, move)
+	events = append(events, protectEvents...)
+	if blocked {
+		return append(events, finishMove(attacker, move, false, field)...)
+	}
+	if targetsFoe(move) && outOfReach(defender, move) {
+		events = append(events, Miss{Pokemon: attacker.Base.Name, Side: attacker.side})
+		return append(events, finishMove(attacker, move, false, field)...)
+	}
 
-	if move.Power > 0 {
-		dmg, percent, calcEvents := DamageCalc(attacker, defender, move)
+	if move.Power > 0 || fixedDamageMove(move) {
+		hitEvents, landed := useDamagingMove(attacker, defender, move, field)
+		events = append(events, hitEvents...)
+		events = append(events, finishMove(attacker, move, landed, field)...)
+	} else {
+		events = append(events, useStatusMove(attacker, defender, move, field)...)
+	}
+	return events
+}
+
+// useDamagingMove strikes defender with move once, or as many times as a
+// multi-hit move rolls, then applies the move's effects for the total damage
+// dealt. It reports whether the move landed.
+func useDamagingMove(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) ([]Event, bool) {
+	var events []Event
+	hits := hitCount(move, attacker.rng())
+	dealt, landed := 0, 0
+	for landed < hits && !defender.Fainted && !attacker.Fainted {
+		hit, calcEvents := damageCalc(attacker, defender, move, field, landed == 0)
 		events = append(events, calcEvents...)
-		if dmg > 0 {
-			defender.ApplyDamage(float64(dmg))
-			events = append(events, fmt.Sprintf("%s took %d damage! (%.1f%%)", defender.Base.Name, dmg, percent))
-			if defender.Fainted {
-				events = append(events, fmt.Sprintf("%s fainted!", defender.Base.Name))
+		if hit.Amount <= 0 {
+			if landed == 0 && len(calcEvents) == 0 && effectivenessCheck(move, defender, field) > 0 {
+				events = append(events, Fail{Pokemon: defender.Base.Name, Side: defender.side, Reason: "no-effect"})
 			}
-		} else if len(calcEvents) == 0 && effectivenessCheck(move, defender) > 0 {
-			events = append(events, fmt.Sprintf("It had no effect on %s!", defender.Base.Name))
+			break
 		}
+		if behindSubstitute(attacker, defender) {
+			absorbed, subEvents := hitSubstitute(defender, hit)
+			recordHit(defender, move, absorbed, true)
+			events = append(events, subEvents...)
+			dealt += absorbed
+			landed++
+			continue
+		}
+		hitDealt, hitEvents := takeHit(attacker, defender, move, hit)
+		events = append(events, hitEvents...)
+		dealt += hitDealt
+		landed++
+	}
+	if landed == 0 {
+		return events, false
+	}
+	if hits > 1 {
+		events = append(events, HitCount{Hits: landed})
+	}
+	events = append(events, thawTarget(defender, move.Name, move.Type.Name)...)
+	return append(events, applyMoveEffects(attacker, defender, move, dealt, field)...), true
+}
+
+// targetsFoe reports whether move is aimed at the foe itself rather than at
+// the user or a side of the field.
+func targetsFoe(move *pokemon.MoveInfo) bool {
+	switch move.Target.Name {
+	case "user", "user-and-allies", "users-field", "opponents-field", "entire-field":
+		return false
+	}
+	return true
+}
+
+// useStatusMove resolves a move that deals no direct damage: an ailment move
+// aimed at a foe its type cannot affect fails, and otherwise it rolls for
+// accuracy when aimed at the foe and then applies the move's meta effects.
+func useStatusMove(attacker, defender *BattlePokemon, move *pokemon.MoveInfo, field *Field) []Event {
+	if ailment := move.Meta.Ailment.Name; targetsFoe(move) && ailment != "" && ailment != "none" && effectivenessCheck(move, defender, field) == 0 {
+		return []Event{Immune{Pokemon: defender.Base.Name, Side: defender.side}}
+	}
+	if targetsFoe(move) && !accuracyHits(attacker, defender, move, field) {
+		return []Event{Miss{Pokemon: attacker.Base.Name, Side: attacker.side}}
+	}
+	if targetsFoe(move) && behindSubstitute(attacker, defender) {
+		return []Event{Fail{Pokemon: attacker.Base.Name, Side: attacker.side}}
+	}
+	var events []Event
+	if weather, ok := weatherMoves[move.Name]; ok {
+		events = field.setWeather(weather)
+	} else if _, ok := conditionMoves[move.Name]; ok {
+		events = field.useConditionMove(attacker, move)
+	} else if _, ok := hazardMoves[move.Name]; ok {
+		events = field.layHazard(attacker, defender, move)
+	} else if _, ok := protectMoves[move.Name]; ok {
+		events = useProtectMove(attacker, move)
+	} else if move.Name == "substitute" {
+		events = useSubstitute(attacker)
+	} else if move.Name == "rest" {
+		events = rest(attacker, field)
+	} else if move.Name == "roost" {
+		events = roost(attacker, defender, move, field)
 	} else {
-		events = append(events, fmt.Sprintf("...%s used a 0 damage move %s with effects %s...", attacker.Base.Name, move.Name, move.EffectEntries))
+		events = applyMoveEffects(attacker, defender, move, 0, field)
+	}
+	if len(events) == 0 {
+		events = append(events, Fail{Pokemon: attacker.Base.Name, Side: attacker.side})
 	}
 	return events
 }
@@ -14,8 +14,8 @@ func DisplayBattleState(playerSquad, enemySquad []*BattlePokemon, playerActiveIn
 	playerPercent := (playerActive.CurrentHP / playerMaxHPs[playerActiveIndex]) * 100
 	enemyPercent := (enemyActive.CurrentHP / enemyMaxHPs[enemyActiveIndex]) * 100
 
-	fmt.Printf("Your %s - HP: %.1f/%.1f (%.0f%%) - Status: %s\n", playerActive.Base.Name, playerActive.CurrentHP, playerMaxHPs[playerActiveIndex], playerPercent, playerActive.Status)
-	fmt.Printf("Enemy %s - HP: %.1f/%.1f (%.0f%%) - Status: %s\n", enemyActive.Base.Name, enemyActive.CurrentHP, enemyMaxHPs[enemyActiveIndex], enemyPercent, enemyActive.Status)
+	fmt.Printf("Your %s Lv.%d - HP: %.1f/%.1f (%.0f%%) - Status: %s\n", playerActive.Base.Name, playerActive.level(), playerActive.CurrentHP, playerMaxHPs[playerActiveIndex], playerPercent, playerActive.Status)
+	fmt.Printf("Enemy %s Lv.%d - HP: %.1f/%.1f (%.0f%%) - Status: %s\n", enemyActive.Base.Name, enemyActive.level(), enemyActive.CurrentHP, enemyMaxHPs[enemyActiveIndex], enemyPercent, enemyActive.Status)
 	fmt.Println("==================================")
 
 	fmt.Println("\nYour Team:")
@@ -27,6 +27,9 @@ func DisplayBattleState(playerSquad, enemySquad []*BattlePokemon, playerActiveIn
 			status = "Active"
 		}
 		hpPercent := (poke.CurrentHP / playerMaxHPs[i]) * 100
+		if poke.Item != "" {
+			status += " @ " + displayName(poke.Item)
+		}
 		fmt.Printf("%d. %s - HP: %.1f/%.1f (%.0f%%) - %s\n", i+1, poke.Base.Name, poke.CurrentHP, playerMaxHPs[i], hpPercent, status)
 	}
 
@@ -0,0 +1,136 @@
+package battle
+
+import "github.com/ross1116/pokebattlecli/internal/pokemon"
+
+// weatherDuration is how many turns weather lasts once a move or ability
+// sets it.
+const weatherDuration = 5
+
+type weatherInfo struct {
+	// residual weather damages every active Pokémon except those of the
+	// immune types at the end of each turn.
+	residual bool
+	immune   []string
+}
+
+var weathers = map[string]weatherInfo{
+	"rain": {},
+	"sun":  {},
+	"sandstorm": {
+		immune:   []string{"rock", "ground", "steel"},
+		residual: true,
+	},
+	"hail": {
+		immune:   []string{"ice"},
+		residual: true,
+	},
+}
+
+// weatherMoves maps the moves that set weather to the weather they set.
+var weatherMoves = map[string]string{
+	"rain-dance": "rain",
+	"sunny-day":  "sun",
+	"sandstorm":  "sandstorm",
+	"hail":       "hail",
+}
+
+// setWeather starts weather for weatherDuration turns. It returns nil when
+// that weather is already in effect.
+func (f *Field) setWeather(weather string) []Event {
+	if f == nil || f.Weather == weather {
+		return nil
+	}
+	f.Weather = weather
+	f.WeatherTurns = weatherDuration
+	return []Event{WeatherChanged{Weather: weather, Phase: WeatherStarted}}
+}
+
+// weatherEndOfTurn counts the weather down and, while it lasts, deals
+// residual damage to the active Pokémon.
+func (f *Field) weatherEndOfTurn(actives ...*BattlePokemon) []Event {
+	if f == nil || f.Weather == "" {
+		return nil
+	}
+	info := weathers[f.Weather]
+	f.WeatherTurns--
+	if f.WeatherTurns <= 0 {
+		ended := f.Weather
+		f.Weather, f.WeatherTurns = "", 0
+		return []Event{WeatherChanged{Weather: ended, Phase: WeatherEnded}}
+	}
+
+	events := []Event{WeatherChanged{Weather: f.Weather, Phase: WeatherContinues}}
+	if !info.residual {
+		return events
+	}
+	for _, bp := range actives {
+		if bp == nil || bp.Fainted || hasAnyType(bp, info.immune) {
+			continue
+		}
+		events = append(events, bp.hurt(bp.MaxHP()/16, f.Weather)...)
+	}
+	return events
+}
+
+// weatherModifyHit applies the weather's effect on a damaging move: rain and
+// sun boost Water and Fire moves and weaken the other, sand raises Rock types'
+// special defense, and Solar Beam loses half its power in any other weather.
+func weatherModifyHit(field *Field, hit *Hit) {
+	weather := field.weather()
+	switch {
+	case weather == "rain" && hit.Move.Type.Name == "water",
+		weather == "sun" && hit.Move.Type.Name == "fire":
+		hit.Damage *= 1.5
+	case weather == "rain" && hit.Move.Type.Name == "fire",
+		weather == "sun" && hit.Move.Type.Name == "water":
+		hit.Damage *= 0.5
+	}
+	if weather == "sandstorm" && hit.Move.DamageClass.Name == "special" && hasType(hit.Defender, "rock") {
+		hit.Defense *= 1.5
+	}
+	if hit.Move.Name == "solar-beam" && weather != "" && weather != "sun" {
+		hit.Power *= 0.5
+	}
+}
+
+// weatherAccuracy returns move's accuracy under the current weather. Zero
+// means the move cannot miss.
+func weatherAccuracy(move *pokemon.MoveInfo, field *Field) int {
+	weather := field.weather()
+	switch move.Name {
+	case "thunder", "hurricane":
+		if weather == "rain" {
+			return 0
+		}
+		if weather == "sun" {
+			return 50
+		}
+	case "blizzard":
+		if weather == "hail" {
+			return 0
+		}
+	}
+	return move.Accuracy
+}
+
+// weatherAbility sets weather when the holder switches in.
+func weatherAbility(name, weather string) *Ability {
+	return &Ability{
+		Name: name,
+		OnSwitchIn: func(self, foe *BattlePokemon, field *Field) []Event {
+			if len(field.setWeather(weather)) == 0 {
+				return nil
+			}
+			return []Event{WeatherChanged{Weather: weather, Phase: WeatherStarted, Pokemon: self.Base.Name, Side: self.side, Ability: name}}
+		},
+	}
+}
+
+func hasAnyType(bp *BattlePokemon, types []string) bool {
+	for _, t := range types {
+		if hasType(bp, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package battle_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+)
+
+func TestDrizzleSetsRainOnSwitchIn(t *testing.T) {
+	politoed := newBattler(t, "politoed", "drizzle")
+	golem := newBattler(t, "golem", "")
+	field := &battle.Field{}
+
+	events := battle.SwitchIn(politoed, golem, field)
+	if field.Weather != "rain" || field.WeatherTurns != 5 {
+		t.Fatalf("Expected 5 turns of rain, got %q for %d turns", field.Weather, field.WeatherTurns)
+	}
+	if len(events) == 0 || events[len(events)-1].(battle.WeatherChanged).Weather != "rain" {
+		t.Errorf("Expected a rain announcement, got %v", events)
+	}
+}
+
+func TestRainBoostsWaterAndWeakensFire(t *testing.T) {
+	swampert := newBattler(t, "swampert", "", "surf", "ember")
+	snorlax := newBattler(t, "snorlax", "")
+
+	average := func(move int, field *battle.Field) float64 {
+		total := 0
+		for range 300 {
+			dmg, _, _ := battle.DamageCalc(swampert, snorlax, swampert.Moves[move], field)
+			total += dmg
+		}
+		return float64(total) / 300
+	}
+	rain := &battle.Field{Weather: "rain"}
+	if ratio := average(0, rain) / average(0, nil); ratio < 1.35 || ratio > 1.65 {
+		t.Errorf("Expected rain to boost Surf by 1.5x, got %.2f", ratio)
+	}
+	if ratio := average(1, rain) / average(1, nil); ratio < 0.4 || ratio > 0.6 {
+		t.Errorf("Expected rain to halve Ember, got %.2f", ratio)
+	}
+}
+
+func TestThunderNeverMissesInRain(t *testing.T) {
+	raichu := newBattler(t, "raichu", "", "thunder")
+	snorlax := newBattler(t, "snorlax", "")
+	rain := &battle.Field{Weather: "rain"}
+
+	for range 200 {
+		_, _, events := battle.DamageCalc(raichu, snorlax, raichu.Moves[0], rain)
+		if slices.Contains(events, battle.Event(battle.Miss{Pokemon: "raichu"})) {
+			t.Fatalf("Thunder missed in rain")
+		}
+	}
+}
+
+func TestSandstormChipsAndSubsides(t *testing.T) {
+	golem := newBattler(t, "golem", "", "sandstorm")
+	snorlax := newBattler(t, "snorlax", "", "splash")
+	field := &battle.Field{}
+
+	battle.ExecuteTurn(golem, snorlax, golem.Moves[0], snorlax.Moves[0], field)
+	if field.Weather != "sandstorm" {
+		t.Fatalf("Expected Sandstorm to start a sandstorm, got %q", field.Weather)
+	}
+	if golem.CurrentHP != golem.MaxHP() {
+		t.Errorf("Rock-type Golem took sandstorm damage")
+	}
+	if want := snorlax.MaxHP() - snorlax.MaxHP()/16; snorlax.CurrentHP != want {
+		t.Errorf("Expected Snorlax at %.1f HP after one turn of sand, got %.1f", want, snorlax.CurrentHP)
+	}
+
+	var events []battle.Event
+	for range 4 {
+		events = battle.ExecuteTurn(golem, snorlax, nil, nil, field)
+	}
+	if field.Weather != "" {
+		t.Errorf("Expected the sandstorm to end after 5 turns, %d turns left", field.WeatherTurns)
+	}
+	if len(events) == 0 || events[0] != (battle.WeatherChanged{Weather: "sandstorm", Phase: battle.WeatherEnded}) {
+		t.Errorf("Expected the sandstorm to subside, got %v", events)
+	}
+}
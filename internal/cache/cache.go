@@ -1 +1,325 @@
 package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL      = 7 * 24 * time.Hour
+	DefaultMaxBytes = 64 << 20
+
+	// tempFileGrace is how old a temp file must be before New takes it for
+	// a write that never finished rather than one another process is
+	// still making.
+	tempFileGrace = time.Hour
+)
+
+type Options struct {
+	Dir        string
+	DefaultTTL time.Duration
+	MaxBytes   int64
+	// Now tells the time entries are stored, read and expire by. It
+	// defaults to time.Now.
+	Now func() time.Time
+}
+
+type Entry struct {
+	Key          string    `json:"key"`
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+func (e *Entry) CanRevalidate() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	Stale       int64
+	Revalidated int64
+	Evictions   int64
+	Entries     int
+	Bytes       int64
+}
+
+type indexEntry struct {
+	size       int64
+	lastAccess time.Time
+}
+
+// Cache is a size-bounded store of HTTP response bodies on disk. Entries
+// survive restarts, carry their own expiry and keep the validators needed to
+// revalidate them once they go stale. The least recently used entries are
+// evicted when the total size exceeds MaxBytes.
+type Cache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+
+	mu         sync.Mutex
+	index      map[string]*indexEntry
+	totalBytes int64
+	stats      Stats
+}
+
+func DefaultDir() string {
+	if dir := os.Getenv("POKEBATTLECLI_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "pokebattlecli")
+}
+
+func New(opts Options) (*Cache, error) {
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir()
+	}
+	if opts.DefaultTTL <= 0 {
+		opts.DefaultTTL = DefaultTTL
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	c := &Cache{
+		dir:      opts.Dir,
+		ttl:      opts.DefaultTTL,
+		maxBytes: opts.MaxBytes,
+		now:      opts.Now,
+		index:    make(map[string]*indexEntry),
+	}
+	if err := c.scan(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) scan() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("reading cache directory: %w", err)
+	}
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".tmp") {
+			if info, err := f.Info(); err == nil && c.now().Sub(info.ModTime()) > tempFileGrace {
+				os.Remove(filepath.Join(c.dir, f.Name()))
+			}
+			continue
+		}
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name(), ".json")
+		c.index[name] = &indexEntry{size: info.Size(), lastAccess: info.ModTime()}
+		c.totalBytes += info.Size()
+	}
+	return nil
+}
+
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(name string) string {
+	return filepath.Join(c.dir, name+".json")
+}
+
+// Get returns the stored entry for key, fresh or not. Callers decide whether
+// a stale entry is still usable or needs revalidating.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.load(key)
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	if entry.Fresh(c.now()) {
+		c.stats.Hits++
+	} else {
+		c.stats.Stale++
+	}
+	return entry, true
+}
+
+func (c *Cache) load(key string) (*Entry, bool) {
+	name := fileName(key)
+	idx, ok := c.index[name]
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(name))
+	if err != nil {
+		c.forget(name)
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		log.Printf("Warning: Dropping unreadable cache entry for %s: %v", key, err)
+		c.remove(name)
+		return nil, false
+	}
+	idx.lastAccess = c.now()
+	return &entry, true
+}
+
+func (c *Cache) Put(entry *Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.store(entry)
+}
+
+func (c *Cache) store(entry *Entry) error {
+	now := c.now()
+	if entry.StoredAt.IsZero() {
+		entry.StoredAt = now
+	}
+	if entry.ExpiresAt.IsZero() {
+		entry.ExpiresAt = now.Add(c.ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	name := fileName(entry.Key)
+	if err := c.write(name, data); err != nil {
+		return err
+	}
+	if old, ok := c.index[name]; ok {
+		c.totalBytes -= old.size
+	}
+	c.index[name] = &indexEntry{size: int64(len(data)), lastAccess: now}
+	c.totalBytes += int64(len(data))
+	c.evict(name)
+	return nil
+}
+
+// Revalidated extends the expiry of an entry after the origin confirmed it
+// has not changed.
+func (c *Cache) Revalidated(key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.load(key)
+	if !ok {
+		return fmt.Errorf("no cache entry for %s", key)
+	}
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	entry.ExpiresAt = c.now().Add(ttl)
+	if err := c.store(entry); err != nil {
+		return err
+	}
+	c.stats.Revalidated++
+	return nil
+}
+
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(fileName(key))
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.index)
+	stats.Bytes = c.totalBytes
+	return stats
+}
+
+func (c *Cache) write(name string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path(name))
+}
+
+func (c *Cache) evict(keep string) {
+	if c.totalBytes <= c.maxBytes {
+		return
+	}
+	names := make([]string, 0, len(c.index))
+	for name := range c.index {
+		if name != keep {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.index[names[i]].lastAccess.Before(c.index[names[j]].lastAccess)
+	})
+	for _, name := range names {
+		if c.totalBytes <= c.maxBytes {
+			break
+		}
+		c.remove(name)
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) remove(name string) {
+	os.Remove(c.path(name))
+	c.forget(name)
+}
+
+func (c *Cache) forget(name string) {
+	if idx, ok := c.index[name]; ok {
+		c.totalBytes -= idx.size
+		delete(c.index, name)
+	}
+}
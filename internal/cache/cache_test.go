@@ -0,0 +1,125 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/cache"
+)
+
+func TestEntriesPersistAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.New(cache.Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if err := c.Put(&cache.Entry{Key: "https://example.test/pokemon/1/", Body: []byte(`{"id":1}`), ETag: `"abc"`}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	reopened, err := cache.New(cache.Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Failed to reopen cache: %v", err)
+	}
+	entry, ok := reopened.Get("https://example.test/pokemon/1/")
+	if !ok {
+		t.Fatal("Expected entry to survive reopening the cache")
+	}
+	if string(entry.Body) != `{"id":1}` || entry.ETag != `"abc"` {
+		t.Errorf("Unexpected entry contents: %+v", entry)
+	}
+	if _, ok := reopened.Get("https://example.test/pokemon/2/"); ok {
+		t.Error("Expected a miss for an unknown key")
+	}
+
+	stats := reopened.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestStaleEntriesCanBeRevalidated(t *testing.T) {
+	c, err := cache.New(cache.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	key := "https://example.test/move/33/"
+	c.Put(&cache.Entry{Key: key, Body: []byte(`{}`), LastModified: "Mon, 01 Jan 2024 00:00:00 GMT", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	entry, ok := c.Get(key)
+	if !ok || entry.Fresh(time.Now()) || !entry.CanRevalidate() {
+		t.Fatalf("Expected a stale, revalidatable entry, got %+v (found=%v)", entry, ok)
+	}
+	if err := c.Revalidated(key, time.Hour); err != nil {
+		t.Fatalf("Revalidated failed: %v", err)
+	}
+	entry, _ = c.Get(key)
+	if !entry.Fresh(time.Now()) {
+		t.Error("Expected entry to be fresh after revalidation")
+	}
+
+	stats := c.Stats()
+	if stats.Stale != 1 || stats.Hits != 1 || stats.Revalidated != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestLeastRecentlyUsedEntriesAreEvicted(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := cache.New(cache.Options{Dir: t.TempDir(), MaxBytes: 600, Now: func() time.Time { return clock }})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	body := make([]byte, 100)
+	c.Put(&cache.Entry{Key: "a", Body: body})
+	clock = clock.Add(time.Second)
+	c.Put(&cache.Entry{Key: "b", Body: body})
+	clock = clock.Add(time.Second)
+	c.Get("a")
+	clock = clock.Add(time.Second)
+	c.Put(&cache.Entry{Key: "c", Body: body})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected least recently used entry b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Expected recently read entry a to be kept")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Expected newest entry c to be kept")
+	}
+	if stats := c.Stats(); stats.Evictions == 0 || stats.Bytes > 600 {
+		t.Errorf("Unexpected stats after eviction: %+v", stats)
+	}
+}
+
+func TestOrphanedTempFilesAreRemoved(t *testing.T) {
+	dir := t.TempDir()
+	orphan := filepath.Join(dir, "abc.123.tmp")
+	inFlight := filepath.Join(dir, "def.456.tmp")
+	for _, name := range []string{orphan, inFlight} {
+		if err := os.WriteFile(name, []byte(`{"key":`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if err := os.Chtimes(orphan, now.Add(-2*time.Hour), now.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := cache.New(cache.Options{Dir: dir, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("Expected the orphaned temp file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(inFlight); err != nil {
+		t.Errorf("Expected a recent temp file to be left for its writer, got %v", err)
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Expected an empty cache, got %+v", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package pokemon
+
+import "math/rand/v2"
+
+// PickRandAbility returns one of the species' regular abilities, drawn from
+// r. Hidden abilities are only used when the species has nothing else.
+func PickRandAbility(pokemon *Pokemon, r *rand.Rand) string {
+	var regular, hidden []string
+	for _, slot := range pokemon.Abilities {
+		if slot.IsHidden {
+			hidden = append(hidden, slot.Ability.Name)
+		} else {
+			regular = append(regular, slot.Ability.Name)
+		}
+	}
+	if len(regular) == 0 {
+		regular = hidden
+	}
+	if len(regular) == 0 {
+		return ""
+	}
+	return regular[r.IntN(len(regular))]
+}
+
+// HasAbility reports whether the species can legally have the ability,
+// hidden abilities included.
+func HasAbility(pokemon *Pokemon, name string) bool {
+	for _, slot := range pokemon.Abilities {
+		if slot.Ability.Name == name {
+			return true
+		}
+	}
+	return false
+}
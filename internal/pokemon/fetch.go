@@ -0,0 +1,149 @@
+package pokemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrUpstream = errors.New("upstream error")
+	ErrDecode   = errors.New("malformed data")
+)
+
+// FetchError describes a failed lookup. Kind is one of ErrNotFound,
+// ErrUpstream or ErrDecode so callers can branch with errors.Is.
+type FetchError struct {
+	URL        string
+	StatusCode int
+	Kind       error
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	msg := fmt.Sprintf("fetching %s: %v", e.URL, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (HTTP %d)", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *FetchError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func statusError(url string, status int) error {
+	if status == http.StatusNotFound {
+		return &FetchError{URL: url, StatusCode: status, Kind: ErrNotFound}
+	}
+	return &FetchError{URL: url, StatusCode: status, Kind: ErrUpstream}
+}
+
+// RetryPolicy is the single backoff policy for every network lookup. Only
+// upstream failures are retried; missing or malformed documents and
+// cancellation return immediately.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		err = fn(ctx)
+		if err == nil || !retryable(ctx, err) {
+			return err
+		}
+		if attempt == attempts-1 {
+			break
+		}
+		timer := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int64N(int64(d/2)+1))
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrDecode)
+}
+
+const negativeCacheTTL = 30 * time.Second
+
+type negativeEntry struct {
+	err     error
+	expires time.Time
+}
+
+// failures remembers recent not-found and upstream errors per URL so a burst
+// of lookups for a broken document does not hammer PokeAPI.
+var failures = struct {
+	sync.Mutex
+	entries map[string]negativeEntry
+}{entries: make(map[string]negativeEntry)}
+
+func recentFailure(url string) error {
+	failures.Lock()
+	defer failures.Unlock()
+	entry, ok := failures.entries[url]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(failures.entries, url)
+		return nil
+	}
+	return entry.err
+}
+
+func rememberFailure(url string, err error) {
+	if !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrUpstream) {
+		return
+	}
+	failures.Lock()
+	failures.entries[url] = negativeEntry{err: err, expires: time.Now().Add(negativeCacheTTL)}
+	failures.Unlock()
+}
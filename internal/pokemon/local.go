@@ -0,0 +1,203 @@
+package pokemon
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+//go:embed snapshot
+var snapshotFS embed.FS
+
+// LocalSource serves PokeAPI documents from a directory laid out as
+// pokemon/<name>.json, move/<name>.json, type/<name>.json and
+// item/<name>.json, with an index.json mapping numeric IDs to names for each
+// kind.
+type LocalSource struct {
+	fsys fs.FS
+
+	indexOnce sync.Once
+	index     localIndex
+	indexErr  error
+}
+
+type localIndex struct {
+	Pokemon map[string]string `json:"pokemon"`
+	Move    map[string]string `json:"move"`
+	Type    map[string]string `json:"type"`
+	Item    map[string]string `json:"item,omitempty"`
+}
+
+func NewLocalSource(fsys fs.FS) *LocalSource {
+	return &LocalSource{fsys: fsys}
+}
+
+func OpenLocalSource(dir string) (*LocalSource, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening local data store: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local data store %s is not a directory", dir)
+	}
+	src := NewLocalSource(os.DirFS(dir))
+	manifest, err := ReadManifest(src.fsys)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if manifest != nil && !manifest.Complete {
+		log.Printf("Warning: Local data store %s is incomplete; run 'data sync' again to finish it.", dir)
+	}
+	if err := src.loadIndex(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func BundledSource() *LocalSource {
+	sub, err := fs.Sub(snapshotFS, "snapshot")
+	if err != nil {
+		panic(fmt.Sprintf("bundled snapshot missing: %v", err))
+	}
+	return NewLocalSource(sub)
+}
+
+func (s *LocalSource) loadIndex() error {
+	s.indexOnce.Do(func() {
+		data, err := fs.ReadFile(s.fsys, "index.json")
+		if err != nil {
+			s.indexErr = fmt.Errorf("reading local index: %w", err)
+			return
+		}
+		if err := json.Unmarshal(data, &s.index); err != nil {
+			s.indexErr = fmt.Errorf("decoding local index: %w", err)
+		}
+	})
+	return s.indexErr
+}
+
+func (s *LocalSource) resolve(kind string, key string) (string, error) {
+	if _, err := strconv.Atoi(key); err != nil {
+		return key, nil
+	}
+	if err := s.loadIndex(); err != nil {
+		return "", err
+	}
+	var ids map[string]string
+	switch kind {
+	case "pokemon":
+		ids = s.index.Pokemon
+	case "move":
+		ids = s.index.Move
+	case "type":
+		ids = s.index.Type
+	case "item":
+		ids = s.index.Item
+	}
+	name, ok := ids[key]
+	if !ok {
+		return "", fmt.Errorf("%s #%s in local data: %w", kind, key, ErrNotFound)
+	}
+	return name, nil
+}
+
+func (s *LocalSource) read(ctx context.Context, kind, name string, result any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := fs.ReadFile(s.fsys, path.Join(kind, name+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s %q in local data: %w", kind, name, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s %q from local data: %w", kind, name, err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("%s %q in local data: %w: %v", kind, name, ErrDecode, err)
+	}
+	return nil
+}
+
+func (s *LocalSource) Pokemon(ctx context.Context, identifier any) (*Pokemon, error) {
+	var key string
+	switch v := identifier.(type) {
+	case string:
+		key = v
+	case int:
+		key = strconv.Itoa(v)
+	default:
+		return nil, fmt.Errorf("invalid identifier type")
+	}
+	name, err := s.resolve("pokemon", key)
+	if err != nil {
+		return nil, err
+	}
+	var pokemon Pokemon
+	if err := s.read(ctx, "pokemon", name, &pokemon); err != nil {
+		return nil, err
+	}
+	return &pokemon, nil
+}
+
+func (s *LocalSource) MoveByName(ctx context.Context, name string) (*MoveInfo, error) {
+	var move MoveInfo
+	if err := s.read(ctx, "move", name, &move); err != nil {
+		return nil, err
+	}
+	return &move, nil
+}
+
+func (s *LocalSource) MoveByURL(ctx context.Context, url string) (*MoveInfo, error) {
+	name, err := s.resolve("move", resourceKey(url))
+	if err != nil {
+		return nil, err
+	}
+	return s.MoveByName(ctx, name)
+}
+
+func (s *LocalSource) Type(ctx context.Context, name string) (*TypeData, error) {
+	name, err := s.resolve("type", name)
+	if err != nil {
+		return nil, err
+	}
+	var typeData TypeData
+	if err := s.read(ctx, "type", name, &typeData); err != nil {
+		return nil, err
+	}
+	return &typeData, nil
+}
+
+func (s *LocalSource) Item(ctx context.Context, name string) (*ItemInfo, error) {
+	name, err := s.resolve("item", name)
+	if err != nil {
+		return nil, err
+	}
+	var item ItemInfo
+	if err := s.read(ctx, "item", name, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (s *LocalSource) SpeciesIDs() []int {
+	if err := s.loadIndex(); err != nil {
+		return nil
+	}
+	ids := make([]int, 0, len(s.index.Pokemon))
+	for key := range s.index.Pokemon {
+		if id, err := strconv.Atoi(key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
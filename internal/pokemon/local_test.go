@@ -0,0 +1,128 @@
+package pokemon_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestBundledSourceResolvesByNameAndID(t *testing.T) {
+	ctx := context.Background()
+	src := pokemon.BundledSource()
+
+	byName, err := src.Pokemon(ctx, "pikachu")
+	if err != nil {
+		t.Fatalf("Failed to load pikachu by name: %v", err)
+	}
+	byID, err := src.Pokemon(ctx, 25)
+	if err != nil {
+		t.Fatalf("Failed to load pokemon #25: %v", err)
+	}
+	if byName.Name != byID.Name || byID.ID != 25 {
+		t.Errorf("Expected #25 to be pikachu, got %s (#%d)", byID.Name, byID.ID)
+	}
+
+	for _, slot := range byName.Moves {
+		move, err := src.MoveByURL(ctx, slot.Move.URL)
+		if err != nil {
+			t.Fatalf("Failed to resolve move %s via %s: %v", slot.Move.Name, slot.Move.URL, err)
+		}
+		if move.Name != slot.Move.Name {
+			t.Errorf("Move URL %s resolved to %s, expected %s", slot.Move.URL, move.Name, slot.Move.Name)
+		}
+	}
+
+	flamethrower, err := src.MoveByName(ctx, "flamethrower")
+	if err != nil {
+		t.Fatalf("Failed to load flamethrower: %v", err)
+	}
+	if flamethrower.Meta.Ailment.Name != "burn" || flamethrower.Meta.AilmentChance != 10 {
+		t.Errorf("Expected flamethrower to burn 10%% of the time, got %+v", flamethrower.Meta)
+	}
+
+	electric, err := src.Type(ctx, "electric")
+	if err != nil {
+		t.Fatalf("Failed to load electric type: %v", err)
+	}
+	if len(electric.DamageRelations.NoDamageTo) != 1 || electric.DamageRelations.NoDamageTo[0].Name != "ground" {
+		t.Errorf("Expected electric to have no effect on ground, got %+v", electric.DamageRelations.NoDamageTo)
+	}
+
+	leftovers, err := src.Item(ctx, "234")
+	if err != nil {
+		t.Fatalf("Failed to load leftovers by ID: %v", err)
+	}
+	if leftovers.Name != "leftovers" || leftovers.FlingPower != 10 {
+		t.Errorf("Unexpected item document: %+v", leftovers)
+	}
+
+	if _, err := src.Pokemon(ctx, "missingno"); !errors.Is(err, pokemon.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a species missing from the snapshot, got %v", err)
+	}
+}
+
+func TestSelectRandSquadUsesAvailableSpecies(t *testing.T) {
+	ctx := context.Background()
+	squad, err := pokemon.SelectRandSquad(ctx, pokemon.BundledSource(), rand.New(rand.NewPCG(1, 2)), io.Discard)
+	if err != nil {
+		t.Fatalf("SelectRandSquad failed: %v", err)
+	}
+	if len(squad) != 6 {
+		t.Fatalf("Expected a full squad of 6 from the bundled snapshot, got %d", len(squad))
+	}
+	seen := make(map[string]bool)
+	for _, p := range squad {
+		if seen[p.Name] {
+			t.Errorf("Duplicate species %s in squad", p.Name)
+		}
+		seen[p.Name] = true
+	}
+}
+
+func TestSelectRandSquadFollowsTheGenerator(t *testing.T) {
+	ctx := context.Background()
+	draw := func() []string {
+		squad, err := pokemon.SelectRandSquad(ctx, pokemon.BundledSource(), rand.New(rand.NewPCG(7, 7)), io.Discard)
+		if err != nil {
+			t.Fatalf("SelectRandSquad failed: %v", err)
+		}
+		names := make([]string, len(squad))
+		for i, p := range squad {
+			names[i] = p.Name
+		}
+		return names
+	}
+	first, second := draw(), draw()
+	if !slices.Equal(first, second) {
+		t.Errorf("Expected the same generator state to draw the same squad, got %v and %v", first, second)
+	}
+}
+
+func TestSelectRandSquadFailsOnSmallStores(t *testing.T) {
+	species := func(index string) fstest.MapFS {
+		return fstest.MapFS{
+			"index.json":           {Data: []byte(`{"pokemon": {` + index + `}}`)},
+			"pokemon/pikachu.json": {Data: []byte(`{"id": 25, "name": "pikachu"}`)},
+			"pokemon/machamp.json": {Data: []byte(`{"id": 68, "name": "machamp"}`)},
+			"pokemon/snorlax.json": {Data: []byte(`{"id": 143, "name": "snorlax"}`)},
+		}
+	}
+	stores := map[string]fstest.MapFS{
+		"three species":         species(`"25": "pikachu", "68": "machamp", "143": "snorlax"`),
+		"missing species files": species(`"1": "bulbasaur", "4": "charmander", "7": "squirtle", "25": "pikachu", "68": "machamp", "143": "snorlax"`),
+	}
+	for name, fsys := range stores {
+		t.Run(name, func(t *testing.T) {
+			src := pokemon.NewLocalSource(fsys)
+			if _, err := pokemon.SelectRandSquad(context.Background(), src, rand.New(rand.NewPCG(1, 2)), io.Discard); err == nil {
+				t.Error("Expected an error for a store too small for a squad")
+			}
+		})
+	}
+}
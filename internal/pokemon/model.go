@@ -1,12 +1,19 @@
 package pokemon
 
 type Pokemon struct {
-	ID      int         `json:"id"`
-	Name    string      `json:"name"`
-	Types   []TypeSlot  `json:"types"`
-	Stats   []BaseStats `json:"stats"`
-	Moves   []MoveSlot  `json:"moves"`
-	Fainted bool        `json:"fainted"`
+	ID        int           `json:"id"`
+	Name      string        `json:"name"`
+	Types     []TypeSlot    `json:"types"`
+	Stats     []BaseStats   `json:"stats"`
+	Abilities []AbilitySlot `json:"abilities"`
+	Moves     []MoveSlot    `json:"moves"`
+	Fainted   bool          `json:"fainted"`
+}
+
+type AbilitySlot struct {
+	Ability  ApiResource `json:"ability"`
+	IsHidden bool        `json:"is_hidden"`
+	Slot     int         `json:"slot"`
 }
 
 type BaseStats struct {
@@ -45,15 +52,74 @@ type MoveInfo struct {
 	DamageClass   ApiResource     `json:"damage_class"`
 	EffectChance  int             `json:"effect_chance"`
 	EffectEntries []EffectEntries `json:"effect_entries"`
+	Meta          MoveMeta        `json:"meta"`
 	Name          string          `json:"name"`
 	Power         int             `json:"power"`
 	Pp            int             `json:"pp"`
 	Priority      int             `json:"priority"`
+	StatChanges   []StatChange    `json:"stat_changes"`
+	Target        ApiResource     `json:"target"`
 	Type          ApiResource     `json:"type"`
 }
 
+// MoveMeta is PokeAPI's machine-readable description of a move's effects.
+// Chances of 0 mean the effect always happens when the move's category
+// calls for it. Hit and turn ranges are 0 when the move has none.
+type MoveMeta struct {
+	Ailment       ApiResource `json:"ailment"`
+	AilmentChance int         `json:"ailment_chance"`
+	Category      ApiResource `json:"category"`
+	CritRate      int         `json:"crit_rate"`
+	Drain         int         `json:"drain"`
+	FlinchChance  int         `json:"flinch_chance"`
+	Healing       int         `json:"healing"`
+	MaxHits       int         `json:"max_hits"`
+	MaxTurns      int         `json:"max_turns"`
+	MinHits       int         `json:"min_hits"`
+	MinTurns      int         `json:"min_turns"`
+	StatChance    int         `json:"stat_chance"`
+}
+
+type StatChange struct {
+	Change int         `json:"change"`
+	Stat   ApiResource `json:"stat"`
+}
+
 type EffectEntries struct {
 	Effect      string      `json:"effect"`
 	Language    ApiResource `json:"language"`
 	ShortEffect string      `json:"short_effect"`
 }
+
+type ItemInfo struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Category      ApiResource     `json:"category"`
+	Attributes    []ApiResource   `json:"attributes"`
+	FlingPower    int             `json:"fling_power"`
+	EffectEntries []EffectEntries `json:"effect_entries"`
+}
+
+type TypeData struct {
+	ID                  int                   `json:"id"`
+	Name                string                `json:"name"`
+	Generation          ApiResource           `json:"generation"`
+	DamageRelations     DamageRelations       `json:"damage_relations"`
+	PastDamageRelations []PastDamageRelations `json:"past_damage_relations"`
+}
+
+// PastDamageRelations records the relations a type had up to and including
+// Generation, before a later generation changed them.
+type PastDamageRelations struct {
+	Generation      ApiResource     `json:"generation"`
+	DamageRelations DamageRelations `json:"damage_relations"`
+}
+
+type DamageRelations struct {
+	DoubleDamageFrom []ApiResource `json:"double_damage_from"`
+	DoubleDamageTo   []ApiResource `json:"double_damage_to"`
+	HalfDamageFrom   []ApiResource `json:"half_damage_from"`
+	HalfDamageTo     []ApiResource `json:"half_damage_to"`
+	NoDamageFrom     []ApiResource `json:"no_damage_from"`
+	NoDamageTo       []ApiResource `json:"no_damage_to"`
+}
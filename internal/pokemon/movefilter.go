@@ -1,8 +1,10 @@
 package pokemon
 
 import (
+	"context"
+	"errors"
 	"log"
-	"math/rand"
+	"math/rand/v2"
 )
 
 func FilterMoveByLearn(pokemon *Pokemon) []ApiResource {
@@ -19,7 +21,11 @@ func FilterMoveByLearn(pokemon *Pokemon) []ApiResource {
 	return moves
 }
 
-func PickRandMoves(pokemon *Pokemon) []ApiResource {
+// PickRandMoves draws up to four moves from the species' learnset that
+// usable accepts, or any move if usable is nil, in an order shuffled by r.
+// Moves whose documents are missing or malformed are skipped; any other
+// failure, including cancellation, aborts the draw.
+func PickRandMoves(ctx context.Context, src DataSource, pokemon *Pokemon, usable func(*MoveInfo) bool, r *rand.Rand) ([]ApiResource, error) {
 	allMoves := FilterMoveByLearn(pokemon)
 
 	moveSet := make(map[string]ApiResource)
@@ -31,40 +37,31 @@ func PickRandMoves(pokemon *Pokemon) []ApiResource {
 		}
 	}
 
-	rand.Shuffle(len(uniqueMoves), func(i, j int) {
+	r.Shuffle(len(uniqueMoves), func(i, j int) {
 		uniqueMoves[i], uniqueMoves[j] = uniqueMoves[j], uniqueMoves[i]
 	})
 
 	var finalMoves []ApiResource
 	for i := 0; i < len(uniqueMoves) && len(finalMoves) < 4; i++ {
-		moveData, err := FetchMoveData(uniqueMoves[i].URL)
+		moveData, err := src.MoveByURL(ctx, uniqueMoves[i].URL)
 		if err != nil {
+			if !skippable(err) {
+				return nil, err
+			}
 			log.Printf("failed to fetch move data for %s: %v", uniqueMoves[i].Name, err)
 			continue
 		}
 
-		if moveData.DamageClass.Name != "status" {
+		if usable == nil || usable(moveData) {
 			finalMoves = append(finalMoves, uniqueMoves[i])
 		}
 	}
 
-	return finalMoves
+	return finalMoves, nil
 }
-func FilterStatusMoves(moves []ApiResource) []ApiResource {
-	var filtered []ApiResource
-
-	for _, move := range moves {
-		moveData, err := FetchMoveData(move.URL)
-		if err != nil {
-			log.Printf("failed to fetch move data for %s: %v", move.Name, err)
-			continue
-		}
 
-		if moveData.DamageClass.Name != "status" {
-			filtered = append(filtered, move)
-		}
-	}
-
-	return filtered
+// skippable reports whether a lookup failed because of the document itself
+// rather than the network or the caller giving up.
+func skippable(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrDecode)
 }
-
@@ -1,12 +1,19 @@
 package pokemon
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/cache"
 )
 
 var httpClient = &http.Client{
@@ -19,53 +26,168 @@ var httpClient = &http.Client{
 }
 
 var (
-	cache     = make(map[string][]byte)
-	cacheLock = sync.RWMutex{}
-	cacheTTL  = make(map[string]time.Time)
+	responseCache     *cache.Cache
+	responseCacheOnce sync.Once
+	responseCacheLock sync.RWMutex
 )
 
-func FetchData[T any](url string, result *T) error {
-	cacheLock.RLock()
-	cachedData, exists := cache[url]
-	expiryTime, timeExists := cacheTTL[url]
-	cacheLock.RUnlock()
+// SetCache replaces the cache FetchData goes through. Passing nil disables
+// caching entirely.
+func SetCache(c *cache.Cache) {
+	responseCacheOnce.Do(func() {})
+	responseCacheLock.Lock()
+	responseCache = c
+	responseCacheLock.Unlock()
+}
 
-	if exists && timeExists && time.Now().Before(expiryTime) {
-		return json.Unmarshal(cachedData, result)
+func activeCache() *cache.Cache {
+	responseCacheOnce.Do(func() {
+		c, err := cache.New(cache.Options{})
+		if err != nil {
+			log.Printf("Warning: PokeAPI response cache disabled: %v", err)
+			return
+		}
+		responseCacheLock.Lock()
+		responseCache = c
+		responseCacheLock.Unlock()
+	})
+	responseCacheLock.RLock()
+	defer responseCacheLock.RUnlock()
+	return responseCache
+}
+
+func CacheStats() cache.Stats {
+	if c := activeCache(); c != nil {
+		return c.Stats()
 	}
+	return cache.Stats{}
+}
 
-	resp, err := httpClient.Get(url)
+func FetchData[T any](ctx context.Context, url string, result *T) error {
+	if err := recentFailure(url); err != nil {
+		return err
+	}
+
+	respCache := activeCache()
+	var cached *cache.Entry
+	if respCache != nil {
+		if entry, ok := respCache.Get(url); ok {
+			if entry.Fresh(time.Now()) {
+				return decode(url, entry.Body, result)
+			}
+			cached = entry
+		}
+	}
+
+	var body []byte
+	err := DefaultRetryPolicy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		body, err = fetchOnce(ctx, respCache, url, cached)
+		return err
+	})
 	if err != nil {
+		if cached != nil && errors.Is(err, ErrUpstream) {
+			log.Printf("Warning: Serving stale cache entry for %s: %v", url, err)
+			return decode(url, cached.Body, result)
+		}
+		rememberFailure(url, err)
 		return err
 	}
+	return decode(url, body, result)
+}
+
+func fetchOnce(ctx context.Context, respCache *cache.Cache, url string, cached *cache.Entry) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, &FetchError{URL: url, Kind: ErrUpstream, Err: err}
+	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if err := respCache.Revalidated(url, cacheTTL(resp.Header)); err != nil {
+			log.Printf("Warning: Failed to refresh cache entry for %s: %v", url, err)
+		}
+		return cached.Body, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(url, resp.StatusCode)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, &FetchError{URL: url, Kind: ErrUpstream, Err: err}
 	}
+	if !json.Valid(body) {
+		return nil, &FetchError{URL: url, StatusCode: resp.StatusCode, Kind: ErrDecode, Err: errors.New("response is not valid JSON")}
+	}
+
+	if respCache != nil {
+		entry := &cache.Entry{
+			Key:          url,
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if ttl := cacheTTL(resp.Header); ttl > 0 {
+			entry.ExpiresAt = time.Now().Add(ttl)
+		}
+		if err := respCache.Put(entry); err != nil {
+			log.Printf("Warning: Failed to cache response for %s: %v", url, err)
+		}
+	}
+	return body, nil
+}
 
-	cacheLock.Lock()
-	cache[url] = body
-	cacheTTL[url] = time.Now().Add(1 * time.Hour)
-	cacheLock.Unlock()
+func decode[T any](url string, body []byte, result *T) error {
+	if err := json.Unmarshal(body, result); err != nil {
+		return &FetchError{URL: url, Kind: ErrDecode, Err: err}
+	}
+	return nil
+}
 
-	return json.Unmarshal(body, result)
+// cacheTTL honours a max-age directive from the origin; zero means the cache
+// default applies.
+func cacheTTL(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.TrimSpace(directive)
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return 0
 }
 
-func FetchPokemonData(identifier any) (*Pokemon, error) {
+func FetchPokemonData(ctx context.Context, identifier any) (*Pokemon, error) {
 	var url string
 	switch v := identifier.(type) {
 	case string:
-		url = fmt.Sprintf("http://pokeapi.co/api/v2/pokemon/%s/", v)
+		url = fmt.Sprintf("%s/pokemon/%s/", apiBaseURL, v)
 	case int:
-		url = fmt.Sprintf("http://pokeapi.co/api/v2/pokemon/%d/", v)
+		url = fmt.Sprintf("%s/pokemon/%d/", apiBaseURL, v)
 	default:
 		return nil, fmt.Errorf("invalid identifier type")
 	}
 
 	var pokemon Pokemon
-	err := FetchData(url, &pokemon)
+	err := FetchData(ctx, url, &pokemon)
 	if err != nil {
 		return nil, err
 	}
@@ -73,9 +195,9 @@ func FetchPokemonData(identifier any) (*Pokemon, error) {
 	return &pokemon, nil
 }
 
-func FetchMoveData(url string) (*MoveInfo, error) {
+func FetchMoveData(ctx context.Context, url string) (*MoveInfo, error) {
 	var move MoveInfo
-	err := FetchData(url, &move)
+	err := FetchData(ctx, url, &move)
 	if err != nil {
 		return nil, err
 	}
@@ -83,12 +205,12 @@ func FetchMoveData(url string) (*MoveInfo, error) {
 	return &move, nil
 }
 
-func FetchMoveByName(name string) (*MoveInfo, error) {
-	url := fmt.Sprintf("https://pokeapi.co/api/v2/move/%s/", name)
-	return FetchMoveData(url)
+func FetchMoveByName(ctx context.Context, name string) (*MoveInfo, error) {
+	url := fmt.Sprintf("%s/move/%s/", apiBaseURL, name)
+	return FetchMoveData(ctx, url)
 }
 
-func FetchMovesInParallel(moveURLs []string) ([]*MoveInfo, error) {
+func FetchMovesInParallel(ctx context.Context, src DataSource, moveURLs []string) ([]*MoveInfo, error) {
 	moves := make([]*MoveInfo, len(moveURLs))
 	var wg sync.WaitGroup
 	errChan := make(chan error, len(moveURLs))
@@ -104,7 +226,7 @@ func FetchMovesInParallel(moveURLs []string) ([]*MoveInfo, error) {
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			move, err := FetchMoveData(moveURL)
+			move, err := src.MoveByURL(ctx, moveURL)
 			if err != nil {
 				errChan <- err
 				return
@@ -0,0 +1,110 @@
+package pokemon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/cache"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestFetchDataRevalidatesWithETag(t *testing.T) {
+	ctx := context.Background()
+	requests, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=1")
+		w.Write([]byte(`{"name":"tackle","power":40}`))
+	}))
+	defer srv.Close()
+
+	c, err := cache.New(cache.Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	pokemon.SetCache(c)
+	defer pokemon.SetCache(nil)
+
+	url := srv.URL + "/move/33/"
+	var move pokemon.MoveInfo
+	if err := pokemon.FetchData(ctx, url, &move); err != nil || move.Power != 40 {
+		t.Fatalf("First fetch failed: %v (%+v)", err, move)
+	}
+	if err := pokemon.FetchData(ctx, url, &move); err != nil {
+		t.Fatalf("Cached fetch failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected fresh cache hit to skip the network, got %d requests", requests)
+	}
+
+	entry, _ := c.Get(url)
+	entry.ExpiresAt = entry.StoredAt
+	c.Put(entry)
+
+	move = pokemon.MoveInfo{}
+	if err := pokemon.FetchData(ctx, url, &move); err != nil || move.Name != "tackle" {
+		t.Fatalf("Revalidated fetch failed: %v (%+v)", err, move)
+	}
+	if notModified != 1 {
+		t.Errorf("Expected a conditional request answered with 304, got %d", notModified)
+	}
+	if stats := c.Stats(); stats.Revalidated != 1 {
+		t.Errorf("Expected one revalidation, got %+v", stats)
+	}
+}
+
+func TestFetchDataTypedErrors(t *testing.T) {
+	ctx := context.Background()
+	hits := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[r.URL.Path]++
+		switch r.URL.Path {
+		case "/missing/":
+			http.NotFound(w, r)
+		case "/broken/":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(`{"name":`))
+		}
+	}))
+	defer srv.Close()
+	pokemon.SetCache(nil)
+
+	var move pokemon.MoveInfo
+	for i := 0; i < 2; i++ {
+		if err := pokemon.FetchData(ctx, srv.URL+"/missing/", &move); !errors.Is(err, pokemon.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	}
+	if hits["/missing/"] != 1 {
+		t.Errorf("Expected a 404 to be cached negatively, got %d requests", hits["/missing/"])
+	}
+
+	err := pokemon.FetchData(ctx, srv.URL+"/broken/", &move)
+	var fetchErr *pokemon.FetchError
+	if !errors.Is(err, pokemon.ErrUpstream) || !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Expected ErrUpstream with status 500, got %v", err)
+	}
+	if hits["/broken/"] != pokemon.DefaultRetryPolicy.MaxAttempts {
+		t.Errorf("Expected %d attempts for a 500, got %d", pokemon.DefaultRetryPolicy.MaxAttempts, hits["/broken/"])
+	}
+
+	if err := pokemon.FetchData(ctx, srv.URL+"/garbled/", &move); !errors.Is(err, pokemon.ErrDecode) {
+		t.Errorf("Expected ErrDecode, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := pokemon.FetchData(cancelled, srv.URL+"/other/", &move); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package pokemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DataSource resolves the species, move, type and item documents a battle
+// needs.
+// HTTPSource talks to PokeAPI; LocalSource reads a snapshot from disk or the
+// copy bundled into the binary. Failed lookups wrap ErrNotFound, ErrUpstream
+// or ErrDecode, or the context's error when ctx is done.
+type DataSource interface {
+	Pokemon(ctx context.Context, identifier any) (*Pokemon, error)
+	MoveByName(ctx context.Context, name string) (*MoveInfo, error)
+	MoveByURL(ctx context.Context, url string) (*MoveInfo, error)
+	Type(ctx context.Context, name string) (*TypeData, error)
+	Item(ctx context.Context, name string) (*ItemInfo, error)
+}
+
+// SpeciesLister is implemented by sources that only hold part of the dex, so
+// random squads are drawn from what is actually available.
+type SpeciesLister interface {
+	SpeciesIDs() []int
+}
+
+const apiBaseURL = "https://pokeapi.co/api/v2"
+
+type HTTPSource struct{}
+
+func NewHTTPSource() *HTTPSource {
+	return &HTTPSource{}
+}
+
+func (s *HTTPSource) Pokemon(ctx context.Context, identifier any) (*Pokemon, error) {
+	return FetchPokemonData(ctx, identifier)
+}
+
+func (s *HTTPSource) MoveByName(ctx context.Context, name string) (*MoveInfo, error) {
+	return FetchMoveByName(ctx, name)
+}
+
+func (s *HTTPSource) MoveByURL(ctx context.Context, url string) (*MoveInfo, error) {
+	return FetchMoveData(ctx, url)
+}
+
+func (s *HTTPSource) Type(ctx context.Context, name string) (*TypeData, error) {
+	var typeData TypeData
+	if err := FetchData(ctx, fmt.Sprintf("%s/type/%s/", apiBaseURL, name), &typeData); err != nil {
+		return nil, err
+	}
+	return &typeData, nil
+}
+
+func (s *HTTPSource) Item(ctx context.Context, name string) (*ItemInfo, error) {
+	var item ItemInfo
+	if err := FetchData(ctx, fmt.Sprintf("%s/item/%s/", apiBaseURL, name), &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// OpenSource maps a -data flag value to a source: "http" for PokeAPI,
+// "bundled" for the snapshot compiled into the binary, "auto" (or empty) for
+// the synced store in DefaultStoreDir when one exists and PokeAPI otherwise.
+// Anything else is a local store directory.
+func OpenSource(spec string) (DataSource, error) {
+	switch spec {
+	case "", "auto":
+		dir := DefaultStoreDir()
+		if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err == nil {
+			return OpenLocalSource(dir)
+		}
+		return NewHTTPSource(), nil
+	case "http":
+		return NewHTTPSource(), nil
+	case "bundled":
+		return BundledSource(), nil
+	default:
+		return OpenLocalSource(spec)
+	}
+}
+
+func resourceKey(url string) string {
+	trimmed := strings.TrimSuffix(url, "/")
+	if idx := strings.LastIndex(trimmed, "/"); idx >= 0 {
+		return trimmed[idx+1:]
+	}
+	return trimmed
+}
@@ -1,53 +1,148 @@
 package pokemon
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"math/rand/v2"
 	"sync"
 )
 
-func SelectRandSquad() []*Pokemon {
-	var squad []*Pokemon
-	var wg sync.WaitGroup
+const MaxDexNumber = 386
+
+// maxDraws bounds how many dex numbers one squad slot tries before giving up,
+// so a sparse source cannot loop forever on missing species.
+const maxDraws = 20
+
+// SelectRandSquad draws six distinct species from r. A species the source
+// does not have is redrawn; any other failure, including ctx being
+// cancelled, stops the draw and is returned. The same r state and source
+// always give the same squad. Each fetch is reported to progress.
+func SelectRandSquad(ctx context.Context, src DataSource, r *rand.Rand, progress io.Writer) ([]*Pokemon, error) {
 	var mu sync.Mutex
-	uniquePokemon := make(map[int]struct{})
-
-	for i := 0; i < 6; i++ {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-
-			var poke *Pokemon
-			var randNum int
-			for {
-				randNum = rand.Intn(386) + 1
-				if _, exists := uniquePokemon[randNum]; !exists {
-					break
-				}
+	report := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(progress, format, args...)
+	}
+	squad := make([]*Pokemon, 6)
+	errs := make([]error, len(squad))
+	taken := make(map[int]struct{})
+	candidates := dexCandidates(src)
+	if candidates != nil && len(candidates) < len(squad) {
+		return nil, fmt.Errorf("selecting squad: store holds only %d species, need %d", len(candidates), len(squad))
+	}
+
+	// Each round draws a dex number for every empty slot in slot order before
+	// fetching them in parallel, so the squad never depends on which fetch
+	// finishes first.
+	for draw := 0; draw < maxDraws; draw++ {
+		dexes := make([]int, len(squad))
+		for i := range squad {
+			if squad[i] != nil {
+				continue
+			}
+			dex, ok := drawDexNumber(r, candidates, taken)
+			if !ok {
+				report("\n")
+				return nil, fmt.Errorf("selecting squad: store holds only %d loadable species, need %d", countLoaded(squad), len(squad))
 			}
+			dexes[i] = dex
+		}
 
-			fmt.Printf("Fetching Pokémon #%d...\n", randNum)
-			poke, err := FetchPokemonData(randNum)
-			if err != nil {
-				mu.Lock()
-				fmt.Printf("Error fetching Pokémon #%d: %v\n", randNum, err)
-				mu.Unlock()
-				return
+		var wg sync.WaitGroup
+		for i, dex := range dexes {
+			if dex == 0 {
+				continue
 			}
+			wg.Add(1)
+			go func(i, dex int) {
+				defer wg.Done()
+				squad[i], errs[i] = fetchSquadMember(ctx, src, dex, report)
+			}(i, dex)
+		}
+		wg.Wait()
+
+		full := true
+		for i, err := range errs {
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				report("\n")
+				return nil, fmt.Errorf("selecting squad: %w", err)
+			}
+			full = full && squad[i] != nil
+		}
+		if full {
+			break
+		}
+	}
+
+	report("\n")
+	for i, poke := range squad {
+		if poke == nil {
+			return nil, fmt.Errorf("selecting squad: %w", errs[i])
+		}
+	}
+	return squad, nil
+}
 
-			mu.Lock()
-			uniquePokemon[randNum] = struct{}{}
-			fmt.Printf("Completed fetching Pokémon #%d: %s\n", randNum, poke.Name)
-			mu.Unlock()
+// drawDexNumber draws a dex number not yet taken and marks it taken. It
+// reports false once every candidate has been taken.
+func drawDexNumber(r *rand.Rand, candidates []int, taken map[int]struct{}) (int, bool) {
+	available := MaxDexNumber
+	if candidates != nil {
+		available = len(candidates)
+	}
+	if len(taken) >= available {
+		return 0, false
+	}
+	for {
+		dex := randDexNumber(r, candidates)
+		if _, exists := taken[dex]; !exists {
+			taken[dex] = struct{}{}
+			return dex, true
+		}
+	}
+}
+
+func countLoaded(squad []*Pokemon) int {
+	n := 0
+	for _, poke := range squad {
+		if poke != nil {
+			n++
+		}
+	}
+	return n
+}
 
-			mu.Lock()
-			squad = append(squad, poke)
-			mu.Unlock()
-		}(i)
+func fetchSquadMember(ctx context.Context, src DataSource, dex int, report func(format string, args ...any)) (*Pokemon, error) {
+	report("Fetching Pokémon #%d...\n", dex)
+	poke, err := src.Pokemon(ctx, dex)
+	if err != nil {
+		report("Error fetching Pokémon #%d: %v\n", dex, err)
+		return nil, err
 	}
+	report("Completed fetching Pokémon #%d: %s\n", dex, poke.Name)
+	return poke, nil
+}
 
-	wg.Wait()
+func dexCandidates(src DataSource) []int {
+	lister, ok := src.(SpeciesLister)
+	if !ok {
+		return nil
+	}
+	ids := []int{}
+	for _, id := range lister.SpeciesIDs() {
+		if id >= 1 && id <= MaxDexNumber {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
 
-	fmt.Println("")
-	return squad
+func randDexNumber(r *rand.Rand, candidates []int) int {
+	if candidates != nil {
+		return candidates[r.IntN(len(candidates))]
+	}
+	return r.IntN(MaxDexNumber) + 1
 }
@@ -0,0 +1,430 @@
+package pokemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const StoreFormatVersion = 1
+
+type Manifest struct {
+	Version   int               `json:"version"`
+	Source    string            `json:"source"`
+	DexFrom   int               `json:"dex_from"`
+	DexTo     int               `json:"dex_to"`
+	Complete  bool              `json:"complete"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Files     map[string]string `json:"files"`
+}
+
+type SyncOptions struct {
+	Dir         string
+	DexFrom     int
+	DexTo       int
+	Concurrency int
+	BaseURL     string
+	// Items lists the held items to mirror alongside the species.
+	Items []string
+	Logf  func(format string, args ...any)
+}
+
+type SyncReport struct {
+	Downloaded int
+	Skipped    int
+	Failed     int
+}
+
+func DefaultStoreDir() string {
+	if dir := os.Getenv("POKEBATTLECLI_DATA_DIR"); dir != "" {
+		return dir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		base = "."
+	}
+	return filepath.Join(base, "pokebattlecli", "data")
+}
+
+func ReadManifest(fsys fs.FS) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, "manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+	if manifest.Version > StoreFormatVersion {
+		return nil, fmt.Errorf("data store format v%d is newer than supported v%d", manifest.Version, StoreFormatVersion)
+	}
+	return &manifest, nil
+}
+
+type storeWriter struct {
+	ctx      context.Context
+	dir      string
+	baseURL  string
+	logf     func(format string, args ...any)
+	mu       sync.Mutex
+	manifest *Manifest
+	index    localIndex
+	report   SyncReport
+}
+
+// SyncStore mirrors every species in the dex range, every move they can
+// learn, every type they reference and the requested held items into a
+// local store that LocalSource can serve. Documents already present with a
+// matching checksum are skipped, so an interrupted sync picks up where it
+// left off. Cancelling ctx stops scheduling new downloads and saves what
+// was fetched so far.
+func SyncStore(ctx context.Context, opts SyncOptions) (SyncReport, error) {
+	if opts.DexFrom < 1 || opts.DexTo < opts.DexFrom {
+		return SyncReport{}, fmt.Errorf("invalid dex range %d-%d", opts.DexFrom, opts.DexTo)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = apiBaseURL
+	}
+	if opts.Logf == nil {
+		opts.Logf = func(string, ...any) {}
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return SyncReport{}, fmt.Errorf("creating data store: %w", err)
+	}
+
+	w := &storeWriter{ctx: ctx, dir: opts.Dir, baseURL: opts.BaseURL, logf: opts.Logf}
+	if err := w.load(opts); err != nil {
+		return SyncReport{}, err
+	}
+
+	var species []*Pokemon
+	var speciesLock sync.Mutex
+	opts.Logf("Syncing species #%d-#%d into %s", opts.DexFrom, opts.DexTo, opts.Dir)
+	w.each(opts.Concurrency, rangeKeys(opts.DexFrom, opts.DexTo), func(key string) {
+		var poke Pokemon
+		if !w.fetch("pokemon", key, &poke) {
+			return
+		}
+		speciesLock.Lock()
+		species = append(species, &poke)
+		speciesLock.Unlock()
+	})
+	if err := w.save(false); err != nil {
+		return w.report, fmt.Errorf("saving data store: %w", err)
+	}
+
+	moveKeys := make(map[string]struct{})
+	typeKeys := make(map[string]struct{})
+	for _, poke := range species {
+		for _, slot := range poke.Moves {
+			moveKeys[resourceKey(slot.Move.URL)] = struct{}{}
+		}
+		for _, slot := range poke.Types {
+			typeKeys[resourceKey(slot.Type.URL)] = struct{}{}
+		}
+	}
+	var typeLock sync.Mutex
+	w.each(opts.Concurrency, sortedKeys(moveKeys), func(key string) {
+		var move MoveInfo
+		if w.fetch("move", key, &move) {
+			typeLock.Lock()
+			typeKeys[resourceKey(move.Type.URL)] = struct{}{}
+			typeLock.Unlock()
+		}
+	})
+	if err := w.save(false); err != nil {
+		return w.report, fmt.Errorf("saving data store: %w", err)
+	}
+
+	w.each(opts.Concurrency, sortedKeys(typeKeys), func(key string) {
+		var typeData TypeData
+		w.fetch("type", key, &typeData)
+	})
+
+	w.each(opts.Concurrency, opts.Items, func(key string) {
+		var item ItemInfo
+		w.fetch("item", key, &item)
+	})
+
+	if err := ctx.Err(); err != nil {
+		if saveErr := w.save(false); saveErr != nil {
+			return w.report, errors.Join(err, fmt.Errorf("saving data store: %w", saveErr))
+		}
+		return w.report, err
+	}
+	complete := w.report.Failed == 0
+	if err := w.save(complete); err != nil {
+		return w.report, err
+	}
+	if !complete {
+		return w.report, fmt.Errorf("%d documents failed to download; run sync again to resume", w.report.Failed)
+	}
+	return w.report, nil
+}
+
+func (w *storeWriter) load(opts SyncOptions) error {
+	fsys := os.DirFS(w.dir)
+	manifest, err := ReadManifest(fsys)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		manifest = &Manifest{CreatedAt: time.Now(), Files: make(map[string]string)}
+	case err != nil:
+		return err
+	}
+	manifest.Version = StoreFormatVersion
+	manifest.Source = opts.BaseURL
+	if manifest.DexTo == 0 || opts.DexFrom < manifest.DexFrom {
+		manifest.DexFrom = opts.DexFrom
+	}
+	if opts.DexTo > manifest.DexTo {
+		manifest.DexTo = opts.DexTo
+	}
+	manifest.Complete = false
+	w.manifest = manifest
+
+	w.index = localIndex{Pokemon: map[string]string{}, Move: map[string]string{}, Type: map[string]string{}, Item: map[string]string{}}
+	if data, err := fs.ReadFile(fsys, "index.json"); err == nil {
+		if err := json.Unmarshal(data, &w.index); err != nil {
+			return fmt.Errorf("decoding local index: %w", err)
+		}
+		if w.index.Item == nil {
+			w.index.Item = map[string]string{}
+		}
+	}
+	return nil
+}
+
+func (w *storeWriter) each(limit int, keys []string, fn func(key string)) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, limit)
+	for _, key := range keys {
+		select {
+		case <-w.ctx.Done():
+			wg.Wait()
+			return
+		case semaphore <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			fn(key)
+		}(key)
+	}
+	wg.Wait()
+}
+
+// fetch stores the raw document for kind/key and decodes it into result. It
+// reads from disk instead when the stored copy still matches the manifest.
+func (w *storeWriter) fetch(kind, key string, result any) bool {
+	w.mu.Lock()
+	name, known := w.lookup(kind, key)
+	w.mu.Unlock()
+
+	if known {
+		rel := path.Join(kind, name+".json")
+		if data, ok := w.stored(rel); ok {
+			if err := json.Unmarshal(data, result); err == nil {
+				w.mu.Lock()
+				w.report.Skipped++
+				w.mu.Unlock()
+				return true
+			}
+		}
+	}
+
+	var raw json.RawMessage
+	url := fmt.Sprintf("%s/%s/%s/", w.baseURL, kind, key)
+	if err := FetchData(w.ctx, url, &raw); err != nil {
+		w.fail(kind, key, err)
+		return false
+	}
+	var ident struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &ident); err != nil || ident.Name == "" {
+		w.fail(kind, key, fmt.Errorf("document has no name: %v", err))
+		return false
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		w.fail(kind, key, err)
+		return false
+	}
+
+	rel := path.Join(kind, ident.Name+".json")
+	if err := writeFileAtomic(filepath.Join(w.dir, filepath.FromSlash(rel)), raw); err != nil {
+		w.fail(kind, key, err)
+		return false
+	}
+
+	w.mu.Lock()
+	w.manifest.Files[rel] = checksum(raw)
+	w.record(kind, strconv.Itoa(ident.ID), ident.Name)
+	w.report.Downloaded++
+	w.mu.Unlock()
+	w.logf("Synced %s %s", kind, ident.Name)
+	return true
+}
+
+func (w *storeWriter) lookup(kind, key string) (string, bool) {
+	if _, err := strconv.Atoi(key); err != nil {
+		return key, true
+	}
+	name, ok := w.ids(kind)[key]
+	return name, ok
+}
+
+func (w *storeWriter) ids(kind string) map[string]string {
+	switch kind {
+	case "pokemon":
+		return w.index.Pokemon
+	case "move":
+		return w.index.Move
+	case "item":
+		return w.index.Item
+	default:
+		return w.index.Type
+	}
+}
+
+func (w *storeWriter) record(kind, id, name string) {
+	w.ids(kind)[id] = name
+}
+
+func (w *storeWriter) stored(rel string) ([]byte, bool) {
+	w.mu.Lock()
+	sum, ok := w.manifest.Files[rel]
+	w.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(w.dir, filepath.FromSlash(rel)))
+	if err != nil || checksum(data) != sum {
+		return nil, false
+	}
+	return data, true
+}
+
+func (w *storeWriter) fail(kind, key string, err error) {
+	w.mu.Lock()
+	w.report.Failed++
+	w.mu.Unlock()
+	w.logf("Failed to sync %s %s: %v", kind, key, err)
+}
+
+func (w *storeWriter) save(complete bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.manifest.Complete = complete
+	w.manifest.UpdatedAt = time.Now()
+	indexData, err := json.MarshalIndent(w.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(w.dir, "index.json"), indexData); err != nil {
+		return err
+	}
+	manifestData, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(w.dir, "manifest.json"), manifestData)
+}
+
+// VerifyStore checks every file listed in the manifest against its recorded
+// checksum and that the index only points at files that exist.
+func VerifyStore(dir string) ([]string, error) {
+	fsys := os.DirFS(dir)
+	manifest, err := ReadManifest(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, rel := range sortedKeys(manifest.Files) {
+		data, err := fs.ReadFile(fsys, rel)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: missing", rel))
+			continue
+		}
+		if checksum(data) != manifest.Files[rel] {
+			problems = append(problems, fmt.Sprintf("%s: checksum mismatch", rel))
+		}
+	}
+
+	src := NewLocalSource(fsys)
+	if err := src.loadIndex(); err != nil {
+		return nil, err
+	}
+	for kind, ids := range map[string]map[string]string{"pokemon": src.index.Pokemon, "move": src.index.Move, "type": src.index.Type, "item": src.index.Item} {
+		for id, name := range ids {
+			if _, ok := manifest.Files[path.Join(kind, name+".json")]; !ok {
+				problems = append(problems, fmt.Sprintf("index: %s #%s (%s) not in manifest", kind, id, name))
+			}
+		}
+	}
+	if !manifest.Complete {
+		problems = append(problems, "manifest: last sync did not complete")
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+func rangeKeys(from, to int) []string {
+	keys := make([]string, 0, to-from+1)
+	for id := from; id <= to; id++ {
+		keys = append(keys, strconv.Itoa(id))
+	}
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,105 @@
+package pokemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+// snapshotServer serves the bundled snapshot the way PokeAPI lays out its
+// URLs, resolving numeric IDs through the snapshot index.
+func snapshotServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	sub := os.DirFS("snapshot")
+	indexData, err := fs.ReadFile(sub, "index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index map[string]map[string]string
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 2 {
+			http.NotFound(w, r)
+			return
+		}
+		kind, key := parts[0], parts[1]
+		if name, ok := index[kind][key]; ok {
+			key = name
+		}
+		data, err := fs.ReadFile(sub, kind+"/"+key+".json")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+func TestSyncStoreIsResumableAndVerifiable(t *testing.T) {
+	ctx := context.Background()
+	pokemon.SetCache(nil)
+	var requests atomic.Int32
+	srv := snapshotServer(t, &requests)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	opts := pokemon.SyncOptions{Dir: dir, DexFrom: 25, DexTo: 26, Concurrency: 4, BaseURL: srv.URL}
+	report, err := pokemon.SyncStore(ctx, opts)
+	if err != nil {
+		t.Fatalf("Sync failed: %v (%+v)", err, report)
+	}
+	if report.Downloaded == 0 || report.Failed != 0 {
+		t.Fatalf("Unexpected first sync report: %+v", report)
+	}
+
+	problems, err := pokemon.VerifyStore(dir)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Expected a clean store, got %v (err %v)", problems, err)
+	}
+
+	src, err := pokemon.OpenLocalSource(dir)
+	if err != nil {
+		t.Fatalf("Failed to open synced store: %v", err)
+	}
+	raichu, err := src.Pokemon(ctx, 26)
+	if err != nil {
+		t.Fatalf("Failed to load raichu from synced store: %v", err)
+	}
+	for _, slot := range raichu.Moves {
+		if _, err := src.MoveByURL(ctx, slot.Move.URL); err != nil {
+			t.Errorf("Move %s missing from synced store: %v", slot.Move.Name, err)
+		}
+	}
+	if _, err := src.Type(ctx, "electric"); err != nil {
+		t.Errorf("Type electric missing from synced store: %v", err)
+	}
+
+	requests.Store(0)
+	report, err = pokemon.SyncStore(ctx, opts)
+	if err != nil || report.Downloaded != 0 || requests.Load() != 0 {
+		t.Errorf("Expected resumed sync to skip everything, got %+v with %d requests (err %v)", report, requests.Load(), err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "move", "thunderbolt.json"), []byte("{}"), 0o644)
+	problems, _ = pokemon.VerifyStore(dir)
+	if len(problems) != 1 || !strings.Contains(problems[0], "thunderbolt") {
+		t.Errorf("Expected verify to flag the corrupted move, got %v", problems)
+	}
+	report, err = pokemon.SyncStore(ctx, opts)
+	if err != nil || report.Downloaded != 1 {
+		t.Errorf("Expected sync to repair exactly one document, got %+v (err %v)", report, err)
+	}
+}
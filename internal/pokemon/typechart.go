@@ -0,0 +1,170 @@
+package pokemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// LatestGeneration is the generation whose rules apply when none is given.
+const LatestGeneration = 9
+
+// TypeNames lists every type in PokeAPI order.
+var TypeNames = []string{
+	"normal", "fighting", "flying", "poison", "ground", "rock", "bug", "ghost", "steel",
+	"fire", "water", "grass", "electric", "psychic", "ice", "dragon", "dark", "fairy",
+}
+
+// TypeChart holds the type matchups of one generation. Types introduced
+// after that generation are absent from it.
+type TypeChart struct {
+	Generation  int
+	types       map[string]bool
+	multipliers map[string]map[string]float64
+}
+
+// NewTypeChart builds the chart for generation from PokeAPI type documents,
+// using each type's past damage relations where the generation predates a
+// change. A generation of 0 means LatestGeneration.
+func NewTypeChart(generation int, docs []*TypeData) *TypeChart {
+	if generation <= 0 {
+		generation = LatestGeneration
+	}
+	chart := &TypeChart{
+		Generation:  generation,
+		types:       make(map[string]bool),
+		multipliers: make(map[string]map[string]float64),
+	}
+	for _, doc := range docs {
+		if introduced := generationNumber(doc.Generation.Name); introduced == 0 || introduced <= generation {
+			chart.types[doc.Name] = true
+		}
+	}
+	for _, doc := range docs {
+		if !chart.types[doc.Name] {
+			continue
+		}
+		relations := relationsFor(doc, generation)
+		row := make(map[string]float64)
+		for mult, targets := range map[float64][]ApiResource{
+			2:   relations.DoubleDamageTo,
+			0.5: relations.HalfDamageTo,
+			0:   relations.NoDamageTo,
+		} {
+			for _, target := range targets {
+				if chart.types[target.Name] {
+					row[target.Name] = mult
+				}
+			}
+		}
+		chart.multipliers[doc.Name] = row
+	}
+	return chart
+}
+
+// relationsFor picks the relations in force in generation: the earliest
+// past entry that still covers it, or the current ones.
+func relationsFor(doc *TypeData, generation int) DamageRelations {
+	best := 0
+	relations := doc.DamageRelations
+	for _, past := range doc.PastDamageRelations {
+		until := generationNumber(past.Generation.Name)
+		if until >= generation && (best == 0 || until < best) {
+			best = until
+			relations = past.DamageRelations
+		}
+	}
+	return relations
+}
+
+// Has reports whether typeName exists in this chart's generation.
+func (c *TypeChart) Has(typeName string) bool {
+	return c.types[typeName]
+}
+
+// Multiplier is the damage multiplier of an attack type against a single
+// defending type.
+func (c *TypeChart) Multiplier(attack, defend string) float64 {
+	if mult, ok := c.multipliers[attack][defend]; ok {
+		return mult
+	}
+	return 1
+}
+
+// Effectiveness multiplies the matchups against every defending type.
+func (c *TypeChart) Effectiveness(attack string, defending ...string) float64 {
+	effectiveness := 1.0
+	for _, t := range defending {
+		effectiveness *= c.Multiplier(attack, t)
+	}
+	return effectiveness
+}
+
+// LoadTypeChart builds the chart for generation from src's type documents.
+// Types src cannot provide are read from the bundled snapshot instead.
+func LoadTypeChart(ctx context.Context, src DataSource, generation int) (*TypeChart, error) {
+	docs := make([]*TypeData, 0, len(TypeNames))
+	for _, name := range TypeNames {
+		doc, err := src.Type(ctx, name)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDecode) {
+			doc, err = BundledSource().Type(ctx, name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading type %s: %w", name, err)
+		}
+		docs = append(docs, doc)
+	}
+	return NewTypeChart(generation, docs), nil
+}
+
+var (
+	bundledChartsMu sync.Mutex
+	bundledCharts   = map[int]*TypeChart{}
+)
+
+// BundledTypeChart returns the chart for generation built from the bundled
+// snapshot. Charts are built once and shared.
+func BundledTypeChart(generation int) *TypeChart {
+	if generation <= 0 {
+		generation = LatestGeneration
+	}
+	bundledChartsMu.Lock()
+	defer bundledChartsMu.Unlock()
+	if chart, ok := bundledCharts[generation]; ok {
+		return chart
+	}
+	chart, err := LoadTypeChart(context.Background(), BundledSource(), generation)
+	if err != nil {
+		log.Printf("Error building bundled type chart: %v", err)
+		chart = NewTypeChart(generation, nil)
+	}
+	bundledCharts[generation] = chart
+	return chart
+}
+
+var romanDigits = map[byte]int{'i': 1, 'v': 5, 'x': 10}
+
+// generationNumber converts a PokeAPI generation name such as
+// "generation-iv" to 4. It returns 0 for anything else.
+func generationNumber(name string) int {
+	numeral, ok := strings.CutPrefix(name, "generation-")
+	if !ok || numeral == "" {
+		return 0
+	}
+	total := 0
+	for i := 0; i < len(numeral); i++ {
+		v, ok := romanDigits[numeral[i]]
+		if !ok {
+			return 0
+		}
+		if i+1 < len(numeral) && romanDigits[numeral[i+1]] > v {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	return total
+}
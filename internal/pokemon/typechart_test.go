@@ -0,0 +1,46 @@
+package pokemon_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+func TestTypeChartByGeneration(t *testing.T) {
+	tests := []struct {
+		generation     int
+		attack, defend string
+		want           float64
+	}{
+		{1, "ghost", "psychic", 0},
+		{1, "bug", "poison", 2},
+		{1, "ice", "fire", 1},
+		{3, "ghost", "psychic", 2},
+		{3, "dark", "steel", 0.5},
+		{5, "ghost", "steel", 0.5},
+		{6, "ghost", "steel", 1},
+		{6, "dragon", "fairy", 0},
+		{0, "fairy", "dragon", 2},
+		{0, "electric", "ground", 0},
+	}
+	for _, tt := range tests {
+		chart := pokemon.BundledTypeChart(tt.generation)
+		if got := chart.Multiplier(tt.attack, tt.defend); got != tt.want {
+			t.Errorf("gen %d: %s vs %s = %v, want %v", tt.generation, tt.attack, tt.defend, got, tt.want)
+		}
+	}
+
+	gen5 := pokemon.BundledTypeChart(5)
+	if gen5.Has("fairy") {
+		t.Error("Fairy should not exist in generation 5")
+	}
+	if got := gen5.Multiplier("dragon", "fairy"); got != 1 {
+		t.Errorf("Generation 5 should ignore Fairy matchups, got %v", got)
+	}
+	if pokemon.BundledTypeChart(1).Has("dark") {
+		t.Error("Dark should not exist in generation 1")
+	}
+	if got := pokemon.BundledTypeChart(0).Effectiveness("ground", "fire", "rock"); got != 4 {
+		t.Errorf("Expected ground to be 4x against fire/rock, got %v", got)
+	}
+}
@@ -1,17 +1,132 @@
 package stats
 
-import "github.com/ross1116/pokebattlecli/internal/pokemon"
+import (
+	"fmt"
+	"math"
 
-func HpCalc(basehp int) float64 {
-	iv := 31
-	level := 100
-	return float64((2*basehp+iv)*level/100 + level + 10)
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+)
+
+const (
+	MaxIV       = 31
+	MaxEV       = 252
+	MaxTotalEVs = 510
+	MaxLevel    = 100
+)
+
+// Names lists the six stats in PokeAPI order.
+var Names = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// Block holds one value per stat: IVs, EVs or the computed stats themselves.
+type Block struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+}
+
+// Uniform returns a block with every stat set to v.
+func Uniform(v int) Block {
+	return Block{HP: v, Attack: v, Defense: v, SpecialAttack: v, SpecialDefense: v, Speed: v}
+}
+
+// Get returns the value for a PokeAPI stat name, or 0 for anything else.
+func (b Block) Get(name string) int {
+	if p := b.field(name); p != nil {
+		return *p
+	}
+	return 0
+}
+
+// Set stores the value for a PokeAPI stat name.
+func (b *Block) Set(name string, v int) error {
+	p := b.field(name)
+	if p == nil {
+		return fmt.Errorf("unknown stat %q", name)
+	}
+	*p = v
+	return nil
 }
 
-func StatCalc(basestat int) float64 {
-	iv := 31
-	level := 100
-	return float64((2*basestat+iv)*level/100 + 5)
+func (b *Block) field(name string) *int {
+	switch name {
+	case "hp":
+		return &b.HP
+	case "attack":
+		return &b.Attack
+	case "defense":
+		return &b.Defense
+	case "special-attack":
+		return &b.SpecialAttack
+	case "special-defense":
+		return &b.SpecialDefense
+	case "speed":
+		return &b.Speed
+	}
+	return nil
+}
+
+func (b Block) total() int {
+	return b.HP + b.Attack + b.Defense + b.SpecialAttack + b.SpecialDefense + b.Speed
+}
+
+// StatSpread is everything besides the species that decides a Pokémon's
+// stats.
+type StatSpread struct {
+	IVs    Block  `json:"ivs"`
+	EVs    Block  `json:"evs"`
+	Nature string `json:"nature"`
+	Level  int    `json:"level"`
+}
+
+// DefaultSpread is a level 100 Pokémon with perfect IVs, no EVs and a
+// neutral nature.
+func DefaultSpread() StatSpread {
+	return StatSpread{IVs: Uniform(MaxIV), Nature: "hardy", Level: MaxLevel}
+}
+
+// Validate checks the spread against the limits the games enforce.
+func (s StatSpread) Validate() error {
+	if s.Level < 1 || s.Level > MaxLevel {
+		return fmt.Errorf("level %d out of range 1-%d", s.Level, MaxLevel)
+	}
+	if _, ok := natures[s.Nature]; !ok {
+		return fmt.Errorf("unknown nature %q", s.Nature)
+	}
+	for _, name := range Names {
+		if iv := s.IVs.Get(name); iv < 0 || iv > MaxIV {
+			return fmt.Errorf("%s IV %d out of range 0-%d", name, iv, MaxIV)
+		}
+		if ev := s.EVs.Get(name); ev < 0 || ev > MaxEV {
+			return fmt.Errorf("%s EV %d out of range 0-%d", name, ev, MaxEV)
+		}
+	}
+	if total := s.EVs.total(); total > MaxTotalEVs {
+		return fmt.Errorf("EVs total %d, more than %d", total, MaxTotalEVs)
+	}
+	return nil
+}
+
+// Compute works out the full stat block for p with the given spread, using
+// the formulas from Generation III onwards.
+func Compute(p *pokemon.Pokemon, s StatSpread) Block {
+	var out Block
+	for _, name := range Names {
+		base := GetStat(p, name)
+		raw := (2*base + s.IVs.Get(name) + s.EVs.Get(name)/4) * s.Level / 100
+		if name == "hp" {
+			if base == 1 {
+				out.HP = 1
+			} else {
+				out.HP = raw + s.Level + 10
+			}
+			continue
+		}
+		out.Set(name, int(math.Floor(float64(raw+5)*NatureModifier(s.Nature, name))))
+	}
+	return out
 }
 
 func GetStat(p *pokemon.Pokemon, statName string) int {
@@ -0,0 +1,57 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
+	"github.com/ross1116/pokebattlecli/internal/stats"
+)
+
+func species(base ...int) *pokemon.Pokemon {
+	p := &pokemon.Pokemon{Name: "test"}
+	for i, name := range stats.Names {
+		p.Stats = append(p.Stats, pokemon.BaseStats{BaseStat: base[i], Stat: pokemon.ApiResource{Name: name}})
+	}
+	return p
+}
+
+func TestComputeMatchesGameFormulas(t *testing.T) {
+	// The worked Garchomp example from Bulbapedia.
+	garchomp := species(108, 130, 95, 80, 85, 102)
+	spread := stats.StatSpread{
+		IVs:    stats.Block{HP: 24, Attack: 12, Defense: 30, SpecialAttack: 16, SpecialDefense: 23, Speed: 5},
+		EVs:    stats.Block{HP: 74, Attack: 190, Defense: 91, SpecialAttack: 48, SpecialDefense: 84, Speed: 23},
+		Nature: "adamant",
+		Level:  78,
+	}
+	if err := spread.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := stats.Block{HP: 289, Attack: 278, Defense: 193, SpecialAttack: 135, SpecialDefense: 171, Speed: 171}
+	if got := stats.Compute(garchomp, spread); got != want {
+		t.Errorf("Compute = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateEnforcesLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*stats.StatSpread)
+	}{
+		{"level", func(s *stats.StatSpread) { s.Level = 101 }},
+		{"nature", func(s *stats.StatSpread) { s.Nature = "grumpy" }},
+		{"iv", func(s *stats.StatSpread) { s.IVs.Speed = 32 }},
+		{"single ev", func(s *stats.StatSpread) { s.EVs.Attack = 253 }},
+		{"ev total", func(s *stats.StatSpread) { s.EVs = stats.Block{HP: 252, Attack: 252, Speed: 8} }},
+	}
+	for _, tt := range tests {
+		spread := stats.DefaultSpread()
+		tt.modify(&spread)
+		if err := spread.Validate(); err == nil {
+			t.Errorf("%s: expected an error for %+v", tt.name, spread)
+		}
+	}
+	if err := stats.DefaultSpread().Validate(); err != nil {
+		t.Errorf("Default spread should be valid: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// Nature raises one stat by 10% and lowers another by 10%. The five natures
+// that name the same stat twice are neutral.
+type Nature struct {
+	Name      string
+	Increased string
+	Decreased string
+}
+
+var natures = map[string]Nature{}
+
+func init() {
+	order := []string{"attack", "defense", "speed", "special-attack", "special-defense"}
+	names := [][]string{
+		{"hardy", "lonely", "brave", "adamant", "naughty"},
+		{"bold", "docile", "relaxed", "impish", "lax"},
+		{"timid", "hasty", "serious", "jolly", "naive"},
+		{"modest", "mild", "quiet", "bashful", "rash"},
+		{"calm", "gentle", "sassy", "careful", "quirky"},
+	}
+	for i, row := range names {
+		for j, name := range row {
+			n := Nature{Name: name}
+			if i != j {
+				n.Increased, n.Decreased = order[i], order[j]
+			}
+			natures[name] = n
+		}
+	}
+}
+
+// LookupNature returns the named nature and whether it exists.
+func LookupNature(name string) (Nature, bool) {
+	n, ok := natures[name]
+	return n, ok
+}
+
+// NatureNames lists every nature, sorted by name.
+func NatureNames() []string {
+	names := make([]string, 0, len(natures))
+	for name := range natures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RandomNature picks one of the 25 natures uniformly from r.
+func RandomNature(r *rand.Rand) string {
+	names := NatureNames()
+	return names[r.IntN(len(names))]
+}
+
+// NatureModifier is the multiplier nature applies to stat.
+func NatureModifier(nature, stat string) float64 {
+	n := natures[nature]
+	if n.Increased == "" {
+		return 1
+	}
+	switch stat {
+	case n.Increased:
+		return 1.1
+	case n.Decreased:
+		return 0.9
+	}
+	return 1
+}
@@ -0,0 +1,9 @@
+package server
+
+// AddClient registers client under username as if it had logged in, so tests
+// can drive the handlers without a listener.
+func (server *Server) AddClient(username string, client *Client) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.clients[username] = client
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/ross1116/pokebattlecli/internal/battle"
 	"github.com/ross1116/pokebattlecli/internal/pokemon"
-	"github.com/ross1116/pokebattlecli/internal/stats"
 )
 
 type PokemonStateInfo struct {
@@ -21,12 +20,19 @@ type PokemonStateInfo struct {
 	HPPercent  float64 `json:"hp_percent"`
 	Fainted    bool    `json:"fainted"`
 	Status     string  `json:"status"`
+	Item       string  `json:"item,omitempty"`
+	// StatStages holds the non-zero stat stages of the active Pokémon.
+	StatStages map[string]int `json:"stat_stages,omitempty"`
 }
 
 type MoveStateInfo struct {
 	Name      string `json:"name"`
 	CurrentPP int    `json:"current_pp"`
 	MaxPP     int    `json:"max_pp"`
+	Disabled  string `json:"disabled,omitempty"`
+	// Forced marks the only move the Pokémon may use, because it is locked
+	// into a multi-turn move or must Struggle. It needs no PP.
+	Forced bool `json:"forced,omitempty"`
 }
 
 func getSquadStateInfo(squad []*battle.BattlePokemon) []PokemonStateInfo {
@@ -39,27 +45,22 @@ func getSquadStateInfo(squad []*battle.BattlePokemon) []PokemonStateInfo {
 			info[i] = PokemonStateInfo{SquadIndex: i, Name: "(Error)"}
 			continue
 		}
-		maxHP := 0.0
-		hpBaseStat := stats.GetStat(p.Base, "hp")
-		if hpBaseStat > 0 {
-			maxHP = stats.HpCalc(hpBaseStat)
-		} else {
-			log.Printf("Warning: Could not get HP base stat for %s", p.Base.Name)
-		}
+		maxHP := p.MaxHP()
 		hpPercent := 0.0
 		if maxHP > 0 {
 			hpPercent = math.Max(0, math.Min(100, (p.CurrentHP/maxHP)*100.0))
 		}
 		info[i] = PokemonStateInfo{
 			SquadIndex: i, Name: p.Base.Name, CurrentHP: p.CurrentHP, MaxHP: maxHP,
-			HPPercent: hpPercent, Fainted: p.Fainted, Status: p.Status,
+			HPPercent: hpPercent, Fainted: p.Fainted, Status: p.Status, Item: p.Item,
+			StatStages: battle.GetPokemonFullView(p).StatStages,
 		}
 	}
 	return info
 }
 
-func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*battle.BattlePokemon, moveset1, moveset2 [][]*pokemon.MoveInfo) {
-	battleState := NewBattleState(player1.Username, player2.Username, squad1, squad2)
+func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*battle.BattlePokemon, lead1, lead2 int, field *battle.Field) {
+	battleState := NewBattleState(player1.Username, player2.Username, squad1, squad2, lead1, lead2, field)
 	log.Printf("Starting game loop goroutine for player1=%s and player2=%s", player1.Username, player2.Username)
 
 	server.mu.Lock()
@@ -98,6 +99,10 @@ func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*ba
 		log.Printf("Game end signaled to HandleClients for %s and %s", player1.Username, player2.Username)
 	}()
 
+	b := battleState.Battle
+	players := [2]*Client{player1, player2}
+	pendingEvents := b.Start()
+
 	for {
 		p1Connected := player1.Conn != nil
 		p2Connected := player2.Conn != nil
@@ -112,122 +117,58 @@ func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*ba
 			return
 		}
 
-		p1Lost := battle.IsAllFainted(battleState.Player1Team)
-		p2Lost := battle.IsAllFainted(battleState.Player2Team)
-		if p1Lost || p2Lost {
-			log.Printf("Game over detected at start of Turn %d. P1 Lost: %v, P2 Lost: %v", battleState.TurnNumber, p1Lost, p2Lost)
-			resultP1, resultP2 := "lose", "win"
-			if p1Lost && p2Lost {
-				resultP1, resultP2 = "draw", "draw"
-			}
-			if p1Lost {
-				resultP1, resultP2 = "lose", "win"
-			}
-			if p2Lost {
-				resultP1, resultP2 = "win", "lose"
-			}
-			if player1.Conn != nil {
-				server.sendGameEnd(player1, player2, resultP1)
-			}
-			if player2.Conn != nil {
-				server.sendGameEnd(player2, player1, resultP2)
-			}
+		if b.Over() {
+			log.Printf("Game over detected at start of Turn %d. P1 Lost: %v, P2 Lost: %v", b.Turn, b.Lost(0), b.Lost(1))
+			server.endGame(player1, player2, b)
 			return
 		}
 
-		p1MustSwitchAtTurnStart := battleState.Player1Team[battleState.Player1ActiveIndex].Fainted
-		p2MustSwitchAtTurnStart := battleState.Player2Team[battleState.Player2ActiveIndex].Fainted
-		log.Printf("Turn %d: Start of turn faint check: P1 Must Switch: %t, P2 Must Switch: %t", battleState.TurnNumber, p1MustSwitchAtTurnStart, p2MustSwitchAtTurnStart)
+		turn := b.Turn
+		reqs := [2]*battle.Request{b.RequestsFor(0), b.RequestsFor(1)}
+		for side, player := range players {
+			server.SendResponse(player.Conn, Response{Type: "turn_request", Message: turnRequestPayload(turn, reqs[side], b.Field)})
+		}
+		log.Printf("Turn %d: Sent turn requests to %s and %s", turn, player1.Username, player2.Username)
 
-		p1Payload := map[string]interface{}{"turn": battleState.TurnNumber, "force_switch": p1MustSwitchAtTurnStart}
-		if p1MustSwitchAtTurnStart {
-			p1Payload["available_moves_info"] = []MoveStateInfo{}
+		actionResultChan1 := make(chan receivedAction)
+		actionResultChan2 := make(chan receivedAction)
+		resultsReceived := 0
+		if reqs[0] != nil {
+			go func() {
+				actionResultChan1 <- receiveGameAction(player1.gameActionChan, player1.Username, GameActionMarker)
+			}()
 		} else {
-			p1ActivePokemon := battleState.Player1Team[battleState.Player1ActiveIndex]
-			p1MoveInfoList := moveset1[battleState.Player1ActiveIndex]
-			p1MovesInfoForClient := make([]MoveStateInfo, 0, len(p1MoveInfoList))
-			for _, moveInfo := range p1MoveInfoList {
-				if moveInfo == nil {
-					continue
-				}
-				moveName := moveInfo.Name
-				maxPP := moveInfo.Pp
-				currentPP, ppOk := p1ActivePokemon.MovePP[moveName]
-				if !ppOk {
-					log.Printf("Warning: Move '%s' not found in MovePP map for %s (Player 1)", moveName, p1ActivePokemon.Base.Name)
-					currentPP = 0
-				}
-				p1MovesInfoForClient = append(p1MovesInfoForClient, MoveStateInfo{
-					Name:      moveName,
-					CurrentPP: currentPP,
-					MaxPP:     maxPP,
-				})
-			}
-			p1Payload["available_moves_info"] = p1MovesInfoForClient
+			resultsReceived++
 		}
-		server.SendResponse(player1.Conn, Response{Type: "turn_request", Message: p1Payload})
-
-		p2Payload := map[string]interface{}{"turn": battleState.TurnNumber, "force_switch": p2MustSwitchAtTurnStart}
-		if p2MustSwitchAtTurnStart {
-			p2Payload["available_moves_info"] = []MoveStateInfo{}
+		if reqs[1] != nil {
+			go func() {
+				actionResultChan2 <- receiveGameAction(player2.gameActionChan, player2.Username, GameActionMarker)
+			}()
 		} else {
-			p2ActivePokemon := battleState.Player2Team[battleState.Player2ActiveIndex]
-			p2MoveInfoList := moveset2[battleState.Player2ActiveIndex]
-			p2MovesInfoForClient := make([]MoveStateInfo, 0, len(p2MoveInfoList))
-			for _, moveInfo := range p2MoveInfoList {
-				if moveInfo == nil {
-					continue
-				}
-				moveName := moveInfo.Name
-				maxPP := moveInfo.Pp
-				currentPP, ppOk := p2ActivePokemon.MovePP[moveName]
-				if !ppOk {
-					log.Printf("Warning: Move '%s' not found in MovePP map for %s (Player 2)", moveName, p2ActivePokemon.Base.Name)
-					currentPP = 0
-				}
-				p2MovesInfoForClient = append(p2MovesInfoForClient, MoveStateInfo{
-					Name:      moveName,
-					CurrentPP: currentPP,
-					MaxPP:     maxPP,
-				})
-			}
-			p2Payload["available_moves_info"] = p2MovesInfoForClient
+			resultsReceived++
 		}
-		server.SendResponse(player2.Conn, Response{Type: "turn_request", Message: p2Payload})
-
-		log.Printf("Turn %d: Sent turn requests to %s and %s", battleState.TurnNumber, player1.Username, player2.Username)
-
-		actionResultChan1 := make(chan receivedAction)
-		actionResultChan2 := make(chan receivedAction)
-		go func() {
-			actionResultChan1 <- receiveGameAction(player1.gameActionChan, player1.Username, GameActionMarker)
-		}()
-		go func() {
-			actionResultChan2 <- receiveGameAction(player2.gameActionChan, player2.Username, GameActionMarker)
-		}()
 		var action1, action2 PlayerAction
 		var err1, err2 error
-		resultsReceived := 0
 		for resultsReceived < 2 {
 			select {
 			case result1 := <-actionResultChan1:
 				action1 = result1.action
 				err1 = result1.err
 				if err1 != nil {
-					log.Printf("Turn %d: Error/Timeout receiving action from %s: %v", battleState.TurnNumber, player1.Username, err1)
+					log.Printf("Turn %d: Error/Timeout receiving action from %s: %v", turn, player1.Username, err1)
 				}
 				resultsReceived++
 			case result2 := <-actionResultChan2:
 				action2 = result2.action
 				err2 = result2.err
 				if err2 != nil {
-					log.Printf("Turn %d: Error/Timeout receiving action from %s: %v", battleState.TurnNumber, player2.Username, err2)
+					log.Printf("Turn %d: Error/Timeout receiving action from %s: %v", turn, player2.Username, err2)
 				}
 				resultsReceived++
 			}
 		}
 		if err1 != nil || err2 != nil {
-			log.Printf("Turn %d: Errors/disconnects during action receive (%s:%v / %s:%v), ending game.", battleState.TurnNumber, player1.Username, err1, player2.Username, err2)
+			log.Printf("Turn %d: Errors/disconnects during action receive (%s:%v / %s:%v), ending game.", turn, player1.Username, err1, player2.Username, err2)
 			if err1 != nil && player2.Conn != nil {
 				server.SendResponse(player2.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player1.Username, "reason": "Timeout/Error"}})
 			}
@@ -244,179 +185,75 @@ func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*ba
 			}
 			return
 		}
-		log.Printf("Turn %d: Received actions: P1=%+v, P2=%+v", battleState.TurnNumber, action1, action2)
-
-		if p1MustSwitchAtTurnStart && action1.Type != "switch" {
-			log.Printf("Turn %d: Player 1 %s was forced to switch but sent action type '%s'. Invalidating action.", battleState.TurnNumber, player1.Username, action1.Type)
-			action1 = PlayerAction{Type: "switch", SwitchToIndex: -1}
-		}
-		if p2MustSwitchAtTurnStart && action2.Type != "switch" {
-			log.Printf("Turn %d: Player 2 %s was forced to switch but sent action type '%s'. Invalidating action.", battleState.TurnNumber, player2.Username, action2.Type)
-			action2 = PlayerAction{Type: "switch", SwitchToIndex: -1}
-		}
-
-		log.Printf("Turn %d: Processing actions for %s and %s", battleState.TurnNumber, player1.Username, player2.Username)
-		turnSummary := []string{}
-		var player1Move, player2Move *pokemon.MoveInfo
-		p1Switched := false
-		p2Switched := false
+		log.Printf("Turn %d: Received actions: P1=%+v, P2=%+v", turn, action1, action2)
 
-		if action1.Type == "switch" {
-			targetIdx := action1.SwitchToIndex
-			if targetIdx >= 0 && targetIdx < len(battleState.Player1Team) && !battleState.Player1Team[targetIdx].Fainted && targetIdx != battleState.Player1ActiveIndex {
-				battleState.Player1ActiveIndex = targetIdx
-				turnSummary = append(turnSummary, fmt.Sprintf("%s switched to %s!", player1.Username, battleState.Player1Team[targetIdx].Base.Name))
-				p1Switched = true
-			} else {
-				errMsg := fmt.Sprintf("%s tried to switch but failed!", player1.Username)
-				if p1MustSwitchAtTurnStart {
-					errMsg = fmt.Sprintf("%s failed to select a valid Pokemon to switch to!", player1.Username)
-				}
-				turnSummary = append(turnSummary, errMsg)
+		turnSummary := pendingEvents
+		pendingEvents = nil
+		for side, action := range [2]PlayerAction{action1, action2} {
+			if reqs[side] == nil {
+				continue
 			}
-			player1Move = nil
-		}
-		if action2.Type == "switch" {
-			targetIdx := action2.SwitchToIndex
-			if targetIdx >= 0 && targetIdx < len(battleState.Player2Team) && !battleState.Player2Team[targetIdx].Fainted && targetIdx != battleState.Player2ActiveIndex {
-				battleState.Player2ActiveIndex = targetIdx
-				turnSummary = append(turnSummary, fmt.Sprintf("%s switched to %s!", player2.Username, battleState.Player2Team[targetIdx].Base.Name))
-				p2Switched = true
-			} else {
-				errMsg := fmt.Sprintf("%s tried to switch but failed!", player2.Username)
-				if p2MustSwitchAtTurnStart {
-					errMsg = fmt.Sprintf("%s failed to select a valid Pokemon to switch to!", player2.Username)
-				}
-				turnSummary = append(turnSummary, errMsg)
+			if err := b.Submit(side, action.battleAction()); err != nil {
+				log.Printf("Turn %d: Rejected action from %s: %v", turn, players[side].Username, err)
+				turnSummary = append(turnSummary, battle.Message{Text: fmt.Sprintf("%s failed to select a valid action!", players[side].Username)})
+				b.Submit(side, reqs[side].DefaultAction())
 			}
-			player2Move = nil
 		}
 
-		if !p1Switched && !p1MustSwitchAtTurnStart && action1.Type == "move" {
-			moveInfo := getMoveFromAction(action1, moveset1[battleState.Player1ActiveIndex])
-			if moveInfo == nil {
-				turnSummary = append(turnSummary, fmt.Sprintf("%s failed to select a valid move!", player1.Username))
-			} else {
-				currentPP := battleState.Player1Team[battleState.Player1ActiveIndex].MovePP[moveInfo.Name]
-				if currentPP <= 0 {
-					log.Printf("Turn %d: Player 1 tried to use %s with 0 PP.", battleState.TurnNumber, moveInfo.Name)
-					turnSummary = append(turnSummary, fmt.Sprintf("%s tried to use %s, but it has no PP left!", player1.Username, moveInfo.Name))
-					player1Move = nil
-				} else {
-					player1Move = moveInfo
-				}
-			}
-		} else if !p1Switched && p1MustSwitchAtTurnStart {
-			player1Move = nil
-		}
-
-		if !p2Switched && !p2MustSwitchAtTurnStart && action2.Type == "move" {
-			moveInfo := getMoveFromAction(action2, moveset2[battleState.Player2ActiveIndex])
-			if moveInfo == nil {
-				turnSummary = append(turnSummary, fmt.Sprintf("%s failed to select a valid move!", player2.Username))
-			} else {
-				currentPP := battleState.Player2Team[battleState.Player2ActiveIndex].MovePP[moveInfo.Name]
-				if currentPP <= 0 {
-					log.Printf("Turn %d: Player 2 tried to use %s with 0 PP.", battleState.TurnNumber, moveInfo.Name)
-					turnSummary = append(turnSummary, fmt.Sprintf("%s tried to use %s, but it has no PP left!", player2.Username, moveInfo.Name))
-					player2Move = nil
-				} else {
-					player2Move = moveInfo
-				}
-			}
-		} else if !p2Switched && p2MustSwitchAtTurnStart {
-			player2Move = nil
-		}
-
-		p1FinalActing := battleState.Player1Team[battleState.Player1ActiveIndex]
-		p2FinalActing := battleState.Player2Team[battleState.Player2ActiveIndex]
-		if player1Move != nil || player2Move != nil {
-			battleEvents := battle.ExecuteBattleTurn(p1FinalActing, p2FinalActing, player1Move, player2Move)
-			turnSummary = append(turnSummary, battleEvents...)
-		} else {
-			if len(turnSummary) == 0 {
-				turnSummary = append(turnSummary, "Neither Pokemon could make a move!")
-			}
+		log.Printf("Turn %d: Processing actions for %s and %s", turn, player1.Username, player2.Username)
+		events, next, err := b.Step()
+		if err != nil {
+			log.Printf("Turn %d: Battle step failed: %v", turn, err)
+			return
 		}
+		turnSummary = append(turnSummary, events...)
 
-		p1FaintedThisTurn := p1FinalActing.Fainted
-		p2FaintedThisTurn := p2FinalActing.Fainted
-		log.Printf("Turn %d: Mid-turn faint check: P1 Fainted: %t, P2 Fainted: %t", battleState.TurnNumber, p1FaintedThisTurn, p2FaintedThisTurn)
-
-		if p1FaintedThisTurn && !battle.IsAllFainted(battleState.Player1Team) {
-			log.Printf("Turn %d: Player 1's %s fainted mid-turn. Requesting switch.", battleState.TurnNumber, p1FinalActing.Base.Name)
-			server.SendResponse(player1.Conn, Response{Type: "switch_request", Message: map[string]interface{}{"reason": "Pokemon fainted"}})
-			switchAction1, switchErr1 := receiveSwitchAction(player1.gameActionChan, player1.Username)
-			if switchErr1 != nil {
-				log.Printf("Turn %d: Error receiving switch action from %s: %v. Ending game.", battleState.TurnNumber, player1.Username, switchErr1)
-				if player2.Conn != nil {
-					server.SendResponse(player2.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player1.Username, "reason": "Switch Timeout/Error"}})
-				}
-				if player1.Conn != nil {
-					player1.Conn.Close()
-					player1.Conn = nil
-				}
-				return
-			}
-			targetIdx := switchAction1.SwitchToIndex
-			if targetIdx >= 0 && targetIdx < len(battleState.Player1Team) && !battleState.Player1Team[targetIdx].Fainted {
-				battleState.Player1ActiveIndex = targetIdx
-				turnSummary = append([]string{fmt.Sprintf("%s switched to %s!", player1.Username, battleState.Player1Team[targetIdx].Base.Name)}, turnSummary...)
-				log.Printf("Turn %d: Player 1 switched to %s.", battleState.TurnNumber, battleState.Player1Team[targetIdx].Base.Name)
-			} else {
-				log.Printf("Turn %d: Player 1 %s sent invalid switch index %d. Ending game.", battleState.TurnNumber, player1.Username, targetIdx)
-				turnSummary = append([]string{fmt.Sprintf("%s failed to choose a valid Pokemon!", player1.Username)}, turnSummary...)
-				if player2.Conn != nil {
-					server.SendResponse(player2.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player1.Username, "reason": "Invalid Switch Choice"}})
-				}
-				if player1.Conn != nil {
-					player1.Conn.Close()
-					player1.Conn = nil
+		for forcedSwitch(next) {
+			for side, req := range next {
+				if req == nil {
+					continue
 				}
-				return
-			}
-		}
-
-		if p2FaintedThisTurn && !battle.IsAllFainted(battleState.Player2Team) {
-			log.Printf("Turn %d: Player 2's %s fainted mid-turn. Requesting switch.", battleState.TurnNumber, p2FinalActing.Base.Name)
-			server.SendResponse(player2.Conn, Response{Type: "switch_request", Message: map[string]interface{}{"reason": "Pokemon fainted"}})
-			switchAction2, switchErr2 := receiveSwitchAction(player2.gameActionChan, player2.Username)
-			if switchErr2 != nil {
-				log.Printf("Turn %d: Error receiving switch action from %s: %v. Ending game.", battleState.TurnNumber, player2.Username, switchErr2)
-				if player1.Conn != nil {
-					server.SendResponse(player1.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player2.Username, "reason": "Switch Timeout/Error"}})
+				player, opponent := players[side], players[1-side]
+				log.Printf("Turn %d: %s's %s fainted. Requesting switch.", turn, player.Username, b.ActivePokemon(side).Base.Name)
+				server.SendResponse(player.Conn, Response{Type: "switch_request", Message: map[string]interface{}{"reason": "Pokemon fainted"}})
+				reason := "Switch Timeout/Error"
+				switchAction, switchErr := receiveSwitchAction(player.gameActionChan, player.Username)
+				if switchErr == nil {
+					reason = "Invalid Switch Choice"
+					switchErr = b.Submit(side, switchAction.battleAction())
 				}
-				if player2.Conn != nil {
-					player2.Conn.Close()
-					player2.Conn = nil
+				if switchErr != nil {
+					log.Printf("Turn %d: No valid switch from %s: %v. Ending game.", turn, player.Username, switchErr)
+					if opponent.Conn != nil {
+						server.SendResponse(opponent.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player.Username, "reason": reason}})
+					}
+					if player.Conn != nil {
+						player.Conn.Close()
+						player.Conn = nil
+					}
+					return
 				}
-				return
 			}
-			targetIdx := switchAction2.SwitchToIndex
-			if targetIdx >= 0 && targetIdx < len(battleState.Player2Team) && !battleState.Player2Team[targetIdx].Fainted {
-				battleState.Player2ActiveIndex = targetIdx
-				turnSummary = append([]string{fmt.Sprintf("%s switched to %s!", player2.Username, battleState.Player2Team[targetIdx].Base.Name)}, turnSummary...)
-				log.Printf("Turn %d: Player 2 switched to %s.", battleState.TurnNumber, battleState.Player2Team[targetIdx].Base.Name)
-			} else {
-				log.Printf("Turn %d: Player 2 %s sent invalid switch index %d. Ending game.", battleState.TurnNumber, player2.Username, targetIdx)
-				turnSummary = append([]string{fmt.Sprintf("%s failed to choose a valid Pokemon!", player2.Username)}, turnSummary...)
-				if player1.Conn != nil {
-					server.SendResponse(player1.Conn, Response{Type: "opponent_disconnected", Message: map[string]interface{}{"opponent": player2.Username, "reason": "Invalid Switch Choice"}})
-				}
-				if player2.Conn != nil {
-					player2.Conn.Close()
-					player2.Conn = nil
-				}
+			events, next, err = b.Step()
+			if err != nil {
+				log.Printf("Turn %d: Battle step failed: %v", turn, err)
 				return
 			}
+			turnSummary = append(turnSummary, events...)
 		}
 
-		log.Printf("Turn %d: Sending final results to %s and %s", battleState.TurnNumber, player1.Username, player2.Username)
+		log.Printf("Turn %d: Sending final results to %s and %s", turn, player1.Username, player2.Username)
 		battleState.LastTurnResults = turnSummary
-		p1SquadState := getSquadStateInfo(battleState.Player1Team)
-		p2SquadState := getSquadStateInfo(battleState.Player2Team)
-		resultMsgP1 := map[string]interface{}{"description": turnSummary, "your_squad_state": p1SquadState, "opponent_squad_state": p2SquadState, "your_active_index": battleState.Player1ActiveIndex, "opponent_active_index": battleState.Player2ActiveIndex}
-		resultMsgP2 := map[string]interface{}{"description": turnSummary, "your_squad_state": p2SquadState, "opponent_squad_state": p1SquadState, "your_active_index": battleState.Player2ActiveIndex, "opponent_active_index": battleState.Player1ActiveIndex}
+		field := b.Field
+		p1SquadState := getSquadStateInfo(b.Team(0))
+		p2SquadState := getSquadStateInfo(b.Team(1))
+		description, records := battle.Render(turnSummary), battle.Records(turnSummary)
+		resultMsgP1 := map[string]interface{}{"events": records, "description": description, "your_squad_state": p1SquadState, "opponent_squad_state": p2SquadState, "your_active_index": b.Active[0], "opponent_active_index": b.Active[1], "weather": field.Weather, "weather_turns": field.WeatherTurns,
+			"your_side_conditions": field.Sides[0].Conditions, "opponent_side_conditions": field.Sides[1].Conditions, "field_conditions": field.ActiveConditions(),
+			"your_hazards": field.Sides[0].Hazards, "opponent_hazards": field.Sides[1].Hazards}
+		resultMsgP2 := map[string]interface{}{"events": records, "description": description, "your_squad_state": p2SquadState, "opponent_squad_state": p1SquadState, "your_active_index": b.Active[1], "opponent_active_index": b.Active[0], "weather": field.Weather, "weather_turns": field.WeatherTurns,
+			"your_side_conditions": field.Sides[1].Conditions, "opponent_side_conditions": field.Sides[0].Conditions, "field_conditions": field.ActiveConditions(),
+			"your_hazards": field.Sides[1].Hazards, "opponent_hazards": field.Sides[0].Hazards}
 		if player1.Conn != nil {
 			server.SendResponse(player1.Conn, Response{Type: "turn_result", Message: resultMsgP1})
 		}
@@ -424,30 +261,52 @@ func (server *Server) runGameLoop(player1, player2 *Client, squad1, squad2 []*ba
 			server.SendResponse(player2.Conn, Response{Type: "turn_result", Message: resultMsgP2})
 		}
 
-		p1LostAfterTurn := battle.IsAllFainted(battleState.Player1Team)
-		p2LostAfterTurn := battle.IsAllFainted(battleState.Player2Team)
-		if p1LostAfterTurn || p2LostAfterTurn {
-			log.Printf("Game over detected *after* Turn %d results sent. P1 Lost: %v, P2 Lost: %v", battleState.TurnNumber, p1LostAfterTurn, p2LostAfterTurn)
-			resultP1, resultP2 := "lose", "win"
-			if p1LostAfterTurn && p2LostAfterTurn {
-				resultP1, resultP2 = "draw", "draw"
-			}
-			if p1LostAfterTurn {
-				resultP1, resultP2 = "lose", "win"
-			}
-			if p2LostAfterTurn {
-				resultP1, resultP2 = "win", "lose"
-			}
-			if player1.Conn != nil {
-				server.sendGameEnd(player1, player2, resultP1)
-			}
-			if player2.Conn != nil {
-				server.sendGameEnd(player2, player1, resultP2)
-			}
+		if b.Over() {
+			log.Printf("Game over detected *after* Turn %d results sent. P1 Lost: %v, P2 Lost: %v", turn, b.Lost(0), b.Lost(1))
+			server.endGame(player1, player2, b)
 			return
 		}
+	}
+}
+
+// forcedSwitch reports whether the battle is waiting on a replacement for a
+// fainted Pokémon.
+func forcedSwitch(reqs [2]*battle.Request) bool {
+	for _, req := range reqs {
+		if req != nil && req.ForceSwitch {
+			return true
+		}
+	}
+	return false
+}
 
-		battleState.TurnNumber++
+// turnRequestPayload is the turn_request message answering req, or telling
+// a player with nothing to decide on turn to wait for the other.
+func turnRequestPayload(turn int, req *battle.Request, field *battle.Field) map[string]interface{} {
+	if req == nil {
+		return map[string]interface{}{"turn": turn, "waiting": true, "weather": field.Weather, "weather_turns": field.WeatherTurns}
+	}
+	moves := make([]MoveStateInfo, len(req.Moves))
+	for i, move := range req.Moves {
+		moves[i] = MoveStateInfo{Name: move.Name, CurrentPP: move.PP, MaxPP: move.MaxPP, Disabled: move.Disabled, Forced: move.Forced}
+	}
+	return map[string]interface{}{"turn": req.Turn, "force_switch": req.ForceSwitch, "can_switch": req.CanSwitch, "weather": field.Weather, "weather_turns": field.WeatherTurns, "available_moves_info": moves}
+}
+
+// endGame tells both players how the finished battle went for them.
+func (server *Server) endGame(player1, player2 *Client, b *battle.Battle) {
+	resultP1, resultP2 := "win", "lose"
+	switch {
+	case b.Lost(0) && b.Lost(1):
+		resultP1, resultP2 = "draw", "draw"
+	case b.Lost(0):
+		resultP1, resultP2 = "lose", "win"
+	}
+	if player1.Conn != nil {
+		server.sendGameEnd(player1, player2, resultP1)
+	}
+	if player2.Conn != nil {
+		server.sendGameEnd(player2, player1, resultP2)
 	}
 }
 
@@ -573,22 +432,3 @@ func receiveGameAction(actionChan <-chan []byte, username string, expectedMarker
 		return receivedAction{err: fmt.Errorf("timeout waiting for action (%s) from %s", expectedMarker, username)}
 	}
 }
-
-func getMoveFromAction(action PlayerAction, moves []*pokemon.MoveInfo) *pokemon.MoveInfo {
-	if action.Type != "move" {
-		return nil
-	}
-	if moves == nil {
-		log.Printf("Error: Attempted to get move from nil moveset (Action: %+v)", action)
-		return nil
-	}
-	if action.ActionIndex < 1 || action.ActionIndex > len(moves) {
-		log.Printf("Invalid move index received in getMoveFromAction: %d (available: %d)", action.ActionIndex, len(moves))
-		return nil
-	}
-	move := moves[action.ActionIndex-1]
-	if move == nil {
-		log.Printf("Warning: Move at index %d is nil. Action: %+v", action.ActionIndex-1, action)
-	}
-	return move
-}
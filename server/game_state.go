@@ -8,30 +8,21 @@ type BattleState struct {
 	Player1Username string
 	Player2Username string
 
-	Player1Team []*battle.BattlePokemon
-	Player2Team []*battle.BattlePokemon
+	// Battle runs the turns; player 1 is side 0 and player 2 side 1.
+	Battle *battle.Battle
 
-	Player1ActiveIndex int
-	Player2ActiveIndex int
-
-	TurnNumber   int
-	Weather      string
-	FieldEffects map[string]int
-
-	LastTurnResults []string
+	LastTurnResults []battle.Event
 }
 
-func NewBattleState(p1Username, p2Username string, p1Team, p2Team []*battle.BattlePokemon) *BattleState {
+// NewBattleState seats both teams on field, which the squads were drawn
+// with, each led by the given squad member.
+func NewBattleState(p1Username, p2Username string, p1Team, p2Team []*battle.BattlePokemon, p1Lead, p2Lead int, field *battle.Field) *BattleState {
+	names := [2]string{p1Username + "'s team", p2Username + "'s team"}
 	return &BattleState{
-		Player1Username:    p1Username,
-		Player2Username:    p2Username,
-		Player1Team:        p1Team,
-		Player2Team:        p2Team,
-		Player1ActiveIndex: 0,
-		Player2ActiveIndex: 0,
-		TurnNumber:         1,
-		FieldEffects:       make(map[string]int),
-		LastTurnResults:    []string{},
+		Player1Username: p1Username,
+		Player2Username: p2Username,
+		Battle:          battle.NewBattle(field, names, [2][]*battle.BattlePokemon{p1Team, p2Team}, [2]int{p1Lead, p2Lead}),
+		LastTurnResults: []battle.Event{},
 	}
 }
 
@@ -41,6 +32,15 @@ type PlayerAction struct {
 	SwitchToIndex int
 }
 
+// battleAction turns a player's wire action into the engine's. Move indexes
+// arrive counted from 1.
+func (a PlayerAction) battleAction() battle.Action {
+	if a.Type == "switch" {
+		return battle.SwitchAction(a.SwitchToIndex)
+	}
+	return battle.MoveAction(a.ActionIndex - 1)
+}
+
 type TurnResult struct {
 	Description   []string
 	DamageDealt   map[string]float64
@@ -49,13 +49,9 @@ type TurnResult struct {
 }
 
 func (b *BattleState) GetActivePokemons() (*battle.BattlePokemon, *battle.BattlePokemon) {
-	if b.Player1ActiveIndex < 0 || b.Player1ActiveIndex >= len(b.Player1Team) ||
-		b.Player2ActiveIndex < 0 || b.Player2ActiveIndex >= len(b.Player2Team) {
-		return nil, nil
-	}
-	return b.Player1Team[b.Player1ActiveIndex], b.Player2Team[b.Player2ActiveIndex]
+	return b.Battle.ActivePokemon(0), b.Battle.ActivePokemon(1)
 }
 
 func (b *BattleState) IsGameOver() bool {
-	return battle.IsAllFainted(b.Player1Team) || battle.IsAllFainted(b.Player2Team)
+	return b.Battle.Over()
 }
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -197,6 +199,22 @@ func (server *Server) HandleDisconnection(conn net.Conn, username string) {
 	}
 }
 
+// setupContext is cancelled as soon as either player's game is torn down, so
+// a disconnect during squad setup stops any outstanding data fetches.
+func setupContext(player1, player2 *Client) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	end1, end2 := player1.endGameSignal, player2.endGameSignal
+	go func() {
+		select {
+		case <-end1:
+		case <-end2:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
 func (server *Server) startGame(player1, player2 *Client) {
 	if player1 == nil || player2 == nil {
 		log.Println("startGame Error: Invalid client(s) provided.")
@@ -205,16 +223,42 @@ func (server *Server) startGame(player1, player2 *Client) {
 
 	log.Printf("startGame invoked for %s and %s", player1.Username, player2.Username)
 
-	squad1, squad2, moveset1, moveset2, idx1, idx2 := battle.SetupMPSquad()
+	field := battle.NewField(server.format, battle.NewSeed())
+	log.Printf("Battle between %s and %s uses seed %d", player1.Username, player2.Username, field.Seed)
+
+	ctx, cancel := setupContext(player1, player2)
+	squad1, squad2, moveset1, moveset2, idx1, idx2, err := battle.SetupMPSquad(ctx, server.data, server.format, field.Rand())
+	cancel()
+	if errors.Is(err, context.Canceled) {
+		log.Printf("startGame: Setup for %s and %s cancelled after a disconnect.", player1.Username, player2.Username)
+		return
+	}
+	if err != nil || len(squad1) == 0 || len(squad2) == 0 {
+		log.Printf("startGame Error: Failed to generate squads for %s and %s: %v", player1.Username, player2.Username, err)
+		server.mu.Lock()
+		delete(server.Lobbies, player1.Username)
+		delete(server.Lobbies, player2.Username)
+		server.mu.Unlock()
+		for _, p := range []*Client{player1, player2} {
+			if p.Conn != nil {
+				server.SendResponse(p.Conn, Response{Type: "match_error", Message: map[string]interface{}{"error": "Failed to load battle data"}})
+			}
+		}
+		return
+	}
 	log.Printf("Squads generated for %s and %s", player1.Username, player2.Username)
 
 	squad1Names := make([]string, len(squad1))
+	squad1Levels := make([]int, len(squad1))
 	for i, p := range squad1 {
 		squad1Names[i] = p.Base.Name
+		squad1Levels[i] = p.Spread.Level
 	}
 	squad2Names := make([]string, len(squad2))
+	squad2Levels := make([]int, len(squad2))
 	for i, p := range squad2 {
 		squad2Names[i] = p.Base.Name
+		squad2Levels[i] = p.Spread.Level
 	}
 	moveNames1 := make([]string, len(moveset1[idx1]))
 	for i, m := range moveset1[idx1] {
@@ -226,10 +270,10 @@ func (server *Server) startGame(player1, player2 *Client) {
 	}
 
 	if player1.Conn != nil {
-		server.SendResponse(player1.Conn, Response{Type: "game_start", Message: map[string]interface{}{"your_squad": squad1Names, "opponent_squad": squad2Names, "your_pokemon": squad1Names[idx1], "opponent_pokemon": squad2Names[idx2], "your_moves": moveNames1}})
+		server.SendResponse(player1.Conn, Response{Type: "game_start", Message: map[string]interface{}{"your_squad": squad1Names, "opponent_squad": squad2Names, "your_pokemon": squad1Names[idx1], "opponent_pokemon": squad2Names[idx2], "your_moves": moveNames1, "format": server.format.Name, "your_levels": squad1Levels, "opponent_levels": squad2Levels, "your_squad_state": getSquadStateInfo(squad1), "opponent_squad_state": getSquadStateInfo(squad2)}})
 	}
 	if player2.Conn != nil {
-		server.SendResponse(player2.Conn, Response{Type: "game_start", Message: map[string]interface{}{"your_squad": squad2Names, "opponent_squad": squad1Names, "your_pokemon": squad2Names[idx2], "opponent_pokemon": squad1Names[idx1], "your_moves": moveNames2}})
+		server.SendResponse(player2.Conn, Response{Type: "game_start", Message: map[string]interface{}{"your_squad": squad2Names, "opponent_squad": squad1Names, "your_pokemon": squad2Names[idx2], "opponent_pokemon": squad1Names[idx1], "your_moves": moveNames2, "format": server.format.Name, "your_levels": squad2Levels, "opponent_levels": squad1Levels, "your_squad_state": getSquadStateInfo(squad2), "opponent_squad_state": getSquadStateInfo(squad1)}})
 	}
 
 	if player1.startGameSignal != nil {
@@ -249,8 +293,7 @@ func (server *Server) startGame(player1, player2 *Client) {
 
 	log.Printf("game_start messages sent and signals sent to %s and %s. Starting runGameLoop.", player1.Username, player2.Username)
 
-	server.runGameLoop(player1, player2, squad1, squad2, moveset1, moveset2)
+	server.runGameLoop(player1, player2, squad1, squad2, idx1, idx2, field)
 
 	log.Printf("startGame finished for lobby between %s and %s", player1.Username, player2.Username)
 }
-
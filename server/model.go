@@ -3,6 +3,9 @@ package server
 import (
 	"net"
 	"sync"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
 type Server struct {
@@ -10,6 +13,8 @@ type Server struct {
 	port    string
 	clients map[string]*Client
 	Lobbies map[string]*Lobby
+	data    pokemon.DataSource
+	format  battle.Format
 	mu      sync.RWMutex
 }
 
@@ -29,8 +34,11 @@ type Lobby struct {
 }
 
 type Config struct {
-	Host string
-	Port string
+	Host       string
+	Port       string
+	DataSource pokemon.DataSource
+	// Format defaults to random battles when left empty.
+	Format battle.Format
 }
 
 type Response struct {
@@ -6,14 +6,27 @@ import (
 	"log"
 	"net"
 	"time"
+
+	"github.com/ross1116/pokebattlecli/internal/battle"
+	"github.com/ross1116/pokebattlecli/internal/pokemon"
 )
 
 func New(config *Config) *Server {
+	data := config.DataSource
+	if data == nil {
+		data = pokemon.NewHTTPSource()
+	}
+	format := config.Format
+	if format.Name == "" {
+		format, _ = battle.LookupFormat(battle.DefaultFormat)
+	}
 	return &Server{
 		host:    config.Host,
 		port:    config.Port,
 		clients: make(map[string]*Client),
 		Lobbies: make(map[string]*Lobby),
+		data:    data,
+		format:  format,
 	}
 }
 